@@ -0,0 +1,176 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"fmt"
+	"sort"
+)
+
+// keyStore is a map with keys of any type (e.g. map[any]any or sync.Map), adapted to the Map interface by anyKeyMap
+type keyStore interface {
+	// rangeKeys calls fn for each key until it returns false
+	rangeKeys(fn func(key any) bool)
+	// load returns the value stored for the key
+	load(key any) (any, bool)
+	// store sets the value stored for the key
+	store(key, value any)
+	// delete removes the key
+	delete(key any)
+}
+
+// anyKeyMap adapts a keyStore to the Map interface, member names are the keys converted to strings using fmt.Sprint.
+// When several keys have the same name (e.g. 1 and "1"), the string key is used, otherwise the key with the first Go
+// type name in sorted order, the other keys are not reachable.
+type anyKeyMap struct {
+	keyStore
+}
+
+// keyIndex maps member names to the original keys, it is built once per enumeration or lookup
+type keyIndex map[string]any
+
+// index returns the original keys of the members by name
+func (m anyKeyMap) index() keyIndex {
+	// index
+	index := keyIndex{}
+	// loop keys
+	m.rangeKeys(func(k any) bool {
+		// member name
+		name := fmt.Sprint(k)
+		// keep the preferred key of colliding names
+		if existing, ok := index[name]; !ok || preferredKey(k, existing) {
+			index[name] = k
+		}
+		return true
+	})
+	return index
+}
+
+// preferredKey checks whether key is used instead of other when both keys have the same member name
+func preferredKey(key, other any) bool {
+	// string keys first
+	if _, ok := other.(string); ok {
+		return false
+	}
+	if _, ok := key.(string); ok {
+		return true
+	}
+	// Go type name order
+	return fmt.Sprintf("%T", key) < fmt.Sprintf("%T", other)
+}
+
+// sortedNames returns the member names in sorted order
+func (index keyIndex) sortedNames() []string {
+	// names
+	names := make([]string, 0, len(index))
+	// loop index
+	for name := range index {
+		names = append(names, name)
+	}
+	// sort names
+	sort.Strings(names)
+	return names
+}
+
+// lookup returns a function returning the original key of a member name, string keys are loaded directly and the other
+// keys are indexed on first use
+func (m anyKeyMap) lookup() func(name string) (any, bool) {
+	// index (built on first use)
+	var index keyIndex
+	return func(name string) (any, bool) {
+		// check string key
+		if _, ok := m.load(name); ok {
+			return name, true
+		}
+		// index keys
+		if index == nil {
+			index = m.index()
+		}
+		// find non string key
+		key, ok := index[name]
+		return key, ok
+	}
+}
+
+func (m anyKeyMap) Keys(keys ...string) Iterator {
+	// check we need specific keys
+	if len(keys) > 0 {
+		// key lookup
+		lookup := m.lookup()
+		// keys in map
+		values := make([]any, 0, len(keys))
+		// loop keys
+		for _, k := range keys {
+			// find key in map
+			if _, ok := lookup(k); ok {
+				// append key
+				values = append(values, k)
+			}
+		}
+		return FromValues(false, values...)
+	}
+	// all keys in map
+	names := m.index().sortedNames()
+	values := make([]any, 0, len(names))
+	// loop names
+	for _, name := range names {
+		// append key
+		values = append(values, name)
+	}
+	return FromValues(false, values...)
+}
+
+func (m anyKeyMap) Values(keys ...string) Iterator {
+	// original keys
+	originals := make([]any, 0, len(keys))
+	// check we need all values
+	if len(keys) == 0 {
+		// index keys once
+		index := m.index()
+		// loop names
+		for _, name := range index.sortedNames() {
+			originals = append(originals, index[name])
+		}
+	} else {
+		// key lookup
+		lookup := m.lookup()
+		// loop keys
+		for _, k := range keys {
+			// find key in map
+			if key, ok := lookup(k); ok {
+				originals = append(originals, key)
+			}
+		}
+	}
+	// values in map
+	values := make([]any, 0, len(originals))
+	// loop keys
+	for _, key := range originals {
+		// load value (the member may have been deleted concurrently)
+		if v, ok := m.load(key); ok {
+			// append value
+			values = append(values, v)
+		}
+	}
+	return FromValues(false, values...)
+}
+
+func (m anyKeyMap) Set(key string, value any) {
+	// find original key
+	if k, ok := m.lookup()(key); ok {
+		m.store(k, value)
+		return
+	}
+	m.store(key, value)
+}
+
+func (m anyKeyMap) Delete(key string) {
+	// find original key
+	if k, ok := m.lookup()(key); ok {
+		m.delete(k)
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrBudgetExceeded is returned when the evaluation of a JsonPath expression visits more nodes than allowed by the
+// MaxNodeVisits option.
+var ErrBudgetExceeded = errors.New("node visit budget exceeded")
+
+// budget limits the number of nodes visited while evaluating a path
+type budget struct {
+	max    int64
+	visits atomic.Int64
+}
+
+// exceeded checks whether more nodes than allowed have been visited
+func (b *budget) exceeded() bool {
+	return b.visits.Load() > b.max
+}
+
+// limit counts every node the path is evaluated on, the path matches nothing once the budget is exceeded
+func (b *budget) limit(path *Path) *Path {
+	return &Path{
+		expression: func(operation operation, value, root any) Iterator {
+			// count visit (visits are not counted once the budget is exceeded)
+			if b.exceeded() || b.visits.Add(1) > b.max {
+				return empty(operation, value, root)
+			}
+			return path.expression(operation, value, root)
+		},
+		terminal: path.terminal,
+	}
+}
+
+// stop returns an iterator over the values in it that ends once the budget is exceeded
+func (b *budget) stop(it Iterator) Iterator {
+	return func() (any, bool) {
+		// check budget
+		if b.exceeded() {
+			return nil, false
+		}
+		return it()
+	}
+}
+
+// checkBudget returns ErrBudgetExceeded if the evaluation visited more nodes than allowed
+func (ctx *pathContext) checkBudget() error {
+	// check budget
+	if ctx.budget != nil && ctx.budget.exceeded() {
+		return ErrBudgetExceeded
+	}
+	return nil
+}
@@ -12,6 +12,32 @@ package jsonpath
 
 import "strconv"
 
+// ValueType is the type of a value taking part in a filter comparison.
+type ValueType int
+
+const (
+	UnknownValue ValueType = iota
+	StringValue
+	IntValue
+	FloatValue
+	BooleanValue
+	NullValue
+)
+
+// TypedValue is a value taking part in a filter comparison, Value holds its textual representation.
+type TypedValue struct {
+	Type  ValueType
+	Value string
+}
+
+// CompareFunc compares two filter values and returns a negative number if a < b, zero if a == b or a positive
+// number if a > b. It returns false if it cannot compare the values, in which case the default comparison is used.
+type CompareFunc func(a, b TypedValue) (int, bool)
+
+// EqualityFunc reports whether two filter values are equal, it receives the values selected from the document (or the
+// Go value of a literal: int, float64, string, bool or nil).
+type EqualityFunc func(a, b any) bool
+
 type comparison int
 
 const (
@@ -82,6 +108,9 @@ func compareNodeValues(lhs, rhs typedValue) comparison {
 	if lhs.typ.isNumeric() && rhs.typ.isNumeric() {
 		return compareFloat64(mustParseFloat64(lhs.val), mustParseFloat64(rhs.val))
 	}
+	if lhs.typ == versionValueType && rhs.typ == versionValueType {
+		return compareSemanticVersions(lhs.val, rhs.val)
+	}
 	if (lhs.typ != stringValueType && !lhs.typ.isNumeric()) || (rhs.typ != stringValueType && !rhs.typ.isNumeric()) {
 		// we cannot compare values, @f1==@f2 and either value is a map or array
 		return compareIncomparable
@@ -89,6 +118,25 @@ func compareNodeValues(lhs, rhs typedValue) comparison {
 	return compareStrings(lhs.val, rhs.val)
 }
 
+// compareValues compares two values using the custom comparison function (if any) or compareNodeValues
+func (ctx *pathContext) compareValues(lhs, rhs typedValue) comparison {
+	// check custom comparison (versions are always compared by precedence)
+	if ctx.compare != nil && lhs.typ != versionValueType {
+		// compare values
+		if c, ok := ctx.compare(lhs.public(), rhs.public()); ok {
+			switch {
+			case c < 0:
+				return compareLessThan
+			case c > 0:
+				return compareGreaterThan
+			default:
+				return compareEqual
+			}
+		}
+	}
+	return compareNodeValues(lhs, rhs)
+}
+
 func mustParseFloat64(s string) float64 {
 	f, err := strconv.ParseFloat(s, 64)
 	if err != nil {
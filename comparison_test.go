@@ -248,3 +248,87 @@ func TestComparators(t *testing.T) {
 		t.Fatalf("testcase(s) still focussed")
 	}
 }
+
+func TestCompareNodeValuesNumbers(t *testing.T) {
+	cases := []struct {
+		name     string
+		lhs      any
+		rhs      any
+		expected comparison
+	}{
+		{name: "float with integer value equals int", lhs: 2.0, rhs: 2, expected: compareEqual},
+		{name: "int equals float with integer value", lhs: 2, rhs: 2.0, expected: compareEqual},
+		{name: "float greater than int", lhs: 2.5, rhs: 2, expected: compareGreaterThan},
+		{name: "int less than float", lhs: 2, rhs: 2.5, expected: compareLessThan},
+		{name: "negative int8 equals negative float", lhs: int8(-1), rhs: -1.0, expected: compareEqual},
+		{name: "int64 greater than float32", lhs: int64(3), rhs: float32(2.5), expected: compareGreaterThan},
+		{name: "float32 equals float64 with the same decimal form", lhs: float32(0.1), rhs: 0.1, expected: compareEqual},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			lhs, rhs := typedValueOfNode(tc.lhs), typedValueOfNode(tc.rhs)
+			require.True(t, lhs.typ.compatibleWith(rhs.typ))
+			require.Equal(t, tc.expected, compareNodeValues(lhs, rhs))
+		})
+	}
+}
+
+func TestCompareSemanticVersions(t *testing.T) {
+	// versions in increasing precedence (see https://semver.org/#spec-item-11)
+	versions := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.9.0",
+		"1.10.0",
+		"2.0.0",
+		"10.0.0",
+		"18446744073709551616.0.0",
+	}
+
+	for i := 1; i < len(versions); i++ {
+		lower, higher := versions[i-1], versions[i]
+		t.Run(lower+" < "+higher, func(t *testing.T) {
+			require.Equal(t, compareLessThan, compareSemanticVersions(lower, higher))
+			require.Equal(t, compareGreaterThan, compareSemanticVersions(higher, lower))
+			require.Equal(t, compareEqual, compareSemanticVersions(higher, higher))
+		})
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	cases := []struct {
+		version string
+		valid   bool
+	}{
+		{version: "1.2.3", valid: true},
+		{version: "v1.2.3", valid: true},
+		{version: "0.0.0", valid: true},
+		{version: "1.2.3-rc.1", valid: true},
+		{version: "1.2.3-0a.x-y", valid: true},
+		{version: "1.2.3+build.5", valid: true},
+		{version: "1.2.3-rc.1+build.5", valid: true},
+		{version: "1.2", valid: false},
+		{version: "1.2.3.4", valid: false},
+		{version: "01.2.3", valid: false},
+		{version: "1.2.3-01", valid: false},
+		{version: "1.2.3-", valid: false},
+		{version: "1.2.3-rc..1", valid: false},
+		{version: "1.2.3+", valid: false},
+		{version: "1.2.x", valid: false},
+		{version: "", valid: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.version, func(t *testing.T) {
+			_, valid := parseVersion(tc.version)
+			require.Equal(t, tc.valid, valid)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// Decoder converts a value selected by a path into a new value, e.g. the string contents of a base64 encoded value.
+// Decoders are applied in paths using the pipe operator, e.g. $.payload|base64|json.items[*]
+type Decoder func(value any) (any, error)
+
+var (
+	// decoders lock
+	decodersLock sync.RWMutex
+	// registered decoders by name
+	decoders = map[string]Decoder{
+		"base64": decodeBase64,
+		"json":   decodeJSON,
+	}
+)
+
+// RegisterDecoder registers a decoder with the given name, replacing any decoder registered with the same name.
+// Paths using the decoder must be created after it is registered.
+func RegisterDecoder(name string, decoder Decoder) {
+	// lock decoders
+	decodersLock.Lock()
+	defer decodersLock.Unlock()
+	// register decoder
+	decoders[name] = decoder
+}
+
+// lookupDecoder returns the decoder registered with the given name
+func lookupDecoder(name string) (Decoder, bool) {
+	// lock decoders
+	decodersLock.RLock()
+	defer decodersLock.RUnlock()
+	// find decoder
+	decoder, ok := decoders[name]
+	return decoder, ok
+}
+
+// decodeBase64 decodes a standard base64 encoded string into a string
+func decodeBase64(value any) (any, error) {
+	// value must be a string
+	s, ok := value.(string)
+	if !ok {
+		return nil, errors.New("base64 decoder requires a string value")
+	}
+	// decode string
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// decodeJSON decodes a JSON document stored in a string
+func decodeJSON(value any) (any, error) {
+	// value must be a string
+	s, ok := value.(string)
+	if !ok {
+		return nil, errors.New("json decoder requires a string value")
+	}
+	// decode document
+	var result any
+	if err := json.Unmarshal([]byte(s), &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
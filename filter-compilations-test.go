@@ -0,0 +1,22 @@
+//go:build test
+// +build test
+
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"sync/atomic"
+)
+
+// filterCompilations counts the filter subpaths and regular expressions compiled, tests use it to check filters are
+// compiled once per path instead of once per evaluation
+var filterCompilations atomic.Int64
+
+func countFilterCompilation() {
+	filterCompilations.Add(1)
+}
@@ -0,0 +1,69 @@
+//go:build test
+// +build test
+
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFilterCompilations(t *testing.T) {
+	// arrange
+	data := filterDocument(100)
+	before := filterCompilations.Load()
+	path, err := NewPath("$..[?(@.name =~ /^item1/ && @.price > 10)].name")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// two subpaths and a regular expression
+	compiled := filterCompilations.Load() - before
+	// act
+	var result []any
+	for i := 0; i < 10; i++ {
+		result = path.Evaluate(data)
+	}
+	// assert
+	if compiled != 3 {
+		t.Errorf("invalid compilations at creation: %d", compiled)
+	}
+	if evaluated := filterCompilations.Load() - before - compiled; evaluated != 0 {
+		t.Errorf("invalid compilations during evaluation: %d", evaluated)
+	}
+	if len(result) != 9 {
+		t.Errorf("invalid result: %d values", len(result))
+	}
+}
+
+func TestFilterCompilationsDynamicPattern(t *testing.T) {
+	// arrange
+	data := []any{
+		map[string]any{"name": "a1", "pattern": "^a"},
+		map[string]any{"name": "b1", "pattern": "^a"},
+		map[string]any{"name": "b2", "pattern": "^b"},
+	}
+	path, err := NewPath("$[?(@.name =~ @.pattern)].name")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	before := filterCompilations.Load()
+	// act
+	var result []any
+	for i := 0; i < 10; i++ {
+		result = path.Evaluate(data)
+	}
+	// assert (each distinct pattern is compiled once)
+	if evaluated := filterCompilations.Load() - before; evaluated != 2 {
+		t.Errorf("invalid compilations during evaluation: %d", evaluated)
+	}
+	if diff := cmp.Diff([]any{"a1", "b2"}, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
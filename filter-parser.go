@@ -11,6 +11,8 @@
 
 package jsonpath
 
+import "fmt"
+
 /*
 filterNode represents a node of a filter expression parse tree. Each node is labelled with a lexeme.
 
@@ -70,16 +72,74 @@ Note that brackets do not appear in the parse tree.
 */
 type filterNode struct {
 	lexeme   lexeme
-	subpath  []lexeme // empty unless lexeme is root or lexemeFilterAt
+	subpath  []lexeme // empty unless lexeme is root, lexemeFilterAt, lexemeFilterParent or lexemeFilterGrandparent
 	children []*filterNode
 }
 
+// child returns the child node at index i, nil if the node has no such child (malformed parse tree)
+func (n *filterNode) child(i int) *filterNode {
+	// check index
+	if i < len(n.children) {
+		return n.children[i]
+	}
+	return nil
+}
+
 func newFilterNode(lexemes []lexeme) *filterNode {
 	return newParser(lexemes).parse()
 }
 
+// validate checks the filter expression can be evaluated, only boolean literals can be used as basic filters
+// (other literals and functions must be compared).
+func (n *filterNode) validate() error {
+	// check node
+	if n == nil {
+		return nil
+	}
+	// process lexer token type
+	switch n.lexeme.typ {
+
+	case lexemeFilterAnd, lexemeFilterOr, lexemeFilterNot, lexemeFilterAll, lexemeFilterAny:
+		// validate children
+		for _, child := range n.children {
+			if err := child.validate(); err != nil {
+				return err
+			}
+		}
+
+	case lexemeFilterTime, lexemeFilterMin, lexemeFilterMax, lexemeFilterAvg, lexemeFilterNormalize, lexemeFilterVersion,
+		lexemeFilterLength, lexemeFilterByteLength, lexemeFilterText:
+		// functions produce values
+		return fmt.Errorf("filter function %s) must be compared", n.lexeme.val)
+
+	case lexemeFilterIndex:
+		// the index is a value
+		return fmt.Errorf("filter term %s must be compared", n.lexeme.val)
+
+	default:
+		// check literal
+		if n.isLiteral() && !n.isBooleanLiteral() {
+			return fmt.Errorf("filter literal %s must be compared", n.lexeme.val)
+		}
+	}
+	return nil
+}
+
 func (n *filterNode) isItemFilter() bool {
-	return n.lexeme.typ == lexemeFilterAt || n.lexeme.typ == lexemeRoot
+	return n.lexeme.typ == lexemeFilterAt || n.lexeme.typ == lexemeFilterParent || n.lexeme.typ == lexemeFilterGrandparent ||
+		n.lexeme.typ == lexemeRoot
+}
+
+// hasKeysOperand checks whether a comparison or regular expression match has a member names (@~) operand
+func (n *filterNode) hasKeysOperand() bool {
+	// loop operands
+	for _, child := range n.children {
+		// check member names
+		if child != nil && child.lexeme.typ == lexemeFilterKeys {
+			return true
+		}
+	}
+	return false
 }
 
 func (n *filterNode) isLiteral() bool {
@@ -225,6 +285,21 @@ func (p *parser) basicFilter() {
 			p.nextLexeme()
 		}
 		return
+
+	case lexemeFilterAll, lexemeFilterAny:
+		p.nextLexeme()
+		p.expression()
+		if p.peek().typ == lexemeFilterCloseBracket {
+			p.nextLexeme()
+		}
+		p.tree = &filterNode{
+			lexeme:  n,
+			subpath: []lexeme{},
+			children: []*filterNode{
+				p.tree,
+			},
+		}
+		return
 	}
 
 	p.filterTerm()
@@ -251,7 +326,7 @@ func (p *parser) filterTerm() {
 	case lexemeEOF, lexemeError:
 		p.tree = nil
 
-	case lexemeFilterAt, lexemeRoot:
+	case lexemeFilterAt, lexemeFilterParent, lexemeFilterGrandparent, lexemeRoot:
 		p.nextLexeme()
 		subpath := []lexeme{}
 		filterNestingLevel := 1
@@ -259,7 +334,7 @@ func (p *parser) filterTerm() {
 		for {
 			s := p.peek()
 			switch s.typ {
-			case lexemeIdentity, lexemeDotChild, lexemeBracketChild, lexemeRecursiveDescent, lexemeArraySubscript:
+			case lexemeIdentity, lexemeDotChild, lexemeBracketChild, lexemeRecursiveDescent, lexemeArraySubscript, lexemePipeDecoder:
 
 			case lexemeFilterBegin:
 				filterNestingLevel++
@@ -288,8 +363,64 @@ func (p *parser) filterTerm() {
 			children: []*filterNode{},
 		}
 
+	case lexemeFilterTime, lexemeFilterMin, lexemeFilterMax, lexemeFilterAvg, lexemeFilterNormalize, lexemeFilterVersion,
+		lexemeFilterLength, lexemeFilterByteLength, lexemeFilterText:
+		p.nextLexeme()
+		// function argument
+		p.filterTerm()
+		if p.peek().typ == lexemeFilterCloseBracket {
+			p.nextLexeme()
+		}
+		p.tree = &filterNode{
+			lexeme:  n,
+			subpath: []lexeme{},
+			children: []*filterNode{
+				p.tree,
+			},
+		}
+
+	case lexemeFilterSetBegin:
+		p.nextLexeme()
+		// set members
+		members := []*filterNode{}
+		for p.peek().typ != lexemeFilterSetEnd && p.peek().typ != lexemeEOF {
+			members = append(members, &filterNode{
+				lexeme:   p.nextLexeme(),
+				subpath:  []lexeme{},
+				children: []*filterNode{},
+			})
+		}
+		if p.peek().typ == lexemeFilterSetEnd {
+			p.nextLexeme()
+		}
+		p.tree = &filterNode{
+			lexeme:   n,
+			subpath:  []lexeme{},
+			children: members,
+		}
+
+	case lexemeFilterIndex:
+		p.nextLexeme()
+		// modulo divisor
+		children := []*filterNode{}
+		if p.peek().typ == lexemeFilterModulo {
+			p.nextLexeme()
+			if p.peek().typ == lexemeFilterIntegerLiteral {
+				children = append(children, &filterNode{
+					lexeme:   p.nextLexeme(),
+					subpath:  []lexeme{},
+					children: []*filterNode{},
+				})
+			}
+		}
+		p.tree = &filterNode{
+			lexeme:   n,
+			subpath:  []lexeme{},
+			children: children,
+		}
+
 	case lexemeFilterIntegerLiteral, lexemeFilterFloatLiteral, lexemeFilterStringLiteral, lexemeFilterBooleanLiteral,
-		lexemeFilterNullLiteral, lexemeFilterRegularExpressionLiteral:
+		lexemeFilterNullLiteral, lexemeFilterRegularExpressionLiteral, lexemeFilterKeys:
 		p.nextLexeme()
 		p.tree = &filterNode{
 			lexeme:   n,
@@ -12,15 +12,21 @@
 package jsonpath
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 )
 
-type filter func(value, root any) bool
+// filter checks a value, parent is the container of the value (a located value when its location is known)
+type filter func(value, parent, root any) bool
 
-func newFilter(node *filterNode) filter {
+func newFilter(ctx *pathContext, node *filterNode) filter {
 	// check node
 	if node == nil {
 		return never
@@ -28,61 +34,98 @@ func newFilter(node *filterNode) filter {
 	// process lexer token type
 	switch node.lexeme.typ {
 
-	case lexemeFilterAt, lexemeRoot:
+	case lexemeFilterAt, lexemeFilterParent, lexemeFilterGrandparent, lexemeRoot:
 		// create filter scanner
-		path := pathFilterScanner(node)
+		path := pathFilterScanner(ctx, node)
 		// return filter
-		return func(value, root any) bool {
+		return func(value, parent, root any) bool {
 			// check path
-			return len(path(value, root)) > 0
+			return len(path(value, parent, root)) > 0
+		}
+
+	case lexemeFilterKeys:
+		// create filter scanner
+		keys := keysFilterScanner()
+		// return filter
+		return func(value, parent, root any) bool {
+			// check member names
+			return len(keys(value, parent, root)) > 0
 		}
 
 	case lexemeFilterEquality, lexemeFilterInequality, lexemeFilterGreaterThan, lexemeFilterGreaterThanOrEqual, lexemeFilterLessThan, lexemeFilterLessThanOrEqual:
+		// check member names (any member name may match)
+		if node.hasKeysOperand() {
+			return anyFilter(ctx, node)
+		}
 		// comparison filter
-		return comparisonFilter(node)
+		return comparisonFilter(ctx, node)
 
 	case lexemeFilterMatchesRegularExpression:
-		return matchRegularExpression(node)
+		// check member names (any member name may match)
+		if node.hasKeysOperand() {
+			return anyFilter(ctx, node)
+		}
+		return matchRegularExpression(ctx, node)
+
+	case lexemeFilterIn:
+		// set membership filter
+		return inFilter(ctx, node)
+
+	case lexemeFilterIs:
+		// type predicate filter
+		return typeFilter(ctx, node)
+
+	case lexemeFilterAll:
+		// all pairs of values must match, this is the default comparison semantics
+		return newFilter(ctx, node.child(0))
+
+	case lexemeFilterAny:
+		// quantified filter
+		return anyFilter(ctx, node.child(0))
 
 	case lexemeFilterNot:
+		// check operand (a missing operand never matches)
+		if node.child(0) == nil {
+			return never
+		}
 		// create filter
-		f := newFilter(node.children[0])
+		f := newFilter(ctx, node.child(0))
 		// return filter
-		return func(value, root any) bool {
+		return func(value, parent, root any) bool {
 			// evaluate not filter
-			return !f(value, root)
+			return !f(value, parent, root)
 		}
 
 	case lexemeFilterOr:
 		// left filter
-		f1 := newFilter(node.children[0])
+		f1 := newFilter(ctx, node.child(0))
 		// right filter
-		f2 := newFilter(node.children[1])
+		f2 := newFilter(ctx, node.child(1))
 		// return filter
-		return func(value, root any) bool {
+		return func(value, parent, root any) bool {
 			// evaluate or filter
-			return f1(value, root) || f2(value, root)
+			return f1(value, parent, root) || f2(value, parent, root)
 		}
 
 	case lexemeFilterAnd:
 		// left filter
-		f1 := newFilter(node.children[0])
+		f1 := newFilter(ctx, node.child(0))
 		// right filter
-		f2 := newFilter(node.children[1])
+		f2 := newFilter(ctx, node.child(1))
 		// return filter
-		return func(value, root any) bool {
+		return func(value, parent, root any) bool {
 			// evaluate and filter
-			return f1(value, root) && f2(value, root)
+			return f1(value, parent, root) && f2(value, parent, root)
 		}
 
 	case lexemeFilterBooleanLiteral:
 		// parse boolean literal
 		b, err := strconv.ParseBool(node.lexeme.val)
 		if err != nil {
-			panic(err) // should not happen
+			return never // should not happen, the lexer rules out invalid boolean literals
 		}
 		// return filter
-		return func(value, root any) bool {
+		return func(value, parent, root any) bool {
 			return b
 		}
 
@@ -91,11 +134,104 @@ func newFilter(node *filterNode) filter {
 	}
 }
 
-func never(value, root any) bool {
+func never(value, parent, root any) bool {
 	return false
 }
 
-func comparisonFilter(node *filterNode) filter {
+func comparisonFilter(ctx *pathContext, node *filterNode) filter {
+	return nodeToFilter(ctx, node, comparisonAcceptor(ctx, node, true))
+}
+
+// inFilter creates a filter which matches if every value of the left term is equal to a member of the set literal on
+// the right, members of different types are compared with the values of compatible types only
+func inFilter(ctx *pathContext, node *filterNode) filter {
+	// left filter scanner
+	lhsPath := newFilterScanner(ctx, node.child(0))
+	// set members
+	members := []typedValue{}
+	// check set literal
+	if set := node.child(1); set != nil {
+		// loop members
+		for _, member := range set.children {
+			// check member
+			if member != nil {
+				members = append(members, member.lexeme.literalValue())
+			}
+		}
+	}
+	// equality acceptor
+	equal := comparisonAcceptor(ctx, &filterNode{lexeme: lexeme{typ: lexemeFilterEquality}}, false)
+	// create filter
+	return func(value, parent, root any) bool {
+		// values of the left term
+		values := lhsPath(value, parent, root)
+		// loop values
+		for _, l := range values {
+			// find equal member
+			found := false
+			for _, r := range members {
+				if equal(l, r) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return len(values) > 0
+	}
+}
+
+// typeFilter creates a filter which matches if every value of the left term has the type named by the string literal
+// on the right
+func typeFilter(ctx *pathContext, node *filterNode) filter {
+	// left filter scanner
+	lhsPath := newFilterScanner(ctx, node.child(0))
+	// type name
+	name := ""
+	if literal := node.child(1); literal != nil {
+		name = literal.lexeme.literalValue().val
+	}
+	// create filter
+	return func(value, parent, root any) bool {
+		// values of the left term
+		values := lhsPath(value, parent, root)
+		// loop values
+		for _, v := range values {
+			// check value type
+			if v.typeName() != name {
+				return false
+			}
+		}
+		return len(values) > 0
+	}
+}
+
+// anyFilter creates a filter which matches if any pair of values in a comparison or regular expression match
+// is accepted, other filters are not affected
+func anyFilter(ctx *pathContext, node *filterNode) filter {
+	// check node
+	if node == nil {
+		return never
+	}
+	// process lexer token type
+	switch node.lexeme.typ {
+
+	case lexemeFilterEquality, lexemeFilterInequality, lexemeFilterGreaterThan, lexemeFilterGreaterThanOrEqual, lexemeFilterLessThan, lexemeFilterLessThanOrEqual:
+		return nodeToAnyFilter(ctx, node, comparisonAcceptor(ctx, node, true))
+
+	case lexemeFilterMatchesRegularExpression:
+		return nodeToAnyFilter(ctx, node, regularExpressionAcceptor(node))
+
+	default:
+		return newFilter(ctx, node)
+	}
+}
+
+// comparisonAcceptor creates the function comparing the values of a filter, comparisons of incompatible types are
+// recorded if strict is set and the StrictTypes option is used
+func comparisonAcceptor(ctx *pathContext, node *filterNode, strict bool) func(typedValue, typedValue) bool {
 	// create comparison function
 	compare := func(b bool) bool {
 		if b {
@@ -105,9 +241,44 @@ func comparisonFilter(node *filterNode) filter {
 		// use comparator from lexer token
 		return node.lexeme.comparator()(compareIncomparable)
 	}
-	// return filter
-	return nodeToFilter(node, func(l, r typedValue) bool {
+	// check custom equality
+	if ctx.equality != nil && node.lexeme.typ.isEquality() {
+		// return acceptor
+		return func(l, r typedValue) bool {
+			// single item arrays may be compared as their item
+			l, r = ctx.singletonItem(l), ctx.singletonItem(r)
+			return compare(ctx.equality(l.raw, r.raw))
+		}
+	}
+	// return acceptor
+	return func(l, r typedValue) bool {
+		// single item arrays may be compared as their item
+		l, r = ctx.singletonItem(l), ctx.singletonItem(r)
+		// check empty strings must be compared as null
+		if ctx.emptyStringIsNull && node.lexeme.typ.isEquality() {
+			l, r = l.emptyStringAsNull(), r.emptyStringAsNull()
+		}
+		// check operands must be compared as instants
+		if ctx.compareTimes {
+			if c, ok := ctx.compareInstants(l, r); ok {
+				return node.lexeme.comparator()(c)
+			}
+		}
 		if !l.typ.compatibleWith(r.typ) {
+			// check numeric strings must be compared as numbers
+			if ctx.looseEquality && node.lexeme.typ.isEquality() {
+				if ln, rn, ok := numericOperands(l, r); ok {
+					return compare(ctx.compareValues(ln, rn) == compareEqual)
+				}
+			}
+			// check string forms must be compared
+			if ctx.equalityAcrossTypes && node.lexeme.typ.isEquality() && l.typ.comparableAsString(r.typ) {
+				return compare(l.val == r.val)
+			}
+			// check incompatible types must be reported (any value may be compared with null)
+			if strict && ctx.typeErrors != nil && l.typ != nullValueType && r.typ != nullValueType {
+				ctx.typeErrors.record(node.lexeme.val, l, r)
+			}
 			return compare(false)
 		}
 		switch l.typ {
@@ -115,12 +286,42 @@ func comparisonFilter(node *filterNode) filter {
 			return compare(equalBooleans(l.val, r.val))
 
 		case nullValueType:
+			// null is unordered, only == and != may match
+			if !node.lexeme.typ.isEquality() {
+				return false
+			}
 			return compare(equalNulls(l.val, r.val))
 
 		default:
-			return node.lexeme.comparator()(compareNodeValues(l, r))
+			return node.lexeme.comparator()(ctx.compareValues(l, r))
 		}
-	})
+	}
+}
+
+// checkEquality checks the equality options can be combined, the custom equality (see WithEquality) decides on its own
+// whether two values are equal so the options changing the default equality are rejected. CompareTimes is accepted
+// since it still applies to the ordering comparisons.
+func (ctx *pathContext) checkEquality() error {
+	// check custom equality
+	if ctx.equality == nil {
+		return nil
+	}
+	// conflicting options
+	conflicts := []string{}
+	if ctx.emptyStringIsNull {
+		conflicts = append(conflicts, "EmptyStringIsNull")
+	}
+	if ctx.looseEquality {
+		conflicts = append(conflicts, "LooseEquality")
+	}
+	if ctx.equalityAcrossTypes {
+		conflicts = append(conflicts, "EqualityAcrossTypes")
+	}
+	// check conflicts
+	if len(conflicts) > 0 {
+		return fmt.Errorf("WithEquality option cannot be combined with %s, the custom equality decides whether values are equal", strings.Join(conflicts, ", "))
+	}
+	return nil
 }
 
 // var x, y typedValue
@@ -130,17 +331,17 @@ func comparisonFilter(node *filterNode) filter {
 // 	y = typedValue{stringValueType, "y"}
 // }
 
-func nodeToFilter(node *filterNode, accept func(typedValue, typedValue) bool) filter {
+func nodeToFilter(ctx *pathContext, node *filterNode, accept func(typedValue, typedValue) bool) filter {
 	// left filter scanner
-	lhsPath := newFilterScanner(node.children[0])
+	lhsPath := newFilterScanner(ctx, node.child(0))
 	// right filter scanner
-	rhsPath := newFilterScanner(node.children[1])
+	rhsPath := newFilterScanner(ctx, node.child(1))
 	// create filter
-	return func(value, root any) (result bool) {
+	return func(value, parent, root any) (result bool) {
 		// perform a set-wise comparison of the values in each path
 		match := false
-		for _, l := range lhsPath(value, root) {
-			for _, r := range rhsPath(value, root) {
+		for _, l := range lhsPath(value, parent, root) {
+			for _, r := range rhsPath(value, parent, root) {
 				if !accept(l, r) {
 					return false
 				}
@@ -151,6 +352,25 @@ func nodeToFilter(node *filterNode, accept func(typedValue, typedValue) bool) fi
 	}
 }
 
+func nodeToAnyFilter(ctx *pathContext, node *filterNode, accept func(typedValue, typedValue) bool) filter {
+	// left filter scanner
+	lhsPath := newFilterScanner(ctx, node.child(0))
+	// right filter scanner
+	rhsPath := newFilterScanner(ctx, node.child(1))
+	// create filter
+	return func(value, parent, root any) bool {
+		// find a pair of values accepted by the comparison
+		for _, l := range lhsPath(value, parent, root) {
+			for _, r := range rhsPath(value, parent, root) {
+				if accept(l, r) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
 func equalBooleans(l, r string) bool {
 	// Note: the YAML parser and our JSONPath lexer both rule out invalid boolean literals such as tRue.
 	return strings.EqualFold(l, r)
@@ -163,64 +383,126 @@ func equalNulls(l, r string) bool {
 
 // filterScanner is a function that returns a slice of typed values from either a filter literal or a path expression
 // which refers to either the current node or the root node. It is used in filter comparisons.
-type filterScanner func(value, root any) []typedValue
+type filterScanner func(value, parent, root any) []typedValue
 
-func emptyScanner(any, any) []typedValue {
+func emptyScanner(any, any, any) []typedValue {
 	return []typedValue{}
 }
 
-func newFilterScanner(node *filterNode) filterScanner {
+func newFilterScanner(ctx *pathContext, node *filterNode) filterScanner {
 	switch {
 	case node == nil:
 		return emptyScanner
 
 	case node.isItemFilter():
-		return pathFilterScanner(node)
+		return pathFilterScanner(ctx, node)
 
 	case node.isLiteral():
 		return literalFilterScanner(node)
 
+	case node.lexeme.typ == lexemeFilterTime:
+		return timeFilterScanner(ctx, node)
+
+	case node.lexeme.typ == lexemeFilterMin:
+		return aggregateFilterScanner(ctx, node, func(v, current float64) bool { return v < current })
+
+	case node.lexeme.typ == lexemeFilterMax:
+		return aggregateFilterScanner(ctx, node, func(v, current float64) bool { return v > current })
+
+	case node.lexeme.typ == lexemeFilterAvg:
+		return averageFilterScanner(ctx, node)
+
+	case node.lexeme.typ == lexemeFilterNormalize:
+		return normalizeFilterScanner(ctx, node)
+
+	case node.lexeme.typ == lexemeFilterLength:
+		return lengthFilterScanner(ctx, node, false)
+
+	case node.lexeme.typ == lexemeFilterByteLength:
+		return lengthFilterScanner(ctx, node, true)
+
+	case node.lexeme.typ == lexemeFilterText:
+		return textFilterScanner(ctx, node)
+
+	case node.lexeme.typ == lexemeFilterVersion:
+		return versionFilterScanner(ctx, node)
+
+	case node.lexeme.typ == lexemeFilterKeys:
+		return keysFilterScanner()
+
+	case node.lexeme.typ == lexemeFilterIndex:
+		return indexFilterScanner(node)
+
 	default:
 		return emptyScanner
 	}
 }
 
-func pathFilterScanner(node *filterNode) filterScanner {
-	// should we evaluate on actual value?
-	var at bool
+func pathFilterScanner(ctx *pathContext, node *filterNode) filterScanner {
+	// count compilation
+	countFilterCompilation()
+	// create path expression
+	path, err := newPathWithContext(ctx.filterContext(), filterSubpath(node))
+	if err != nil {
+		// empty path expression
+		return emptyScanner
+	}
 	// process node token type
 	switch node.lexeme.typ {
 
 	case lexemeFilterAt:
-		at = true
+		// evaluate on actual value (without its location)
+		return func(value, parent, root any) []typedValue {
+			// value
+			value = original(value)
+			return values(path.expression(getOperation, value, value))
+		}
+
+	case lexemeFilterParent:
+		// evaluate on the container of the actual value (if any)
+		return func(value, parent, root any) []typedValue {
+			// check parent
+			if parent == nil {
+				return []typedValue{}
+			}
+			// unwrap located container
+			parent, _ = unwrap(parent)
+			return values(path.expression(getOperation, parent, parent))
+		}
+
+	case lexemeFilterGrandparent:
+		// evaluate on the container of the container of the actual value (if any)
+		return func(value, parent, root any) []typedValue {
+			// the container of the container is known only if the container is located
+			_, loc := unwrap(parent)
+			// container of the container
+			grandparent := loc.container()
+			if grandparent == nil {
+				return []typedValue{}
+			}
+			return values(path.expression(getOperation, grandparent, grandparent))
+		}
 
 	case lexemeRoot:
-		at = false
+		// evaluate on root
+		return func(value, parent, root any) []typedValue {
+			return values(path.expression(getOperation, root, root))
+		}
 
 	default:
 		panic("false precondition")
 	}
+}
+
+// filterSubpath returns the subpath of a @, @^, @^^ or $ filter term
+func filterSubpath(node *filterNode) string {
 	// all subpaths concatenated
 	subpath := ""
 	// loop subpaths
 	for _, lexeme := range node.subpath {
 		subpath += lexeme.val
 	}
-	// create path expression
-	path, err := NewPath(subpath)
-	if err != nil {
-		// empty path expression
-		return emptyScanner
-	}
-	// return path expression
-	return func(value, root any) []typedValue {
-		// check we need to evaluate (value)
-		if at {
-			return values(path.expression(getOperation, value, value))
-		}
-		// evaluate on root
-		return values(path.expression(getOperation, root, root))
-	}
+	return subpath
 }
 
 type valueType int
@@ -233,6 +515,7 @@ const (
 	booleanValueType
 	nullValueType
 	regularExpressionValueType
+	versionValueType
 )
 
 func (vt valueType) isNumeric() bool {
@@ -243,9 +526,140 @@ func (vt valueType) compatibleWith(vt2 valueType) bool {
 	return vt.isNumeric() && vt2.isNumeric() || vt == vt2 || vt == stringValueType && vt2 == regularExpressionValueType
 }
 
+// comparableAsString checks whether values of the types are compared using their string forms when equality across
+// types is enabled, i.e. a string and a number or a boolean
+func (vt valueType) comparableAsString(vt2 valueType) bool {
+	// scalar types with a string form
+	scalar := func(t valueType) bool {
+		return t.isNumeric() || t == booleanValueType
+	}
+	return vt == stringValueType && scalar(vt2) || scalar(vt) && vt2 == stringValueType
+}
+
 type typedValue struct {
 	typ valueType
 	val string
+	raw any // value the typed value was created from, used by custom equality functions
+}
+
+// singletonItem returns the item of a single item array if the UnwrapSingletonArrays option is used, the value itself
+// otherwise
+func (ctx *pathContext) singletonItem(tv typedValue) typedValue {
+	// check option
+	if !ctx.unwrapSingletonArrays {
+		return tv
+	}
+	// array item
+	var item any
+	// process raw value type
+	switch a := tv.raw.(type) {
+
+	case []any:
+		// check length
+		if len(a) != 1 {
+			return tv
+		}
+		item = a[0]
+
+	case Array:
+		// check length
+		if a.Len() != 1 {
+			return tv
+		}
+		item, _ = a.Values(false, 0)()
+
+	default:
+		return tv
+	}
+	// typed value for item
+	v := typedValueOfNode(item)
+	// keep raw value
+	v.raw = item
+	return v
+}
+
+// numericOperands converts the string operand to a number when the other operand is a number, it returns false if
+// the operands are not a number and a numeric string
+func numericOperands(l, r typedValue) (typedValue, typedValue, bool) {
+	// process operand types
+	switch {
+
+	case l.typ.isNumeric() && r.typ == stringValueType:
+		// convert right operand
+		n, ok := r.numericString()
+		return l, n, ok
+
+	case l.typ == stringValueType && r.typ.isNumeric():
+		// convert left operand
+		n, ok := l.numericString()
+		return n, r, ok
+	}
+	return l, r, false
+}
+
+// numericString returns the float value of a string holding a finite decimal number, false otherwise
+func (tv typedValue) numericString() (typedValue, bool) {
+	// parse number
+	f, err := strconv.ParseFloat(tv.val, 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) || strings.ContainsAny(tv.val, "xX_") {
+		return tv, false
+	}
+	return typedValue{typ: floatValueType, val: tv.val, raw: tv.raw}, true
+}
+
+// emptyStringAsNull returns the null value if the value is an empty string, the value itself otherwise
+func (tv typedValue) emptyStringAsNull() typedValue {
+	// check empty string
+	if tv.typ == stringValueType && tv.val == "" {
+		return typedValueOfNull()
+	}
+	return tv
+}
+
+// typeName returns the name of the value type used by the is operator, empty for unknown types
+func (tv typedValue) typeName() string {
+	// process value type
+	switch tv.typ {
+	case intValueType, floatValueType:
+		return "number"
+	case stringValueType:
+		return "string"
+	case booleanValueType:
+		return "boolean"
+	case nullValueType:
+		return "null"
+	}
+	// process container type
+	switch tv.raw.(type) {
+	case []any, Array:
+		return "array"
+	case map[string]any, map[any]any, Map:
+		return "object"
+	}
+	return ""
+}
+
+func (tv typedValue) public() TypedValue {
+	// map value type
+	var t ValueType
+	switch tv.typ {
+	case stringValueType:
+		t = StringValue
+	case intValueType:
+		t = IntValue
+	case floatValueType:
+		t = FloatValue
+	case booleanValueType:
+		t = BooleanValue
+	case nullValueType:
+		t = NullValue
+	default:
+		t = UnknownValue
+	}
+	return TypedValue{
+		Type:  t,
+		Value: tv.val,
+	}
 }
 
 func typedValueOfNode(value any) typedValue {
@@ -337,8 +751,12 @@ func values(it Iterator) []typedValue {
 	result := []typedValue{}
 	// loop iterator
 	for v, ok := it(); ok; v, ok = it() {
-		// append typed for v
-		result = append(result, typedValueOfNode(v))
+		// typed value for v
+		tv := typedValueOfNode(v)
+		// keep raw value
+		tv.raw = v
+		// append typed value
+		result = append(result, tv)
 	}
 	return result
 }
@@ -347,19 +765,482 @@ func literalFilterScanner(n *filterNode) filterScanner {
 	// literal value from lexer token
 	v := n.lexeme.literalValue()
 	// create filter
-	return func(value, root any) []typedValue {
+	return func(value, parent, root any) []typedValue {
 		return []typedValue{v}
 	}
 }
 
-func matchRegularExpression(parseTree *filterNode) filter {
-	return nodeToFilter(parseTree, stringMatchesRegularExpression)
+// timeFilterScanner converts the values of the function argument into instants (seconds since the epoch, with
+// millisecond precision) so they can be compared. Numbers are epoch seconds (or milliseconds, see TimeEpochMillis)
+// and strings are RFC 3339 timestamps, other values are ignored.
+func timeFilterScanner(ctx *pathContext, node *filterNode) filterScanner {
+	// argument scanner
+	argument := newFilterScanner(ctx, node.child(0))
+	// create scanner
+	return func(value, parent, root any) []typedValue {
+		// result
+		result := []typedValue{}
+		// loop argument values
+		for _, v := range argument(value, parent, root) {
+			// convert value
+			if t, ok := ctx.instant(v); ok {
+				// seconds since the epoch
+				seconds := float64(t.UnixMilli()) / 1000
+				result = append(result, typedValue{typ: floatValueType, val: typedValueOfFloat64(seconds).val, raw: seconds})
+			}
+		}
+		return result
+	}
+}
+
+// normalizeFilterScanner creates a scanner returning the string argument values with leading and trailing whitespace
+// removed and internal whitespace collapsed into single spaces, other values are ignored
+func normalizeFilterScanner(ctx *pathContext, node *filterNode) filterScanner {
+	// argument scanner
+	argument := newFilterScanner(ctx, node.child(0))
+	// create scanner
+	return func(value, parent, root any) []typedValue {
+		// result
+		result := []typedValue{}
+		// loop argument values
+		for _, v := range argument(value, parent, root) {
+			// check value is a string
+			if v.typ == stringValueType {
+				// collapse whitespace
+				normalized := strings.Join(strings.Fields(v.val), " ")
+				result = append(result, typedValue{typ: stringValueType, val: normalized, raw: normalized})
+			}
+		}
+		return result
+	}
+}
+
+// lengthFilterScanner creates a scanner returning the length of the argument values: the number of characters (runes)
+// of strings and the number of items of arrays or members of objects. When bytes is set, the scanner returns the
+// number of bytes of the UTF-8 encoded strings instead and containers are ignored. Other values are ignored.
+func lengthFilterScanner(ctx *pathContext, node *filterNode, bytes bool) filterScanner {
+	// argument scanner
+	argument := newFilterScanner(ctx, node.child(0))
+	// create scanner
+	return func(value, parent, root any) []typedValue {
+		// result
+		result := []typedValue{}
+		// loop argument values
+		for _, v := range argument(value, parent, root) {
+			// length of value
+			length := -1
+			// check value is a string
+			if v.typ == stringValueType {
+				// check bytes are counted
+				if bytes {
+					length = len(v.val)
+				} else {
+					length = utf8.RuneCountInString(v.val)
+				}
+			} else if !bytes {
+				// process container type
+				switch c := adapt(v.raw).(type) {
+
+				case []any:
+					length = len(c)
+
+				case map[string]any:
+					length = len(c)
+
+				case Array:
+					length = c.Len()
+
+				case Map:
+					// count keys
+					length = 0
+					it := c.Keys()
+					for _, ok := it(); ok; _, ok = it() {
+						length++
+					}
+				}
+			}
+			// check length
+			if length >= 0 {
+				result = append(result, typedValue{typ: intValueType, val: strconv.Itoa(length), raw: length})
+			}
+		}
+		return result
+	}
+}
+
+// textFilterScanner creates a scanner returning the JSON encoding of the argument values as strings, with object
+// members sorted by name, so the text of a whole subtree can be searched. Values that cannot be encoded are ignored.
+func textFilterScanner(ctx *pathContext, node *filterNode) filterScanner {
+	// argument scanner
+	argument := newFilterScanner(ctx, node.child(0))
+	// create scanner
+	return func(value, parent, root any) []typedValue {
+		// result
+		result := []typedValue{}
+		// loop argument values
+		for _, v := range argument(value, parent, root) {
+			// encode value
+			if text, ok := jsonText(v.raw); ok {
+				result = append(result, typedValue{typ: stringValueType, val: text, raw: text})
+			}
+		}
+		return result
+	}
+}
+
+// jsonText returns the JSON encoding of the value, HTML characters are not escaped and object members are sorted by
+// name (Map and Array values are encoded as objects and arrays)
+func jsonText(value any) (string, bool) {
+	// encoder
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	// encode value
+	if err := encoder.Encode(plainValue(value)); err != nil {
+		return "", false
+	}
+	// remove the newline added by the encoder
+	return strings.TrimSuffix(b.String(), "\n"), true
+}
+
+// plainValue copies the Map and Array values found in the value into map[string]any and []any values, so they can be
+// encoded as JSON objects and arrays
+func plainValue(value any) any {
+	// process value type
+	switch v := adapt(value).(type) {
+
+	case map[string]any:
+		// copy map
+		m := make(map[string]any, len(v))
+		for k, mv := range v {
+			m[k] = plainValue(mv)
+		}
+		return m
+
+	case []any:
+		// copy array
+		a := make([]any, len(v))
+		for i, av := range v {
+			a[i] = plainValue(av)
+		}
+		return a
+
+	case Map:
+		// copy members
+		m := map[string]any{}
+		keys := v.Keys()
+		for k, ok := keys(); ok; k, ok = keys() {
+			// member value
+			key := fmt.Sprint(k)
+			if mv, found := v.Values(key)(); found {
+				m[key] = plainValue(mv)
+			}
+		}
+		return m
+
+	case Array:
+		// copy items
+		a := make([]any, 0, v.Len())
+		it := v.Values(false)
+		for av, ok := it(); ok; av, ok = it() {
+			a = append(a, plainValue(av))
+		}
+		return a
+
+	default:
+		return value
+	}
+}
+
+// keysFilterScanner creates a scanner returning the member names of the value when it is an object, arrays and other
+// values have no member names
+func keysFilterScanner() filterScanner {
+	// create scanner
+	return func(value, parent, root any) []typedValue {
+		// result
+		result := []typedValue{}
+		// unwrap value
+		value, _ = unwrap(value)
+		// process value type
+		switch o := value.(type) {
+
+		case map[string]any:
+			// loop members
+			loopMap(o, func(k string, v any) {
+				result = append(result, typedValue{typ: stringValueType, val: k, raw: k})
+			})
+
+		case Map:
+			// key iterator
+			it := o.Keys()
+			// loop keys
+			for k, ok := it(); ok; k, ok = it() {
+				result = append(result, typedValue{typ: stringValueType, val: k.(string), raw: k})
+			}
+		}
+		return result
+	}
+}
+
+// indexFilterScanner creates a scanner returning the index of the value in the array being filtered (modulo the
+// divisor, if any), or no value if the value is not an array item. The index is known from the location of the value.
+func indexFilterScanner(node *filterNode) filterScanner {
+	// divisor
+	divisor := 0
+	if literal := node.child(0); literal != nil {
+		divisor, _ = strconv.Atoi(literal.lexeme.val)
+	}
+	// create scanner
+	return func(value, parent, root any) []typedValue {
+		// location of value
+		_, loc := unwrap(value)
+		if loc == nil {
+			return []typedValue{}
+		}
+		// check value is an array item
+		index, ok := loc.key.(int)
+		if !ok || loc.name {
+			return []typedValue{}
+		}
+		// check modulo
+		if divisor > 0 {
+			index %= divisor
+		}
+		return []typedValue{{typ: intValueType, val: strconv.Itoa(index), raw: index}}
+	}
+}
+
+// versionFilterScanner creates a scanner returning the string argument values holding a semantic version (e.g. 1.2.0
+// or v1.2.0-rc.1) as versions, so they are compared by precedence. Other values are ignored.
+func versionFilterScanner(ctx *pathContext, node *filterNode) filterScanner {
+	// argument scanner
+	argument := newFilterScanner(ctx, node.child(0))
+	// create scanner
+	return func(value, parent, root any) []typedValue {
+		// result
+		result := []typedValue{}
+		// loop argument values
+		for _, v := range argument(value, parent, root) {
+			// check value is a version
+			if v.typ == stringValueType {
+				if _, ok := parseVersion(v.val); ok {
+					result = append(result, typedValue{typ: versionValueType, val: v.val, raw: v.raw})
+				}
+			}
+		}
+		return result
+	}
+}
+
+// aggregateFilterScanner creates a scanner returning the numeric argument value for which better returns true
+// compared to every other numeric argument value (min or max), or no value if the argument has no numeric values
+func aggregateFilterScanner(ctx *pathContext, node *filterNode, better func(v, current float64) bool) filterScanner {
+	// argument scanner
+	argument := newFilterScanner(ctx, node.child(0))
+	// create scanner
+	return func(value, parent, root any) []typedValue {
+		// result
+		result := []typedValue{}
+		// current aggregate
+		var current float64
+		// loop argument values
+		for _, v := range argument(value, parent, root) {
+			// check value is numeric
+			if !v.typ.isNumeric() {
+				continue
+			}
+			// parse value
+			f, err := strconv.ParseFloat(v.val, 64)
+			if err != nil {
+				continue
+			}
+			// check first value or better value
+			if len(result) == 0 || better(f, current) {
+				// update aggregate
+				current = f
+				result = []typedValue{v}
+			}
+		}
+		return result
+	}
+}
+
+// averageFilterScanner creates a scanner returning the mean of the numeric argument values, or no value if the
+// argument has no numeric values
+func averageFilterScanner(ctx *pathContext, node *filterNode) filterScanner {
+	// argument scanner
+	argument := newFilterScanner(ctx, node.child(0))
+	// create scanner
+	return func(value, parent, root any) []typedValue {
+		// sum and count
+		sum, count := 0.0, 0
+		// loop argument values
+		for _, v := range argument(value, parent, root) {
+			// check value is numeric
+			if !v.typ.isNumeric() {
+				continue
+			}
+			// parse value
+			f, err := strconv.ParseFloat(v.val, 64)
+			if err != nil {
+				continue
+			}
+			// update sum
+			sum += f
+			count++
+		}
+		// check numeric values
+		if count == 0 {
+			return []typedValue{}
+		}
+		// mean
+		mean := sum / float64(count)
+		return []typedValue{{typ: floatValueType, val: typedValueOfFloat64(mean).val, raw: mean}}
+	}
+}
+
+// instant converts an epoch number or a RFC 3339 string into a time
+func (ctx *pathContext) instant(v typedValue) (time.Time, bool) {
+	// process value type
+	switch v.typ {
+
+	case intValueType, floatValueType:
+		// epoch number
+		f, err := strconv.ParseFloat(v.val, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		// check epoch unit
+		if ctx.epochMillis {
+			return time.UnixMilli(int64(f)), true
+		}
+		return time.UnixMilli(int64(f * 1000)), true
+
+	case stringValueType:
+		// RFC 3339 timestamp
+		t, err := time.Parse(time.RFC3339Nano, v.val)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return t, true
+
+	default:
+		return time.Time{}, false
+	}
+}
+
+// compareInstants compares two timestamps, at least one of them a string, as instants
+func (ctx *pathContext) compareInstants(lhs, rhs typedValue) (comparison, bool) {
+	// check at least one string operand
+	if lhs.typ != stringValueType && rhs.typ != stringValueType {
+		return compareIncomparable, false
+	}
+	// convert operands
+	lt, ok := ctx.instant(lhs)
+	if !ok {
+		return compareIncomparable, false
+	}
+	rt, ok := ctx.instant(rhs)
+	if !ok {
+		return compareIncomparable, false
+	}
+	// compare instants
+	switch {
+	case lt.Before(rt):
+		return compareLessThan, true
+	case lt.After(rt):
+		return compareGreaterThan, true
+	default:
+		return compareEqual, true
+	}
+}
+
+func matchRegularExpression(ctx *pathContext, parseTree *filterNode) filter {
+	return nodeToFilter(ctx, parseTree, regularExpressionAcceptor(parseTree))
+}
+
+// regularExpressionAcceptor returns the function matching strings with the regular expression literal of the node,
+// the regular expression is compiled once when the filter is created
+func regularExpressionAcceptor(node *filterNode) func(typedValue, typedValue) bool {
+	// regular expression literal
+	literal := node.child(1)
+	if literal == nil {
+		return stringMatchesRegularExpression
+	}
+	// check pattern is read from the document
+	if literal.isItemFilter() {
+		return dynamicRegularExpressionAcceptor()
+	}
+	if !literal.isRegularExpressionLiteral() {
+		return stringMatchesRegularExpression
+	}
+	// count compilation
+	countFilterCompilation()
+	// compile regular expression
+	re, err := regexp.Compile(literal.lexeme.literalValue().val)
+	if err != nil {
+		return stringMatchesRegularExpression // should not happen, regex already compiled during lexing
+	}
+	// return acceptor
+	return func(s, expr typedValue) bool {
+		if s.typ != stringValueType || expr.typ != regularExpressionValueType {
+			return false // can't compare types so return false
+		}
+		return re.MatchString(s.val)
+	}
+}
+
+// maxDynamicRegularExpressions is the number of patterns read from the document kept compiled by each =~ filter
+const maxDynamicRegularExpressions = 64
+
+// dynamicRegularExpressionAcceptor matches strings with patterns read from the document, e.g. @.name =~ @.pattern.
+// Patterns are compiled when they are first used and cached, invalid patterns never match.
+func dynamicRegularExpressionAcceptor() func(typedValue, typedValue) bool {
+	// compiled patterns (nil for invalid patterns), paths may be evaluated concurrently
+	var mutex sync.Mutex
+	cache := map[string]*regexp.Regexp{}
+	// compile pattern
+	compile := func(pattern string) *regexp.Regexp {
+		// lock cache
+		mutex.Lock()
+		defer mutex.Unlock()
+		// check cache
+		if re, ok := cache[pattern]; ok {
+			return re
+		}
+		// check cache is full
+		if len(cache) >= maxDynamicRegularExpressions {
+			// start over
+			cache = map[string]*regexp.Regexp{}
+		}
+		// count compilation
+		countFilterCompilation()
+		// compile pattern
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			re = nil
+		}
+		cache[pattern] = re
+		return re
+	}
+	// return acceptor
+	return func(s, expr typedValue) bool {
+		// patterns must be strings
+		if s.typ != stringValueType || expr.typ != stringValueType {
+			return false // can't compare types so return false
+		}
+		// compiled pattern
+		re := compile(expr.val)
+		return re != nil && re.MatchString(s.val)
+	}
 }
 
 func stringMatchesRegularExpression(s, expr typedValue) bool {
 	if s.typ != stringValueType || expr.typ != regularExpressionValueType {
 		return false // can't compare types so return false
 	}
+	// count compilation
+	countFilterCompilation()
 	re, _ := regexp.Compile(expr.val) // regex already compiled during lexing
 	return re.Match([]byte(s.val))
 }
@@ -25,6 +25,7 @@ func TestNewFilter(t *testing.T) {
 		filter    string
 		parseTree *filterNode
 		jsonDoc   string
+		parentDoc string
 		rootDoc   string
 		match     bool
 		focus     bool // if true, run only tests with focus set to true
@@ -327,6 +328,144 @@ func TestNewFilter(t *testing.T) {
 			jsonDoc: `{ "x": null }`,
 			match:   true,
 		},
+		{
+			name:    "null comparison filter, path to literal, inequality on null",
+			filter:  `@.x!=null`,
+			jsonDoc: `{ "x": null }`,
+			match:   false,
+		},
+		{
+			name:    "null comparison filter, path to literal, inequality on non null",
+			filter:  `@.x!=null`,
+			jsonDoc: `{ "x": 1 }`,
+			match:   true,
+		},
+		{
+			name:    "null comparison filter, path to literal, equality on missing path",
+			filter:  `@.x==null`,
+			jsonDoc: `{ "y": 1 }`,
+			match:   false,
+		},
+		{
+			name:    "null comparison filter, path to literal, inequality on missing path",
+			filter:  `@.x!=null`,
+			jsonDoc: `{ "y": 1 }`,
+			match:   false,
+		},
+		{
+			name:    "null ordering filter, null path to number, less than",
+			filter:  `@.x<5`,
+			jsonDoc: `{ "x": null }`,
+			match:   false,
+		},
+		{
+			name:    "null ordering filter, null path to number, less than or equal",
+			filter:  `@.x<=5`,
+			jsonDoc: `{ "x": null }`,
+			match:   false,
+		},
+		{
+			name:    "null ordering filter, null path to number, greater than",
+			filter:  `@.x>5`,
+			jsonDoc: `{ "x": null }`,
+			match:   false,
+		},
+		{
+			name:    "null ordering filter, null path to number, greater than or equal",
+			filter:  `@.x>=5`,
+			jsonDoc: `{ "x": null }`,
+			match:   false,
+		},
+		{
+			name:    "null ordering filter, number path to null, less than",
+			filter:  `@.x<null`,
+			jsonDoc: `{ "x": 1 }`,
+			match:   false,
+		},
+		{
+			name:    "null ordering filter, null path to null, less than",
+			filter:  `@.x<null`,
+			jsonDoc: `{ "x": null }`,
+			match:   false,
+		},
+		{
+			name:    "null ordering filter, null path to null, less than or equal",
+			filter:  `@.x<=null`,
+			jsonDoc: `{ "x": null }`,
+			match:   false,
+		},
+		{
+			name:    "null ordering filter, null path to null, greater than or equal",
+			filter:  `@.x>=null`,
+			jsonDoc: `{ "x": null }`,
+			match:   false,
+		},
+		{
+			name:    "null ordering filter, null path to null path, greater than or equal",
+			filter:  `@.x>=@.y`,
+			jsonDoc: `{ "x": null, "y": null }`,
+			match:   false,
+		},
+		{
+			name:    "all quantifier, no match",
+			filter:  `all(@.x[*] > 0)`,
+			jsonDoc: `{ "x": [1, 2, -1] }`,
+			match:   false,
+		},
+		{
+			name:    "all quantifier, match",
+			filter:  `all(@.x[*] > 0)`,
+			jsonDoc: `{ "x": [1, 2, 3] }`,
+			match:   true,
+		},
+		{
+			name:    "all quantifier, empty",
+			filter:  `all(@.x[*] > 0)`,
+			jsonDoc: `{ "x": [] }`,
+			match:   false,
+		},
+		{
+			name:    "any quantifier, match",
+			filter:  `any(@.x[*] > 0)`,
+			jsonDoc: `{ "x": [1, 2, -1] }`,
+			match:   true,
+		},
+		{
+			name:    "any quantifier, no match",
+			filter:  `any(@.x[*] > 0)`,
+			jsonDoc: `{ "x": [-1, -2] }`,
+			match:   false,
+		},
+		{
+			name:    "any quantifier, empty",
+			filter:  `any(@.x[*] > 0)`,
+			jsonDoc: `{ "x": [] }`,
+			match:   false,
+		},
+		{
+			name:    "any quantifier, equality",
+			filter:  `any(@.x[*] == 'b')`,
+			jsonDoc: `{ "x": ["a", "b"] }`,
+			match:   true,
+		},
+		{
+			name:    "any quantifier, regular expression",
+			filter:  `any(@.x[*] =~ /^b/)`,
+			jsonDoc: `{ "x": ["a", "bc"] }`,
+			match:   true,
+		},
+		{
+			name:    "negated any quantifier",
+			filter:  `!any(@.x[*] < 0)`,
+			jsonDoc: `{ "x": [1, 2] }`,
+			match:   true,
+		},
+		{
+			name:    "any quantifier in conjunction",
+			filter:  `any(@.x[*] > 1) && @.y`,
+			jsonDoc: `{ "x": [1, 2], "y": true }`,
+			match:   true,
+		},
 		{
 			name:    "existence || existence filter",
 			filter:  "@.a || @.b",
@@ -480,6 +619,286 @@ func TestNewFilter(t *testing.T) {
 			rootDoc: `-1`,
 			match:   true,
 		},
+		{
+			name:    "string literal with escaped single quote, match",
+			filter:  `@.name == 'O\'Brien'`,
+			jsonDoc: `{"name": "O'Brien"}`,
+			match:   true,
+		},
+		{
+			name:    "string literal with escaped double quote, match",
+			filter:  `@.name == "say \"hi\""`,
+			jsonDoc: `{"name": "say \"hi\""}`,
+			match:   true,
+		},
+		{
+			name:    "single and double quoted string literals, match",
+			filter:  `'it\'s' == "it's"`,
+			jsonDoc: `{}`,
+			match:   true,
+		},
+		{
+			name:    "string literal with escaped backslash, match",
+			filter:  `@.path == 'C:\\temp'`,
+			jsonDoc: `{"path": "C:\\temp"}`,
+			match:   true,
+		},
+		{
+			name:    "string literal with unescaped backslash, match",
+			filter:  `@.path == 'a\b'`,
+			jsonDoc: `{"path": "a\\b"}`,
+			match:   true,
+		},
+		{
+			name:    "time function, epoch seconds and RFC 3339 string, match",
+			filter:  "time(@.ts) > time('2023-01-01T00:00:00Z')",
+			jsonDoc: `{"ts": 1700000000}`,
+			match:   true,
+		},
+		{
+			name:    "time function, RFC 3339 strings with offsets, match",
+			filter:  "time(@.ts) == time('2023-01-01T00:00:00Z')",
+			jsonDoc: `{"ts": "2023-01-01T01:00:00+01:00"}`,
+			match:   true,
+		},
+		{
+			name:    "time function, RFC 3339 string, no match",
+			filter:  "time(@.ts) > time(1700000000)",
+			jsonDoc: `{"ts": "2023-01-01T00:00:00Z"}`,
+			match:   false,
+		},
+		{
+			name:    "time function, invalid timestamp, no match",
+			filter:  "time(@.ts) < time('2023-01-01T00:00:00Z')",
+			jsonDoc: `{"ts": "yesterday"}`,
+			match:   false,
+		},
+		{
+			name:    "max function, match",
+			filter:  "max(@.*) == 3",
+			jsonDoc: `{"a": 1, "b": 3.0, "c": "x"}`,
+			match:   true,
+		},
+		{
+			name:    "min function, match",
+			filter:  "min(@.*) == -2",
+			jsonDoc: `[1, -2, 0]`,
+			match:   true,
+		},
+		{
+			name:    "avg function, match",
+			filter:  "avg(@.*) == 2.5",
+			jsonDoc: `[1, 4, "x", 2, 3]`,
+			match:   true,
+		},
+		{
+			name:    "avg function, no numeric values, no match",
+			filter:  "avg(@.*) != 0",
+			jsonDoc: `["a", "b"]`,
+			match:   false,
+		},
+		{
+			name:    "max function, no numeric values, no match",
+			filter:  "max(@.*) >= 0",
+			jsonDoc: `["a", "b"]`,
+			match:   false,
+		},
+		{
+			name:    "normalize function, match",
+			filter:  "normalize(@.name) == normalize('  John  Doe ')",
+			jsonDoc: `{"name": "John\t Doe"}`,
+			match:   true,
+		},
+		{
+			name:    "normalize function, no match",
+			filter:  "normalize(@.name) == 'JohnDoe'",
+			jsonDoc: `{"name": "John Doe"}`,
+			match:   false,
+		},
+		{
+			name:    "normalize function, non-string value, no match",
+			filter:  "normalize(@.name) == '1'",
+			jsonDoc: `{"name": 1}`,
+			match:   false,
+		},
+		{
+			name:    "length function, multibyte string, match",
+			filter:  "length(@.name) == 4",
+			jsonDoc: `{"name": "caf\u00e9"}`,
+			match:   true,
+		},
+		{
+			name:    "length function, array, match",
+			filter:  "length(@.tags) == 2",
+			jsonDoc: `{"tags": ["a", "b"]}`,
+			match:   true,
+		},
+		{
+			name:    "length function, object, match",
+			filter:  "length(@) == 1",
+			jsonDoc: `{"name": "x"}`,
+			match:   true,
+		},
+		{
+			name:    "length function, number, no match",
+			filter:  "length(@.name) >= 0",
+			jsonDoc: `{"name": 1}`,
+			match:   false,
+		},
+		{
+			name:    "bytelength function, multibyte string, match",
+			filter:  "bytelength(@.name) == 5",
+			jsonDoc: `{"name": "caf\u00e9"}`,
+			match:   true,
+		},
+		{
+			name:    "bytelength function, array, no match",
+			filter:  "bytelength(@.tags) >= 0",
+			jsonDoc: `{"tags": ["a", "b"]}`,
+			match:   false,
+		},
+		{
+			name:    "text function, nested string, match",
+			filter:  "text(@) =~ /needle/",
+			jsonDoc: `{"a": {"b": ["x", "a needle"]}}`,
+			match:   true,
+		},
+		{
+			name:    "text function, sorted keys, match",
+			filter:  "text(@.o) == '{\"a\":1,\"b\":[true,null]}'",
+			jsonDoc: `{"o": {"b": [true, null], "a": 1}}`,
+			match:   true,
+		},
+		{
+			name:    "text function, no match",
+			filter:  "text(@) =~ /needle/",
+			jsonDoc: `{"a": ["haystack"]}`,
+			match:   false,
+		},
+		{
+			name:    "version function, match",
+			filter:  "version(@.v) > version('1.9.0')",
+			jsonDoc: `{"v": "1.10.0"}`,
+			match:   true,
+		},
+		{
+			name:    "version function, pre-release, no match",
+			filter:  "version(@.v) >= version('1.2.0')",
+			jsonDoc: `{"v": "1.2.0-rc.1"}`,
+			match:   false,
+		},
+		{
+			name:    "version function, invalid version, no match",
+			filter:  "version(@.v) != version('1.2.0')",
+			jsonDoc: `{"v": "1.2"}`,
+			match:   false,
+		},
+		{
+			name:    "version function, compared with string, no match",
+			filter:  "version(@.v) == '1.2.0'",
+			jsonDoc: `{"v": "1.2.0"}`,
+			match:   false,
+		},
+		{
+			name:    "member names matching regular expression, match",
+			filter:  "@~ =~ /^tmp_/",
+			jsonDoc: `{"tmp_a": 1, "b": 2}`,
+			match:   true,
+		},
+		{
+			name:    "member names matching regular expression, no match",
+			filter:  "@~ =~ /^tmp_/",
+			jsonDoc: `{"a": 1, "b": 2}`,
+			match:   false,
+		},
+		{
+			name:    "member names of array, no match",
+			filter:  "@~ =~ /^tmp_/",
+			jsonDoc: `["tmp_a"]`,
+			match:   false,
+		},
+		{
+			name:    "member names existence, empty object, no match",
+			filter:  "@~",
+			jsonDoc: `{}`,
+			match:   false,
+		},
+		{
+			name:    "member names equal to literal, match",
+			filter:  "'b' == @~",
+			jsonDoc: `{"a": 1, "b": 2}`,
+			match:   true,
+		},
+		{
+			name:    "in filter, integer member, match",
+			filter:  "@.status in [200, 204, 'OK']",
+			jsonDoc: `{"status": 204}`,
+			match:   true,
+		},
+		{
+			name:    "in filter, string member, match",
+			filter:  "@.status in [200, 204, 'OK']",
+			jsonDoc: `{"status": "OK"}`,
+			match:   true,
+		},
+		{
+			name:    "in filter, float value and integer member, match",
+			filter:  "@.status in [200, 'OK']",
+			jsonDoc: `{"status": 200.0}`,
+			match:   true,
+		},
+		{
+			name:    "in filter, no match",
+			filter:  "@.status in [200, 204, 'OK']",
+			jsonDoc: `{"status": "200"}`,
+			match:   false,
+		},
+		{
+			name:    "in filter, empty set, no match",
+			filter:  "@.status in []",
+			jsonDoc: `{"status": 200}`,
+			match:   false,
+		},
+		{
+			name:    "in filter, missing value, no match",
+			filter:  "@.status in [200]",
+			jsonDoc: `{}`,
+			match:   false,
+		},
+		{
+			name:      "parent existence filter, match",
+			filter:    "@^.enabled",
+			jsonDoc:   `1`,
+			parentDoc: `{"enabled": true, "value": 1}`,
+			match:     true,
+		},
+		{
+			name:      "parent existence filter, no match",
+			filter:    "@^.enabled",
+			jsonDoc:   `1`,
+			parentDoc: `{"value": 1}`,
+			match:     false,
+		},
+		{
+			name:    "parent existence filter, no parent",
+			filter:  "@^",
+			jsonDoc: `1`,
+			match:   false,
+		},
+		{
+			name:      "parent comparison filter, match",
+			filter:    "@ > @^[0]",
+			jsonDoc:   `5`,
+			parentDoc: `[3, 1, 5]`,
+			match:     true,
+		},
+		{
+			name:      "parent comparison filter, no match",
+			filter:    "@^.enabled == true && @ > 1",
+			jsonDoc:   `1`,
+			parentDoc: `{"enabled": true, "value": 1}`,
+			match:     false,
+		},
 	}
 
 	focussed := false
@@ -497,9 +916,13 @@ func TestNewFilter(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			n := unmarshalDoc(t, tc.jsonDoc)
 			root := unmarshalDoc(t, tc.rootDoc)
+			var parent any
+			if tc.parentDoc != "" {
+				parent = unmarshalDoc(t, tc.parentDoc)
+			}
 
 			parseTree := parseFilterString(tc.filter)
-			match := newFilter(parseTree)(n, root)
+			match := newFilter(&pathContext{}, parseTree)(n, parent, root)
 			require.Equal(t, tc.match, match)
 		})
 	}
@@ -509,6 +932,64 @@ func TestNewFilter(t *testing.T) {
 	}
 }
 
+func TestNewFilterMalformedTrees(t *testing.T) {
+	// node helpers
+	node := func(typ lexemeType, val string, children ...*filterNode) *filterNode {
+		return &filterNode{lexeme: lexeme{typ: typ, val: val}, children: children}
+	}
+	at := func() *filterNode {
+		return &filterNode{lexeme: lexeme{typ: lexemeFilterAt, val: "@"}, subpath: []lexeme{{typ: lexemeDotChild, val: ".x"}}}
+	}
+	literal := func() *filterNode {
+		return node(lexemeFilterIntegerLiteral, "1")
+	}
+	cases := []struct {
+		name      string
+		parseTree *filterNode
+	}{
+		{name: "equality without operands", parseTree: node(lexemeFilterEquality, "==")},
+		{name: "equality with one operand", parseTree: node(lexemeFilterEquality, "==", at())},
+		{name: "equality with nil operands", parseTree: node(lexemeFilterEquality, "==", nil, nil)},
+		{name: "greater than with nil left operand", parseTree: node(lexemeFilterGreaterThan, ">", nil, literal())},
+		{name: "regular expression without operands", parseTree: node(lexemeFilterMatchesRegularExpression, "=~")},
+		{name: "in without operands", parseTree: node(lexemeFilterIn, "in")},
+		{name: "in without set", parseTree: node(lexemeFilterIn, "in", at())},
+		{name: "in with nil set member", parseTree: node(lexemeFilterIn, "in", at(), node(lexemeFilterSetBegin, "[", nil))},
+		{name: "not without operand", parseTree: node(lexemeFilterNot, "!")},
+		{name: "not with nil operand", parseTree: node(lexemeFilterNot, "!", nil)},
+		{name: "and without operands", parseTree: node(lexemeFilterAnd, "&&")},
+		{name: "or with one operand", parseTree: node(lexemeFilterOr, "||", node(lexemeFilterBooleanLiteral, "false"))},
+		{name: "all without operand", parseTree: node(lexemeFilterAll, "all(")},
+		{name: "any without operand", parseTree: node(lexemeFilterAny, "any(")},
+		{name: "any comparison without operands", parseTree: node(lexemeFilterAny, "any(", node(lexemeFilterLessThan, "<"))},
+		{name: "time function without argument", parseTree: node(lexemeFilterGreaterThan, ">", node(lexemeFilterTime, "time("), literal())},
+		{name: "min function without argument", parseTree: node(lexemeFilterEquality, "==", node(lexemeFilterMin, "min("), literal())},
+		{name: "max function without argument", parseTree: node(lexemeFilterEquality, "==", node(lexemeFilterMax, "max("), literal())},
+		{name: "avg function without argument", parseTree: node(lexemeFilterEquality, "==", node(lexemeFilterAvg, "avg("), literal())},
+		{name: "normalize function without argument", parseTree: node(lexemeFilterEquality, "==", node(lexemeFilterNormalize, "normalize("), literal())},
+		{name: "length function without argument", parseTree: node(lexemeFilterEquality, "==", node(lexemeFilterLength, "length("), literal())},
+		{name: "bytelength function without argument", parseTree: node(lexemeFilterEquality, "==", node(lexemeFilterByteLength, "bytelength("), literal())},
+		{name: "text function without argument", parseTree: node(lexemeFilterEquality, "==", node(lexemeFilterText, "text("), literal())},
+		{name: "version function without argument", parseTree: node(lexemeFilterEquality, "==", node(lexemeFilterVersion, "version("), literal())},
+		{name: "invalid boolean literal", parseTree: node(lexemeFilterBooleanLiteral, "yes")},
+		{name: "literal", parseTree: literal()},
+		{name: "set literal", parseTree: node(lexemeFilterSetBegin, "[")},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			value := map[string]any{"x": 1}
+			// act
+			require.NotPanics(t, func() {
+				match := newFilter(&pathContext{}, tc.parseTree)(value, nil, value)
+				// assert
+				require.False(t, match)
+			})
+		})
+	}
+}
+
 func unmarshalDoc(t *testing.T, doc string) any {
 	// empty document
 	if doc == "" {
@@ -0,0 +1,44 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+// interfaceMap stores the members of map[any]any values (e.g. documents decoded by gopkg.in/yaml.v2), adapted to the
+// Map interface by anyKeyMap. Keys with the same string form (e.g. 1 and "1") are a single member, see anyKeyMap for
+// the key that is used.
+type interfaceMap map[any]any
+
+// adapt converts container types without native support to the Map interface
+func adapt(value any) any {
+	// check map[any]any
+	if m, ok := value.(map[any]any); ok {
+		return anyKeyMap{interfaceMap(m)}
+	}
+	return value
+}
+
+func (m interfaceMap) rangeKeys(fn func(key any) bool) {
+	// loop map
+	for k := range m {
+		if !fn(k) {
+			return
+		}
+	}
+}
+
+func (m interfaceMap) load(key any) (any, bool) {
+	// find key in map
+	v, ok := m[key]
+	return v, ok
+}
+
+func (m interfaceMap) store(key, value any) {
+	m[key] = value
+}
+
+func (m interfaceMap) delete(key any) {
+	delete(m, key)
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func interfaceMapDocument() map[any]any {
+	return map[any]any{
+		"store": map[any]any{
+			"book": []any{
+				map[any]any{"author": "Nigel Rees", "price": 8.95},
+				map[any]any{"author": "Evelyn Waugh", "price": 12.99},
+			},
+		},
+		80: "http",
+	}
+}
+
+func TestInterfaceMapDotChild(t *testing.T) {
+	// arrange
+	var data = interfaceMapDocument()
+	var path = "$.store.book[0].author"
+	var expected = "Nigel Rees"
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestInterfaceMapNonStringKey(t *testing.T) {
+	// arrange
+	var data = interfaceMapDocument()
+	var path = "$['80']"
+	var expected = "http"
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestInterfaceMapWildcard(t *testing.T) {
+	// arrange
+	var data = interfaceMapDocument()
+	var path = "$.store.book[*].*"
+	var expected = []any{"Nigel Rees", 8.95, "Evelyn Waugh", 12.99}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestInterfaceMapRecursiveDescent(t *testing.T) {
+	// arrange
+	var data = interfaceMapDocument()
+	var path = "$..price"
+	var expected = []any{8.95, 12.99}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestInterfaceMapFilter(t *testing.T) {
+	// arrange
+	var data = interfaceMapDocument()
+	var path = "$.store.book[?(@.price > 10)].author"
+	var expected = []any{"Evelyn Waugh"}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestInterfaceMapSet(t *testing.T) {
+	// arrange
+	var data = map[any]any{80: "http", "a": 1}
+	var path = "$['80']"
+	var expected = map[any]any{80: "https", "a": 1}
+	// act
+	err := Set(data, path, "https")
+	if err != nil {
+		t.Errorf("Failed to set value: %v", err)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestInterfaceMapNormalizedPaths(t *testing.T) {
+	// arrange
+	var data = interfaceMapDocument()
+	var path = "$..author"
+	var expected = []string{"$['store']['book'][0]['author']", "$['store']['book'][1]['author']"}
+	// act
+	result, err := NormalizedPaths(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestInterfaceMapCollidingKeys(t *testing.T) {
+	// arrange (keys with the same string form are a single member)
+	var data = map[any]any{1: "int", "1": "string", uint(2): "uint", 2: "int", 3: "other"}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected any
+	}{
+		{path: "$.*", expected: []any{"string", "int", "other"}},
+		{path: "$[*]~", expected: []any{"1", "2", "3"}},
+		{path: "$..*", expected: []any{"string", "int", "other"}},
+		{path: "$['1']", expected: "string"},
+		{path: "$['2']", expected: "int"},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestInterfaceMapSetCollidingKey(t *testing.T) {
+	// arrange
+	var data = map[any]any{1: "int", "1": "string"}
+	var expected = map[any]any{1: "int", "1": "updated"}
+	// act
+	if err := Set(data, "$['1']", "updated"); err != nil {
+		t.Errorf("Failed to set value: %v", err)
+	}
+	// assert
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected data: %v", diff)
+	}
+}
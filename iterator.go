@@ -21,64 +21,99 @@ func (it Iterator) ToSlice() []any {
 }
 
 func (it Iterator) RecurseValues() Iterator {
+	// unbounded recursion
+	return it.recurseValues(0, -1, nil)
+}
+
+// recurseValues returns the values in the iterator (depth 0) and their descendants with depth in [min, max],
+// a negative max means no upper bound. The children of a value are skipped if into is not nil and returns false for it.
+func (it Iterator) recurseValues(min, max int, into func(any) bool) Iterator {
+	// value @ depth
+	type item struct {
+		value any
+		depth int
+	}
 	// stack
-	var stack []any
+	var stack []item
 	// return iterator
 	return func() (any, bool) {
-		// result
-		var value any
-		var ok bool
-		// check if stack is empty
-		if len(stack) > 0 {
-			// pop
-			value = stack[len(stack)-1]
-			stack = stack[:len(stack)-1]
-			// indicate we have a value
-			ok = true
-		} else {
-			// get next value from iterator
-			value, ok = it()
-			if !ok {
-				// exit
-				return nil, false
+		for {
+			// current item
+			var current item
+			// check if stack is empty
+			if len(stack) > 0 {
+				// pop
+				current = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			} else {
+				// get next value from iterator
+				value, ok := it()
+				if !ok {
+					// exit
+					return nil, false
+				}
+				// root value
+				current = item{value: value}
 			}
-		}
-		// process value type, add values to stack if value is a container
-		switch v := value.(type) {
+			// check we need to descend into the value
+			if (max < 0 || current.depth < max) && (into == nil || into(original(current.value))) {
+				// child depth
+				depth := current.depth + 1
+				// unwrap located value
+				value, loc := unwrap(current.value)
+				// process value type, add values to stack if value is a container
+				switch v := value.(type) {
 
-		case []any:
-			// iterate backwards (debugging and unit test consistency)
-			for i := len(v) - 1; i >= 0; i-- {
-				// append to stack
-				stack = append(stack, v[i])
-			}
+				case []any:
+					// iterate backwards (debugging and unit test consistency)
+					for i := len(v) - 1; i >= 0; i-- {
+						// append to stack
+						stack = append(stack, item{loc.index(i, v[i]), depth})
+					}
 
-		case map[string]any:
-			// iterate map
-			loopMap(v, func(_ string, mv any) {
-				// append to stack
-				stack = append(stack, mv)
-			})
+				case map[string]any:
+					// iterate map
+					loopMap(v, func(k string, mv any) {
+						// append to stack
+						stack = append(stack, item{loc.member(k, mv), depth})
+					})
 
-		case Array:
-			// backwards iterator (debugging and unit test consistency)
-			it := v.Values(true)
-			// loop over values
-			for iv, ok := it(); ok; iv, ok = it() {
-				// append to stack
-				stack = append(stack, iv)
-			}
+				case Array:
+					// check array is located
+					if loc != nil {
+						// iterate backwards (debugging and unit test consistency)
+						for i := v.Len() - 1; i >= 0; i-- {
+							// value @ i
+							if iv, ok := v.Values(false, i)(); ok {
+								// append to stack
+								stack = append(stack, item{loc.index(i, iv), depth})
+							}
+						}
+						break
+					}
+					// backwards iterator (debugging and unit test consistency)
+					it := v.Values(true)
+					// loop over values
+					for iv, ok := it(); ok; iv, ok = it() {
+						// append to stack
+						stack = append(stack, item{iv, depth})
+					}
 
-		case Map:
-			// iterator
-			it := v.Values()
-			// loop over values
-			for iv, ok := it(); ok; iv, ok = it() {
-				// append to stack
-				stack = append(stack, iv)
+				case Map:
+					// values
+					values := loc.mapValues(v).ToSlice()
+					// iterate backwards, values are popped in map order
+					for i := len(values) - 1; i >= 0; i-- {
+						// append to stack
+						stack = append(stack, item{values[i], depth})
+					}
+				}
+			}
+			// check value is within range
+			if current.depth >= min {
+				return current.value, true
 			}
 		}
-		return value, ok
 	}
 }
 
@@ -141,3 +176,25 @@ func FromIterators(its ...Iterator) Iterator {
 		}
 	}
 }
+
+// none is an iterator without values
+func none() (any, bool) {
+	return nil, false
+}
+
+// fromValue returns an iterator with a single value
+func fromValue(value any) Iterator {
+	// done flag
+	done := false
+	// return iterator
+	return func() (any, bool) {
+		// check value was returned
+		if done {
+			return nil, false
+		}
+		// update flag
+		done = true
+		// return value
+		return value, true
+	}
+}
@@ -285,3 +285,174 @@ func TestSetArrayField4WithStruct(t *testing.T) {
 		t.Errorf("Unexpected result: %v", diff)
 	}
 }
+
+func TestRecursiveDepthRangeWithStruct(t *testing.T) {
+	// arrange
+	var data = TestMap{
+		"name": "l0",
+		"a": TestArray{
+			TestMap{"name": "l2"},
+		},
+	}
+	var path = "$..name"
+	var expected = []any{"l0"}
+	// act
+	result, err := Get(data, path, RecursiveDepthRange(0, 1))
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestNormalizedPathsWithStruct(t *testing.T) {
+	// arrange
+	var data = TestMap{
+		"a": TestArray{
+			TestMap{"b": 1},
+			TestMap{"b": 2},
+		},
+		"c": TestMap{"b": 3},
+	}
+	var path = "$..b"
+	var expected = []string{"$['a'][0]['b']", "$['a'][1]['b']", "$['c']['b']"}
+	// act
+	result, err := NormalizedPaths(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestUpdateWhereWithStruct(t *testing.T) {
+	// arrange
+	var data = TestArray{1, "a", TestMap{"b": "c"}}
+	var path = "$..*"
+	var expected = TestArray{1, "a!", TestMap{"b": "c!"}}
+	// act
+	err := UpdateWhere(data, path, func(v any) bool {
+		_, ok := v.(string)
+		return ok
+	}, func(v any) any {
+		return v.(string) + "!"
+	})
+	if err != nil {
+		t.Errorf("Failed to update value: %v", err)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestCountWithStruct(t *testing.T) {
+	// arrange (counts on custom containers match the counts on built-in containers)
+	var data = TestMap{
+		"a": TestArray{1, 2, 3, 4},
+		"b": TestMap{"x": 1, "y": 2},
+	}
+	var builtin = map[string]any{
+		"a": []any{1, 2, 3, 4},
+		"b": map[string]any{"x": 1, "y": 2},
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected int
+	}{
+		{path: "$.a[*]", expected: 4},
+		{path: "$.a[-3:]", expected: 3},
+		{path: "$.a[?(@ > 2)]", expected: 2},
+		{path: "$.b.*", expected: 2},
+		{path: "$.*[3]", expected: 1},
+		{path: "$..*", expected: 8},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			count, err := Count(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to count values: %v", err)
+			}
+			builtinCount, err := Count(builtin, tc.path)
+			if err != nil {
+				t.Errorf("Failed to count values: %v", err)
+			}
+			if count != tc.expected || builtinCount != tc.expected {
+				t.Errorf("Unexpected count: %d (built-in %d), expected %d", count, builtinCount, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDistinctKeysWithStruct(t *testing.T) {
+	// arrange
+	var data = TestArray{
+		TestMap{"a": 1, "b": 2},
+		map[any]any{"c": 3, 1: 4},
+		TestMap{"b": 5},
+	}
+	var expected = []string{"1", "a", "b", "c"}
+	// act
+	result, err := DistinctKeys(data, "$[*]")
+	if err != nil {
+		t.Errorf("Failed to get keys: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestRecurseIntoWithStruct(t *testing.T) {
+	// arrange (custom arrays are visited, their items are not)
+	var data = map[string]any{
+		"a": TestArray{[]any{1}},
+		"b": []any{[]any{2}},
+	}
+	var path = "$..*"
+	var expected = []any{TestArray{[]any{1}}, []any{[]any{2}}, []any{2}, 2, []any{1}}
+	// act
+	result, err := Get(data, path, RecurseInto(func(value any) bool {
+		_, ok := value.(TestArray)
+		return !ok
+	}))
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestArraySubscriptPropertyNameWithStruct(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": TestArray{"x", "y"}}
+	var path = "$.a[*]~"
+	var expected = []any{0, 1}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSkipNullElementsWithStruct(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": TestArray{nil, 1, nil}}
+	var path = "$.a[*]"
+	var expected = []any{1}
+	// act
+	result, err := Get(data, path, SkipNullElements())
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
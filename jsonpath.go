@@ -6,25 +6,18 @@
 
 package jsonpath
 
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
 // Gets evaluates the given JsonPath expression on the input data and returns the result.
 // The result is a single value if the JsonPath expression is definite, otherwise a list.
 func Get(data any, expression string, options ...Option) (any, error) {
-	// initial context
-	ctx := &pathContext{
-		definite: true,
-	}
-	// process options
-	for _, option := range options {
-		// check option
-		if option.setup != nil {
-			// update context
-			option.setup(ctx)
-		}
-	}
-	// create lexer
-	lexer := lex(expression)
-	// create Path
-	path, err := createPath(ctx, lexer)
+	// create context and Path
+	ctx, path, err := compile(expression, options)
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +25,12 @@ func Get(data any, expression string, options ...Option) (any, error) {
 	it := path.expression(getOperation, data, data)
 	// collect results
 	result := it.ToSlice()
+	// check evaluation errors
+	if err := ctx.checkErrors(); err != nil {
+		return nil, err
+	}
+	// post-process result
+	ctx.postProcess(result)
 	// check we need to return a list
 	if ctx.returnList {
 		// return result
@@ -53,26 +52,393 @@ func Get(data any, expression string, options ...Option) (any, error) {
 	return result, nil
 }
 
+// GetArray evaluates the given definite JsonPath expression on the input data and returns the array it matches. An
+// error is returned if the expression is not definite, does not match exactly one value or the value is not an array.
+func GetArray(data any, expression string, options ...Option) ([]any, error) {
+	// get single value
+	value, err := getSingle(data, expression, options)
+	if err != nil {
+		return nil, err
+	}
+	// check value type
+	array, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("path %s matched a %T value, expected an array", expression, value)
+	}
+	return array, nil
+}
+
+// GetObject evaluates the given definite JsonPath expression on the input data and returns the object it matches. An
+// error is returned if the expression is not definite, does not match exactly one value or the value is not an object.
+// Objects implementing Map (e.g. *OrderedMap or map[any]any values) are returned as a copy of their members, so changes
+// to the returned map are not written to the document.
+func GetObject(data any, expression string, options ...Option) (map[string]any, error) {
+	// get single value
+	value, err := getSingle(data, expression, options)
+	if err != nil {
+		return nil, err
+	}
+	// process value type
+	switch v := adapt(value).(type) {
+
+	case map[string]any:
+		return v, nil
+
+	case Map:
+		// copy members
+		return mapMembers(v), nil
+
+	default:
+		return nil, fmt.Errorf("path %s matched a %T value, expected an object", expression, value)
+	}
+}
+
+// mapMembers returns the members of the given Map as a map[string]any, member values are not copied
+func mapMembers(m Map) map[string]any {
+	// members
+	members := map[string]any{}
+	// loop keys
+	keys := m.Keys()
+	for k, ok := keys(); ok; k, ok = keys() {
+		// member value
+		key := fmt.Sprint(k)
+		if v, found := m.Values(key)(); found {
+			members[key] = v
+		}
+	}
+	return members
+}
+
+// GetNumbers evaluates the given JsonPath expression on the input data and returns the matching numbers converted to
+// float64, matching values of other types are skipped.
+func GetNumbers(data any, expression string, options ...Option) ([]float64, error) {
+	// matching values
+	values, err := getValues(data, expression, options)
+	if err != nil {
+		return nil, err
+	}
+	// numbers
+	numbers := []float64{}
+	// loop values
+	for _, v := range values {
+		// skip containers (normalizeNumbers copies them)
+		if isContainer(v) {
+			continue
+		}
+		// check value is a number
+		if f, ok := normalizeNumbers(v).(float64); ok {
+			// append number
+			numbers = append(numbers, f)
+		}
+	}
+	return numbers, nil
+}
+
+// GetStrings evaluates the given JsonPath expression on the input data and returns the matching strings, matching
+// values of other types are skipped.
+func GetStrings(data any, expression string, options ...Option) ([]string, error) {
+	// matching values
+	values, err := getValues(data, expression, options)
+	if err != nil {
+		return nil, err
+	}
+	// strings
+	result := []string{}
+	// loop values
+	for _, v := range values {
+		// check value is a string
+		if s, ok := v.(string); ok {
+			// append string
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+// DistinctKeys evaluates the given JsonPath expression on the input data and returns the sorted member names found in
+// the matching objects, each of them once, e.g. $.store.book[*] returns every member name used by the books including
+// the optional ones. Matching values other than objects are ignored.
+func DistinctKeys(data any, expression string, options ...Option) ([]string, error) {
+	// matching values
+	values, err := getValues(data, expression, options)
+	if err != nil {
+		return nil, err
+	}
+	// distinct keys
+	keys := map[string]bool{}
+	// loop values
+	for _, v := range values {
+		// process value type
+		switch o := adapt(v).(type) {
+
+		case map[string]any:
+			// loop members
+			for k := range o {
+				keys[k] = true
+			}
+
+		case Map:
+			// key iterator
+			it := o.Keys()
+			// loop keys
+			for k, ok := it(); ok; k, ok = it() {
+				keys[k.(string)] = true
+			}
+		}
+	}
+	// result
+	result := make([]string, 0, len(keys))
+	// loop keys
+	for k := range keys {
+		result = append(result, k)
+	}
+	// sort keys
+	sort.Strings(result)
+	return result, nil
+}
+
+// getValues evaluates the given JsonPath expression on the input data and returns all the matching values
+func getValues(data any, expression string, options []Option) ([]any, error) {
+	// create context and Path
+	ctx, path, err := compile(expression, options)
+	if err != nil {
+		return nil, err
+	}
+	// evaluate it
+	result := path.expression(getOperation, data, data).ToSlice()
+	// check evaluation errors
+	if err := ctx.checkErrors(); err != nil {
+		return nil, err
+	}
+	// post-process result
+	ctx.postProcess(result)
+	return result, nil
+}
+
+// getSingle evaluates the given definite JsonPath expression on the input data and returns the single value it matches
+func getSingle(data any, expression string, options []Option) (any, error) {
+	// create context and Path
+	ctx, path, err := compile(expression, options)
+	if err != nil {
+		return nil, err
+	}
+	// check expression is definite
+	if !ctx.definite {
+		return nil, fmt.Errorf("path %s is not definite", expression)
+	}
+	// evaluate it
+	result := path.expression(getOperation, data, data).ToSlice()
+	// check evaluation errors
+	if err := ctx.checkErrors(); err != nil {
+		return nil, err
+	}
+	// post-process result
+	ctx.postProcess(result)
+	// check number of values in result
+	switch len(result) {
+	case 0:
+		return nil, fmt.Errorf("path %s did not match any value", expression)
+	case 1:
+		return result[0], nil
+	default:
+		return nil, fmt.Errorf("path %s matched %d values, expected one", expression, len(result))
+	}
+}
+
+// Count evaluates the given JsonPath expression on the input data and returns the number of matching values, the
+// values are counted as they are produced without collecting them.
+func Count(data any, expression string, options ...Option) (int, error) {
+	// create context and Path
+	ctx, path, err := compile(expression, options)
+	if err != nil {
+		return 0, err
+	}
+	// evaluate it
+	it := path.expression(getOperation, data, data)
+	// count values
+	count := 0
+	// loop iterator
+	for _, ok := it(); ok; _, ok = it() {
+		// increment count
+		count++
+	}
+	// check evaluation errors
+	if err := ctx.checkErrors(); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetNth evaluates the given JsonPath expression on the input data and returns the n-th (0-based) matching value,
+// found is false when the expression matches n values or less. Values are taken from the lazy iterator in evaluation
+// order and the evaluation stops once the n-th value is produced, unless the SortByValue option is used (all values are
+// collected and sorted first).
+func GetNth(data any, expression string, n int, options ...Option) (any, bool, error) {
+	// create context and Path
+	ctx, path, err := compile(expression, options)
+	if err != nil {
+		return nil, false, err
+	}
+	// check index
+	if n < 0 {
+		return nil, false, nil
+	}
+	// evaluate it
+	it := path.expression(getOperation, data, data)
+	// check sorted result
+	if ctx.sortByValue {
+		// collect and post-process values
+		values := it.ToSlice()
+		ctx.postProcess(values)
+		it = FromValues(false, values...)
+	}
+	// loop iterator up to the n-th value
+	value, found := it()
+	for i := 0; found && i < n; i++ {
+		value, found = it()
+	}
+	// check evaluation errors
+	if err := ctx.checkErrors(); err != nil {
+		return nil, false, err
+	}
+	// check value was found
+	if !found {
+		return nil, false, nil
+	}
+	// check value was taken from the lazy iterator
+	if !ctx.sortByValue {
+		// post-process value
+		values := []any{value}
+		ctx.postProcess(values)
+		value = values[0]
+	}
+	return value, true, nil
+}
+
+// GetNested evaluates the outer JsonPath expression on the input data, then evaluates the relative inner expression
+// (starting with @, e.g. @.title) on each outer match and returns the inner matches of every outer match in order, so
+// query fragments can be reused and composed, e.g. GetNested(data, "$.store.book[*]", "@.title"). Inside inner
+// filters $ is still the root of the input data. The options apply to both expressions.
+func GetNested(data any, outer, inner string, options ...Option) ([]any, error) {
+	// check inner expression is relative
+	if !strings.HasPrefix(inner, "@") {
+		return nil, fmt.Errorf("inner path %s must start with @", inner)
+	}
+	// create context and Path for outer expression
+	outerCtx, outerPath, err := compile(outer, options)
+	if err != nil {
+		return nil, err
+	}
+	// create context and Path for inner expression (evaluated from each outer match)
+	innerCtx, innerPath, err := compile("$"+strings.TrimPrefix(inner, "@"), options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inner path %s: %w", inner, err)
+	}
+	// result
+	result := []any{}
+	// evaluate outer expression
+	it := outerPath.expression(getOperation, data, data)
+	// loop outer matches
+	for match, ok := it(); ok; match, ok = it() {
+		// evaluate inner expression on match
+		result = append(result, innerPath.expression(getOperation, match, data).ToSlice()...)
+	}
+	// check evaluation errors
+	if err := outerCtx.checkErrors(); err != nil {
+		return nil, err
+	}
+	if err := innerCtx.checkErrors(); err != nil {
+		return nil, err
+	}
+	// post-process result
+	innerCtx.postProcess(result)
+	return result, nil
+}
+
+// Require evaluates each of the given JsonPath expressions on the input data and checks every expression matches at
+// least one value. The returned error lists every expression that is invalid or does not match, it is nil if all
+// expressions match.
+func Require(data any, expressions []string, options ...Option) error {
+	// errors
+	errs := []error{}
+	// loop expressions
+	for _, expression := range expressions {
+		// create context and Path
+		ctx, path, err := compile(expression, options)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid path %s: %w", expression, err))
+			continue
+		}
+		// count matching values
+		count := 0
+		// evaluate it
+		it := path.expression(getOperation, data, data)
+		// loop iterator
+		for _, ok := it(); ok; _, ok = it() {
+			// increment count
+			count++
+		}
+		// check evaluation errors
+		if err := ctx.checkErrors(); err != nil {
+			errs = append(errs, fmt.Errorf("path %s: %w", expression, err))
+			continue
+		}
+		// check count
+		if count == 0 {
+			errs = append(errs, fmt.Errorf("path %s did not match any value", expression))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // Sets evaluates the given JsonPath expression on the input data and sets the value to all matching paths.
 func Set(data any, expression string, value any, options ...Option) error {
-	// initial context
-	ctx := &pathContext{
-		definite: true,
+	// set values
+	_, err := SetN(data, expression, value, options...)
+	return err
+}
+
+// SetN evaluates the given JsonPath expression on the input data and sets the value to all matching paths like Set,
+// it returns the number of values written, zero if the expression did not match anything.
+func SetN(data any, expression string, value any, options ...Option) (int, error) {
+	// create context and Path
+	ctx, path, err := compile(expression, options)
+	if err != nil {
+		return 0, err
 	}
-	// process options
-	for _, option := range options {
-		// check option
-		if option.setup != nil {
-			// update context
-			option.setup(ctx)
+	// evaluate it
+	it := path.expression(setOperation, data, data)
+	// setters, all of them are collected before the data is modified
+	setters := []setExpression{}
+	// loop iterator
+	for r, ok := it(); ok; r, ok = it() {
+		// current iterator value must be setExpression
+		if f, ok := r.(setExpression); ok {
+			// append setter
+			setters = append(setters, f)
 		}
 	}
-	// create lexer
-	lexer := lex(expression)
-	// create Path
-	path, err := createPath(ctx, lexer)
+	// check evaluation errors
+	if err := ctx.checkErrors(); err != nil {
+		return 0, err
+	}
+	// loop setters
+	for _, f := range setters {
+		// set value
+		f(value)
+	}
+	return len(setters), nil
+}
+
+// SetFirst evaluates the given JsonPath expression on the input data and sets the value on the first matching path only.
+// It returns true if a value was set. Matching paths are visited in evaluation order, i.e. the order Get returns values
+// in: document order for arrays, OrderedMap and Map objects, unspecified order for Go maps. The evaluation stops at the
+// first match and the data is not modified if nothing matches.
+func SetFirst(data any, expression string, value any, options ...Option) (bool, error) {
+	// create context and Path
+	ctx, path, err := compile(expression, options)
 	if err != nil {
-		return err
+		return false, err
 	}
 	// evaluate it
 	it := path.expression(setOperation, data, data)
@@ -80,9 +446,269 @@ func Set(data any, expression string, value any, options ...Option) error {
 	for r, ok := it(); ok; r, ok = it() {
 		// current iterator value must be setExpression
 		if f, ok := r.(setExpression); ok {
+			// check evaluation errors
+			if err := ctx.checkErrors(); err != nil {
+				return false, err
+			}
 			// set value
 			f(value)
+			// stop on first match
+			return true, nil
+		}
+	}
+	// check evaluation errors
+	if err := ctx.checkErrors(); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// PlanSet evaluates the given JsonPath expression on the input data and returns the normalized path of each value
+// Set would write, including object members that would be created. The input data is not modified.
+func PlanSet(data any, expression string, options ...Option) ([]string, error) {
+	// create context and Path
+	ctx, path, err := compile(expression, options)
+	if err != nil {
+		return nil, err
+	}
+	// evaluate it on located root value
+	it := path.expression(setOperation, &located{value: data}, data)
+	// normalized paths
+	paths := []string{}
+	// loop iterator
+	for r, ok := it(); ok; r, ok = it() {
+		// current iterator value must be a located setExpression
+		if f, loc := unwrap(r); loc != nil {
+			// check expression
+			if _, ok := f.(setExpression); ok {
+				// append normalized path
+				paths = append(paths, loc.normalizedPath())
+			}
+		}
+	}
+	// check evaluation errors
+	if err := ctx.checkErrors(); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// UpdateWhere evaluates the given JsonPath expression on the input data and replaces each matching value for which
+// pred returns true with the result of calling fn on it. Other matching values are left unchanged.
+func UpdateWhere(data any, expression string, pred func(any) bool, fn func(any) any, options ...Option) error {
+	// create context and Path
+	ctx, path, err := compile(expression, options)
+	if err != nil {
+		return err
+	}
+	// locate matching values
+	locations := locate(path, data)
+	// check evaluation errors
+	if err := ctx.checkErrors(); err != nil {
+		return err
+	}
+	// loop matching locations
+	for _, l := range locations {
+		// check current value
+		if pred(l.value) {
+			// update value
+			l.set(fn(l.value))
 		}
 	}
 	return nil
 }
+
+// Prune evaluates each of the given JsonPath expressions on the input data and removes every matching value from its
+// containing object or array, e.g. to redact $..password from a document. Expressions are evaluated one at a time on
+// the data left by the previous ones, array items after a removed item are shifted down. It returns the number of
+// removed values, items of the root array cannot be removed.
+func Prune(data any, expressions []string, options ...Option) (int, error) {
+	// removed values
+	count := 0
+	// loop expressions
+	for _, expression := range expressions {
+		// create context and Path
+		ctx, path, err := compile(expression, options)
+		if err != nil {
+			return count, err
+		}
+		// locate matching values
+		locations := locate(path, data)
+		// check evaluation errors
+		if err := ctx.checkErrors(); err != nil {
+			return count, err
+		}
+		// remove duplicate locations, a value may match more than once through different descents
+		locations = uniqueLocations(locations)
+		// remove deepest values first and array items from the end, removals do not move the remaining locations
+		sort.SliceStable(locations, func(i, j int) bool {
+			// depths
+			di, dj := locations[i].depth(), locations[j].depth()
+			if di != dj {
+				return di > dj
+			}
+			// array indexes
+			ii, iok := locations[i].key.(int)
+			ij, jok := locations[j].key.(int)
+			if iok && jok {
+				return ii > ij
+			}
+			return iok && !jok
+		})
+		// loop locations
+		for _, l := range locations {
+			// remove value
+			if err := l.remove(); err != nil {
+				return count, err
+			}
+			// increment count
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Redact evaluates each of the given JsonPath expressions on the input data and replaces every matching value with
+// mask, e.g. "***", keeping the document structure. Members that do not exist are not created. It returns the number
+// of replaced values.
+func Redact(data any, expressions []string, mask any, options ...Option) (int, error) {
+	// replaced values
+	count := 0
+	// loop expressions
+	for _, expression := range expressions {
+		// create context and Path
+		ctx, path, err := compile(expression, options)
+		if err != nil {
+			return count, err
+		}
+		// locate matching values
+		locations := locate(path, data)
+		// check evaluation errors
+		if err := ctx.checkErrors(); err != nil {
+			return count, err
+		}
+		// loop locations
+		for _, l := range locations {
+			// replace value
+			if l.set(mask) {
+				// increment count
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+// ParentMatch is a value matched by GetWithParents together with the array or object containing it. Index is the
+// position of the value in the parent array (-1 otherwise) and Key is the member name of the value in the parent
+// object (empty otherwise). Parent is nil for the root value.
+type ParentMatch struct {
+	Value  any
+	Parent any
+	Index  int
+	Key    string
+}
+
+// GetWithParents evaluates the given JsonPath expression on the input data and returns each matching value together
+// with its parent container and the index or key selecting it. An error is returned for expressions matching values
+// that are not part of the input data (pipe decoders and count()).
+func GetWithParents(data any, expression string, options ...Option) ([]ParentMatch, error) {
+	// create context and Path
+	ctx, path, err := compile(expression, options)
+	if err != nil {
+		return nil, err
+	}
+	// check matching values have a location
+	if err := ctx.checkLocatable(expression); err != nil {
+		return nil, err
+	}
+	// locate matching values
+	locations := locate(path, data)
+	// check evaluation errors
+	if err := ctx.checkErrors(); err != nil {
+		return nil, err
+	}
+	// matches
+	matches := make([]ParentMatch, 0, len(locations))
+	// loop locations
+	for _, l := range locations {
+		// match
+		match := ParentMatch{
+			Value: l.value,
+			Index: -1,
+		}
+		// check parent
+		if l.parent != nil {
+			// parent container
+			match.Parent = l.parent.value
+			// process key type
+			switch k := l.key.(type) {
+			case int:
+				match.Index = k
+			case string:
+				match.Key = k
+			}
+		}
+		// append match
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+// NormalizedPaths evaluates the given JsonPath expression on the input data and returns the location of each
+// matching value using the RFC 9535 normalized path form, e.g. $['store']['book'][0]. An error is returned for
+// expressions matching values that are not part of the input data (pipe decoders and count()).
+func NormalizedPaths(data any, expression string, options ...Option) ([]string, error) {
+	// create context and Path
+	ctx, path, err := compile(expression, options)
+	if err != nil {
+		return nil, err
+	}
+	// check matching values have a location
+	if err := ctx.checkLocatable(expression); err != nil {
+		return nil, err
+	}
+	// locate matching values
+	locations := locate(path, data)
+	// check evaluation errors
+	if err := ctx.checkErrors(); err != nil {
+		return nil, err
+	}
+	// normalized paths
+	paths := make([]string, 0, len(locations))
+	// loop locations
+	for _, l := range locations {
+		// append normalized path
+		paths = append(paths, l.normalizedPath())
+	}
+	return paths, nil
+}
+
+func compile(expression string, options []Option) (*pathContext, *Path, error) {
+	// create context
+	ctx := newContext(options)
+	// create Path
+	path, err := newPathWithContext(ctx, expression)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ctx, path, nil
+}
+
+// newContext creates the context of a path compilation configured with the given options
+func newContext(options []Option) *pathContext {
+	// initial context
+	ctx := &pathContext{
+		definite: true,
+		options:  options,
+	}
+	// process options
+	for _, option := range options {
+		// check option
+		if option.setup != nil {
+			// update context
+			option.setup(ctx)
+		}
+	}
+	return ctx
+}
@@ -7,7 +7,15 @@
 package jsonpath
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -151,137 +159,3949 @@ func TestDefinitiveResult8(t *testing.T) {
 	}
 }
 
+func TestCount1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"a": []any{
+			map[string]any{"id": 1},
+			map[string]any{"id": 2},
+			map[string]any{"name": "x"},
+		},
+	}
+	var path = "$.a[*].id"
+	// act
+	count, err := Count(data, path)
+	if err != nil {
+		t.Errorf("Failed to count values: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Unexpected count: %d", count)
+	}
+}
+
+func TestCount2(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": 1}
+	var path = "$.b"
+	// act
+	count, err := Count(data, path)
+	if err != nil {
+		t.Errorf("Failed to count values: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Unexpected count: %d", count)
+	}
+}
+
+func TestCount3(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": 1}
+	var path = "$["
+	// act
+	_, err := Count(data, path)
+	if err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestGetNth1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"store": map[string]any{
+			"book": []any{
+				map[string]any{"title": "a", "price": 8.95},
+				map[string]any{"title": "b", "price": 12.99},
+				map[string]any{"title": "c", "price": 8.99},
+			},
+		},
+	}
+	// test cases
+	tcs := []struct {
+		n        int
+		expected any
+		found    bool
+	}{
+		{n: 0, expected: 8.95, found: true},
+		{n: 2, expected: 8.99, found: true},
+		{n: 3, expected: nil, found: false},
+		{n: -1, expected: nil, found: false},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(strconv.Itoa(tc.n), func(t *testing.T) {
+			// act
+			value, found, err := GetNth(data, "$..price", tc.n)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if found != tc.found {
+				t.Errorf("Unexpected found: %v", found)
+			}
+			if diff := cmp.Diff(tc.expected, value); diff != "" {
+				t.Errorf("Unexpected value: %v", diff)
+			}
+		})
+	}
+}
+
+func TestGetNth2(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": []any{3, 1, 2}}
+	// act
+	value, found, err := GetNth(data, "$.a[*]", 0, SortByValue(false))
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if !found || value != 1 {
+		t.Errorf("Unexpected value: %v, %v", value, found)
+	}
+}
+
+func TestGetNth3(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": 1}
+	// act
+	_, found, err := GetNth(data, "$[", 0)
+	if err == nil {
+		t.Errorf("Expected error")
+	}
+	if found {
+		t.Errorf("Unexpected found")
+	}
+}
+
+func TestGetNth4(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": []any{3, json.Number("1.5"), uint8(2)}}
+	// test cases
+	tcs := []struct {
+		name     string
+		n        int
+		options  []Option
+		expected any
+	}{
+		{name: "lazy", n: 1, options: []Option{NormalizeNumbers()}, expected: 1.5},
+		{name: "sorted", n: 1, options: []Option{NormalizeNumbers(), SortByValue(false)}, expected: float64(2)},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// act
+			value, found, err := GetNth(data, "$.a[*]", tc.n, tc.options...)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if !found {
+				t.Errorf("Expected value")
+			}
+			if diff := cmp.Diff(tc.expected, value); diff != "" {
+				t.Errorf("Unexpected value: %v", diff)
+			}
+		})
+	}
+}
+
+func TestGetNested1(t *testing.T) {
+	// arrange
+	var books = []any{
+		map[string]any{"title": "a", "price": 8.95, "tags": []any{"x", "y"}},
+		map[string]any{"title": "b", "price": 12.99},
+		map[string]any{"title": "c", "price": 8.99, "tags": []any{"z"}},
+	}
+	var data = map[string]any{
+		"limit": 10,
+		"store": map[string]any{"book": books},
+	}
+	// test cases
+	tcs := []struct {
+		outer    string
+		inner    string
+		expected []any
+	}{
+		{outer: "$.store.book[*]", inner: "@.title", expected: []any{"a", "b", "c"}},
+		{outer: "$.store.book[*]", inner: "@.tags[*]", expected: []any{"x", "y", "z"}},
+		{outer: "$.store.book", inner: "@[?(@.price < $.limit)].title", expected: []any{"a", "c"}},
+		{outer: "$.store.book[1:]", inner: "@..price", expected: []any{12.99, 8.99}},
+		{outer: "$.store.book[*]", inner: "@", expected: books},
+		{outer: "$.store.missing", inner: "@.title", expected: []any{}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.outer+" "+tc.inner, func(t *testing.T) {
+			// act
+			result, err := GetNested(data, tc.outer, tc.inner)
+			if err != nil {
+				t.Errorf("Failed to get values: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestGetNested2(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": []any{map[string]any{"b": 1}}}
+	// test cases
+	tcs := []struct {
+		outer    string
+		inner    string
+		expected string
+	}{
+		{outer: "$.a[*]", inner: "$.b", expected: "inner path $.b must start with @"},
+		{outer: "$.a[*]", inner: "@[", expected: `invalid inner path @[: unmatched [ at position 2, following "$["`},
+		{outer: "$[", inner: "@.b", expected: `unmatched [ at position 2, following "$["`},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.outer+" "+tc.inner, func(t *testing.T) {
+			// act
+			_, err := GetNested(data, tc.outer, tc.inner)
+			if err == nil {
+				t.Fatalf("Expected error")
+			}
+			if err.Error() != tc.expected {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetNested3(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": []any{map[string]any{"b": []any{3, json.Number("1.5")}}, map[string]any{"b": []any{uint8(2)}}}}
+	var expected = []any{1.5, float64(2), float64(3)}
+	// act
+	result, err := GetNested(data, "$.a[*]", "@.b[*]", NormalizeNumbers(), SortByValue(false))
+	if err != nil {
+		t.Errorf("Failed to get values: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestCountSelector(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"store": map[string]any{
+			"book": []any{
+				map[string]any{"category": "reference", "author": "Nigel Rees", "price": 8.95},
+				map[string]any{"category": "fiction", "author": "Evelyn Waugh", "price": 12.99},
+				map[string]any{"category": "fiction", "title": "Moby Dick", "price": 8.99},
+			},
+			"bicycle": map[string]any{"color": "red", "price": 19.95},
+		},
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected any
+	}{
+		{path: "$..author.count()", expected: 2},
+		{path: "$.store.book[*].count()", expected: 3},
+		{path: "$.store.book.count()", expected: 1},
+		{path: "$.store.book[?(@.category == 'fiction')].count()", expected: 2},
+		{path: "$..price.count()", expected: 4},
+		{path: "$..*.count()", expected: 17},
+		{path: "$.store.missing.count()", expected: 0},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestCountSelectorRecursiveDescent(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": []any{1, 2}}
+	var path = "$..count()"
+	// act
+	_, err := Get(data, path)
+	// assert
+	if err == nil {
+		t.Errorf("Expected error for expression %s", path)
+	}
+}
+
+func TestNormalizeNumbers1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"a": []any{1, int64(2), float32(3.5), 4.25, json.Number("5"), "6", true},
+	}
+	var path = "$.a[*]"
+	var expected = []any{float64(1), float64(2), 3.5, 4.25, float64(5), "6", true}
+	// act
+	result, err := Get(data, path, NormalizeNumbers())
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestNormalizeNumbers2(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"a": map[string]any{
+			"b": []any{1, json.Number("2.5")},
+			"c": uint8(3),
+		},
+	}
+	var path = "$.a"
+	var expected = map[string]any{
+		"b": []any{float64(1), 2.5},
+		"c": float64(3),
+	}
+	// act
+	result, err := Get(data, path, NormalizeNumbers())
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+	// input data must not be modified
+	if diff := cmp.Diff([]any{1, json.Number("2.5")}, data["a"].(map[string]any)["b"]); diff != "" {
+		t.Errorf("Unexpected data: %v", diff)
+	}
+}
+
+func TestNormalizeNumbers3(t *testing.T) {
+	// arrange
+	var expected = "NormalizeNumbers option is not supported by compiled paths and lenses, use functions returning the result (e.g. Get)"
+	// act
+	_, errPath := NewPath("$.a[*]", NormalizeNumbers())
+	_, errAll := CompileAll([]string{"$.a[*]"}, NormalizeNumbers())
+	_, errLens := NewLens("$.a", NormalizeNumbers())
+	// assert
+	for _, err := range []error{errPath, errAll, errLens} {
+		if err == nil || err.Error() != expected {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+}
+
+func TestSortByValue1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"scores": map[string]any{"ann": 7, "bob": 12.5, "cid": 9, "dan": 3},
+	}
+	// test cases
+	tcs := []struct {
+		desc     bool
+		expected []any
+	}{
+		{desc: false, expected: []any{3, 7, 9, 12.5}},
+		{desc: true, expected: []any{12.5, 9, 7, 3}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		// act
+		result, err := Get(data, "$.scores.*", SortByValue(tc.desc))
+		if err != nil {
+			t.Errorf("Failed to get value: %v", err)
+		}
+		if diff := cmp.Diff(tc.expected, result); diff != "" {
+			t.Errorf("Unexpected result (desc: %v): %v", tc.desc, diff)
+		}
+	}
+}
+
+func TestSortByValue2(t *testing.T) {
+	// arrange
+	var data = []any{"b", true, nil, []any{1}, 2, "a", map[string]any{"x": 1}, false, 1.5}
+	// test cases (types are grouped whatever the direction)
+	tcs := []struct {
+		desc     bool
+		expected []any
+	}{
+		{desc: false, expected: []any{1.5, 2, "a", "b", false, true, nil, []any{1}, map[string]any{"x": 1}}},
+		{desc: true, expected: []any{2, 1.5, "b", "a", true, false, nil, []any{1}, map[string]any{"x": 1}}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		// act
+		result, err := Get(data, "$[*]", SortByValue(tc.desc))
+		if err != nil {
+			t.Errorf("Failed to get value: %v", err)
+		}
+		if diff := cmp.Diff(tc.expected, result); diff != "" {
+			t.Errorf("Unexpected result (desc: %v): %v", tc.desc, diff)
+		}
+	}
+	// input data must not be modified
+	if data[0] != "b" {
+		t.Errorf("Unexpected data: %v", data)
+	}
+}
+
+func TestSortByValue3(t *testing.T) {
+	// arrange
+	var expected = "SortByValue option is not supported by compiled paths and lenses, use functions returning the result (e.g. Get)"
+	// act
+	_, errPath := NewPath("$.x[*]", SortByValue(false))
+	_, errAll := CompileAll([]string{"$.x[*]"}, SortByValue(false))
+	_, errLens := NewLens("$.x", SortByValue(false))
+	// assert
+	for _, err := range []error{errPath, errAll, errLens} {
+		if err == nil || err.Error() != expected {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+}
+
+func TestSortByValue4(t *testing.T) {
+	// arrange (numbers of any Go type are ranked and compared together)
+	var data = []any{map[string]any{}, json.Number("1"), uint(2), "a", float32(0.5), int64(-1), json.Number("1.5")}
+	// test cases
+	tcs := []struct {
+		desc     bool
+		expected []any
+	}{
+		{desc: false, expected: []any{int64(-1), float32(0.5), json.Number("1"), json.Number("1.5"), uint(2), "a", map[string]any{}}},
+		{desc: true, expected: []any{uint(2), json.Number("1.5"), json.Number("1"), float32(0.5), int64(-1), "a", map[string]any{}}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		// act
+		result, err := Get(data, "$[*]", SortByValue(tc.desc))
+		if err != nil {
+			t.Errorf("Failed to get value: %v", err)
+		}
+		if diff := cmp.Diff(tc.expected, result); diff != "" {
+			t.Errorf("Unexpected result (desc: %v): %v", tc.desc, diff)
+		}
+	}
+}
+
+func TestMixedUnion1(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": 1, "b": 2, "0": 3}
+	var path = "$['a', 0, 'b', 2]"
+	var expected = []any{1, 2}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestMixedUnion2(t *testing.T) {
+	// arrange
+	var data = []any{"x", "y", "z"}
+	var path = "$['a', 0, 'b', -1]"
+	var expected = []any{"x", "z"}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestMixedUnion3(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": "x"}
+	var path = "$.a['a', 0]"
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff([]any{}, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestMixedUnion4(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"a": []any{1, 2},
+		"b": map[string]any{"c": 3},
+	}
+	var path = "$[*][0, 'c']"
+	var expected = map[string]any{
+		"a": []any{0, 2},
+		"b": map[string]any{"c": 0},
+	}
+	// act
+	err := Set(data, path, 0)
+	if err != nil {
+		t.Errorf("Failed to set value: %v", err)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSetRecursiveDescent(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"a": 1,
+		"b": map[string]any{"a": 2},
+	}
+	var path = "$..a"
+	var expected = map[string]any{
+		"a": map[string]any{"a": 0},
+		"b": map[string]any{"a": map[string]any{"a": 0}},
+	}
+	// act
+	err := Set(data, path, map[string]any{"a": 0})
+	if err != nil {
+		t.Errorf("Failed to set value: %v", err)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestFilterStringLiteralEscapes(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"name": "O'Brien"},
+		map[string]any{"name": "OBrien"},
+	}
+	var path = `$[?(@.name == 'O\'Brien')].name`
+	var expected = []any{"O'Brien"}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestRequire1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"id":    1,
+		"items": []any{map[string]any{"name": "a"}},
+	}
+	var expressions = []string{"$.id", "$.items[*].name", "$..name"}
+	// act
+	err := Require(data, expressions)
+	// assert
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestRequire2(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"id":   1,
+		"tags": []any{},
+	}
+	var expressions = []string{"$.id", "$.name", "$.tags[*]", "$["}
+	var expected = strings.Join([]string{
+		"path $.name did not match any value",
+		"path $.tags[*] did not match any value",
+		`invalid path $[: unmatched [ at position 2, following "$["`,
+	}, "\n")
+	// act
+	err := Require(data, expressions)
+	// assert
+	if err == nil {
+		t.Fatalf("Expected error")
+	}
+	if diff := cmp.Diff(expected, err.Error()); diff != "" {
+		t.Errorf("Unexpected error: %v", diff)
+	}
+}
+
+func TestFullSlice1(t *testing.T) {
+	// arrange
+	var data = []any{1, 2, 3, 4, 5}
+	var path = "$[:]"
+	var expected = []any{1, 2, 3, 4, 5}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestFullSlice2(t *testing.T) {
+	// arrange
+	var data = []any{1, 2, 3, 4, 5}
+	var path = "$[::]"
+	var expected = []any{1, 2, 3, 4, 5}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestFullSlice3(t *testing.T) {
+	// arrange
+	var data = []any{1, 2, 3, 4, 5}
+	var path = "$[::2]"
+	var expected = []any{1, 3, 5}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestFullSlice4(t *testing.T) {
+	// arrange
+	var data = []any{1}
+	var path = "$[:]"
+	var expected = []any{1}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestFullSlice5(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": 1, "b": 2}
+	var path = "$[:]"
+	var expected = []any{}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestUnicodeEscapes(t *testing.T) {
+	// arrange
+	var data = map[string]any{"caf\u00e9": 1, "\U0001F600": 2, `a\u0041`: 3, "'": 4}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected any
+	}{
+		{path: `$['caf\u00e9']`, expected: 1},
+		{path: `$["caf\u00E9"]`, expected: 1},
+		{path: `$['\uD83D\uDE00']`, expected: 2},
+		{path: `$['a\\u0041']`, expected: 3},
+		{path: `$['\u0027', 'caf\u00e9']`, expected: []any{4, 1}},
+		{path: `$[?(@['caf\u00e9'] == 1)]`, expected: []any{data}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestInvalidUnicodeEscapes(t *testing.T) {
+	// test cases
+	tcs := []struct {
+		path     string
+		expected string
+	}{
+		{path: `$['\uD800']`, expected: `lone high surrogate \uD800 in unicode escape sequence at position 9, following "$['\\uD800"`},
+		{path: `$['\uDFFF']`, expected: `lone low surrogate \uDFFF in unicode escape sequence at position 9, following "$['\\uDFFF"`},
+		{path: `$['\u00']`, expected: `incomplete unicode escape sequence, \u must be followed by four hexadecimal digits at position 7, following "$['\\u00"`},
+		{path: `$['\u']`, expected: `incomplete unicode escape sequence, \u must be followed by four hexadecimal digits at position 5, following "$['\\u"`},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			_, err := NewPath(tc.path)
+			// assert
+			if err == nil || err.Error() != tc.expected {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestPaginationWindow(t *testing.T) {
+	// arrange
+	var items = []any{}
+	for i := 0; i < 12; i++ {
+		items = append(items, i)
+	}
+	var data = map[string]any{"items": items}
+	// test cases
+	tcs := []struct {
+		name     string
+		path     string
+		expected any
+	}{
+		{name: "window within bounds", path: "$.items[5:10]", expected: []any{5, 6, 7, 8, 9}},
+		{name: "window partially past end", path: "$.items[10:15]", expected: []any{10, 11}},
+		{name: "window entirely past end", path: "$.items[15:20]", expected: []any{}},
+		{name: "window starting at end", path: "$.items[12:17]", expected: []any{}},
+		{name: "negative offset from end", path: "$.items[-5:]", expected: []any{7, 8, 9, 10, 11}},
+		{name: "negative window from end", path: "$.items[-5:-3]", expected: []any{7, 8}},
+		{name: "negative offset before start", path: "$.items[-20:2]", expected: []any{0, 1}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestTimeFunction1(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"id": 1, "ts": 1640995200},
+		map[string]any{"id": 2, "ts": "2023-06-01T12:00:00Z"},
+		map[string]any{"id": 3, "ts": 1700000000},
+		map[string]any{"id": 4, "ts": "2022-06-01T12:00:00Z"},
+	}
+	var path = "$[?(time(@.ts) > time('2023-01-01T00:00:00Z'))].id"
+	var expected = []any{2, 3}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestTimeFunction2(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"id": 1, "ts": 1672531200000},
+		map[string]any{"id": 2, "ts": 1672531199999},
+	}
+	var path = "$[?(time(@.ts) >= time('2023-01-01T00:00:00Z'))].id"
+	var expected = []any{1}
+	// act
+	result, err := Get(data, path, TimeEpochMillis())
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestTimeFunction3(t *testing.T) {
+	// act
+	_, err := Get([]any{}, "$[?(time(@.ts))]")
+	// assert
+	if err == nil || err.Error() != "filter function time() must be compared" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestCompareTimes1(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"id": 1, "ts": 1640995200},
+		map[string]any{"id": 2, "ts": "2023-06-01T14:00:00+02:00"},
+		map[string]any{"id": 3, "ts": 1700000000},
+		map[string]any{"id": 4, "ts": "2022-06-01T12:00:00Z"},
+	}
+	var path = "$[?(@.ts > '2023-01-01T00:00:00Z')].id"
+	var expected = []any{2, 3}
+	// act
+	result, err := Get(data, path, CompareTimes())
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestCompareTimes2(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"id": 1, "ts": 1672531200000, "deadline": "2023-01-01T00:00:00Z"},
+		map[string]any{"id": 2, "ts": 1672531199999, "deadline": "2023-01-01T00:00:00Z"},
+		map[string]any{"id": 3, "ts": "2023-01-01T01:00:00+01:00", "deadline": 1672531200000},
+	}
+	var path = "$[?(@.ts == @.deadline)].id"
+	var expected = []any{1, 3}
+	// act
+	result, err := Get(data, path, CompareTimes(), TimeEpochMillis())
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestCompareTimes3(t *testing.T) {
+	// arrange (operands that are not timestamps are compared as usual)
+	var data = []any{
+		map[string]any{"id": 1, "name": "b", "n": 5},
+		map[string]any{"id": 2, "name": "a", "n": "5"},
+	}
+	var path = "$[?(@.name == 'b' || @.n == 5)].id"
+	var expected = []any{1}
+	// act
+	result, err := Get(data, path, CompareTimes())
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestMaxFunction1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"players": []any{
+			map[string]any{"name": "ann", "score": 7},
+			map[string]any{"name": "bob", "score": 12},
+			map[string]any{"name": "cid", "score": 9},
+		},
+	}
+	var path = "$.players[?(@.score == max($.players[*].score))].name"
+	var expected = []any{"bob"}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestMaxFunction2(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"players": []any{
+			map[string]any{"name": "ann", "score": 12},
+			map[string]any{"name": "bob", "score": 12.0},
+			map[string]any{"name": "cid", "score": 9},
+		},
+	}
+	var path = "$.players[?(@.score == max($.players[*].score))].name"
+	var expected = []any{"ann", "bob"}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestMinFunction(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"price": 3.5},
+		map[string]any{"price": 1},
+		map[string]any{"price": 2},
+	}
+	var path = "$[?(@.price == min($[*].price))]"
+	var expected = []any{map[string]any{"price": 1}}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestAggregateComparisons(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"teams": []any{
+			map[string]any{"name": "a", "scores": []any{1, 2, 6}},
+			map[string]any{"name": "b", "scores": []any{4, 4}},
+			map[string]any{"name": "c", "scores": []any{}},
+			map[string]any{"name": "d", "scores": []any{3}},
+		},
+	}
+	// test cases (the aggregate is a single value compared with each value of the multi-valued side)
+	tcs := []struct {
+		path     string
+		expected []any
+	}{
+		// every score must be above the average, which is impossible
+		{path: "$.teams[?(@.scores[*] > avg(@.scores[*]))].name", expected: []any{}},
+		// some score is above the average
+		{path: "$.teams[?(any(@.scores[*] > avg(@.scores[*])))].name", expected: []any{"a"}},
+		// every score is the average, there must be a score (c has none)
+		{path: "$.teams[?(@.scores[*] == avg(@.scores[*]))].name", expected: []any{"b", "d"}},
+		{path: "$.teams[?(@.scores[*] >= min(@.scores[*]))].name", expected: []any{"a", "b", "d"}},
+		{path: "$.teams[?(any(@.scores[*] > avg($.teams[*].scores[*])))].name", expected: []any{"a", "b"}},
+		{path: "$.teams[?(avg(@.scores[*]) > 3)].name", expected: []any{"b"}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestAnyRegularExpressionMatch(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"posts": []any{
+			map[string]any{"id": 1, "tags": []any{"golang", "rust"}},
+			map[string]any{"id": 2, "tags": []any{"gopher", "go"}},
+			map[string]any{"id": 3, "tags": []any{"java"}},
+			map[string]any{"id": 4, "tags": []any{}},
+			map[string]any{"id": 5, "tags": []any{7, "go"}},
+		},
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected []any
+	}{
+		// every tag must match
+		{path: "$.posts[?(@.tags[*] =~ /^go/)].id", expected: []any{2}},
+		// some tag matches, non-string tags are ignored
+		{path: "$.posts[?(any(@.tags[*] =~ /^go/))].id", expected: []any{1, 2, 5}},
+		// no tag matches (including posts without tags)
+		{path: "$.posts[?(!any(@.tags[*] =~ /^go/))].id", expected: []any{3, 4}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestNormalizeFunction(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"name": " John   Doe"},
+		map[string]any{"name": "Jane Doe"},
+		map[string]any{"name": "JohnDoe"},
+	}
+	var path = "$[?(normalize(@.name) == normalize('  John  Doe '))].name"
+	var expected = []any{" John   Doe"}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestVersionFunction(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"name": "a", "v": "1.9.0"},
+		map[string]any{"name": "b", "v": "1.10.0"},
+		map[string]any{"name": "c", "v": "v1.2.0"},
+		map[string]any{"name": "d", "v": "1.2.0-rc.1"},
+		map[string]any{"name": "e", "v": "1.2"},
+		map[string]any{"name": "f", "v": 1.2},
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected []any
+	}{
+		{path: "$[?(version(@.v) >= version('1.2.0'))].name", expected: []any{"a", "b", "c"}},
+		{path: "$[?(version(@.v) > version('1.9.0'))].name", expected: []any{"b"}},
+		{path: "$[?(version(@.v) < version('1.2.0'))].name", expected: []any{"d"}},
+		{path: "$[?(version(@.v) == version('1.2.0+build.1'))].name", expected: []any{"c"}},
+		{path: "$[?(version(@.v) == version(@.v))].name", expected: []any{"a", "b", "c", "d"}},
+		{path: "$[?(version(@.v) > version('1.2'))].name", expected: []any{}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestLengthFunctions(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"name": "caf\u00e9", "tags": []any{"a", "b"}},
+		map[string]any{"name": "abcd", "tags": []any{}},
+		map[string]any{"name": "\U0001F600", "tags": map[string]any{"a": 1, "b": 2}},
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected []any
+	}{
+		{path: "$[?(length(@.name) == 4)].name", expected: []any{"caf\u00e9", "abcd"}},
+		{path: "$[?(bytelength(@.name) == 4)].name", expected: []any{"abcd", "\U0001F600"}},
+		{path: "$[?(bytelength(@.name) > length(@.name))].name", expected: []any{"caf\u00e9", "\U0001F600"}},
+		{path: "$[?(length(@.name) <= 1)].name", expected: []any{"\U0001F600"}},
+		{path: "$[?(length(@.tags) == 2)].name", expected: []any{"caf\u00e9", "\U0001F600"}},
+		{path: "$[?(bytelength(@.tags) >= 0)].name", expected: []any{}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestTextFunction(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"items": []any{
+			map[string]any{"id": 1, "meta": map[string]any{"notes": []any{"a", "b <needle>"}}},
+			map[string]any{"id": 2, "meta": map[string]any{"notes": []any{}}},
+			map[string]any{"id": 3, "tags": []any{[]any{"x"}, []any{"needle"}}},
+		},
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected []any
+	}{
+		{path: "$.items[?(text(@) =~ /needle/)].id", expected: []any{1, 3}},
+		{path: "$.items[?(text(@.meta) =~ /needle/)].id", expected: []any{1}},
+		{path: "$.items[?(text(@.meta.notes) == '[]')].id", expected: []any{2}},
+		{path: "$.items[?(text(@.tags) == '[[\"x\"],[\"needle\"]]')].id", expected: []any{3}},
+		{path: "$.items[?(text(@.id) == '2')].id", expected: []any{2}},
+		{path: "$.items[?(text(@) =~ /<needle>/)].id", expected: []any{1}},
+		{path: "$.items[?(text(@.missing) =~ /.*/)].id", expected: []any{}},
+		{path: "$.items[2].tags..[?(text(@) =~ /needle/)]", expected: []any{[]any{"needle"}, "needle"}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestTextFunctionOrderedMap(t *testing.T) {
+	// arrange
+	var object = NewOrderedMap()
+	object.Set("z", "needle")
+	object.Set("a", []any{1, 2})
+	var data = []any{object}
+	// act
+	result, err := Get(data, "$[?(text(@) == '{\"a\":[1,2],\"z\":\"needle\"}')].z")
+	// assert
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff([]any{"needle"}, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestFilterKeys(t *testing.T) {
+	// arrange
+	var object = NewOrderedMap()
+	object.Set("id", 4)
+	object.Set("tmp_c", 1)
+	var data = map[string]any{
+		"items": []any{
+			map[string]any{"id": 1, "tmp_a": true},
+			map[string]any{"id": 2},
+			[]any{"tmp_b"},
+			object,
+		},
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected any
+	}{
+		{path: "$.items[?(@~ =~ /^tmp_/)].id", expected: []any{1, 4}},
+		{path: "$.items[?(!(@~ =~ /^tmp_/))].id", expected: []any{2}},
+		{path: "$.items[?(@~ in ['id'])].id", expected: []any{2}},
+		{path: "$.items[0][?(@~ =~ /^tmp_/)].id", expected: []any{1}},
+		{path: "$.items[1][?(@~ =~ /^tmp_/)].id", expected: []any{}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestFilterIndex(t *testing.T) {
+	// arrange
+	var series = []any{}
+	for i := 0; i < 25; i++ {
+		series = append(series, i*10)
+	}
+	var data = map[string]any{
+		"series": series,
+		"object": map[string]any{"a": 1},
+		"items":  []any{map[string]any{"id": 1}, map[string]any{"id": 2}, map[string]any{"id": 3}},
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected any
+	}{
+		{path: "$.series[?(@@index % 10 == 0)]", expected: []any{0, 100, 200}},
+		{path: "$.series[?(@@index % 2 == 1 && @ > 150)]", expected: []any{170, 190, 210, 230}},
+		{path: "$.series[?(3 > @@index)]", expected: []any{0, 10, 20}},
+		{path: "$.series[?(@@index in [1, 2])]", expected: []any{10, 20}},
+		{path: "$.items[?(@@index % 2 == 0)].id", expected: []any{1, 3}},
+		{path: "$.object[?(@@index == 0)]", expected: []any{}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestFilterIndexErrors(t *testing.T) {
+	// test cases
+	tcs := []struct {
+		path     string
+		expected string
+	}{
+		{path: "$.series[?(@@index)]", expected: "filter term @@index must be compared"},
+		{path: "$.series[?(@@index % 0 == 0)]", expected: `modulo divisor must be a positive integer at position 22, following "% 0"`},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			_, err := NewPath(tc.path)
+			// assert
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestInFilter(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"id": 1, "status": 200},
+		map[string]any{"id": 2, "status": "OK"},
+		map[string]any{"id": 3, "status": 404},
+		map[string]any{"id": 4, "status": "200"},
+	}
+	var path = "$[?(@.status in [200, 204, 'OK'])].id"
+	var expected = []any{1, 2}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+// foldEqual compares strings ignoring case and surrounding whitespace, other values must be equal
+func foldEqual(a, b any) bool {
+	// check strings
+	sa, ok1 := a.(string)
+	sb, ok2 := b.(string)
+	if ok1 && ok2 {
+		return strings.EqualFold(strings.TrimSpace(sa), strings.TrimSpace(sb))
+	}
+	return a == b
+}
+
+func TestWithEquality1(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"id": 1, "name": " Alice "},
+		map[string]any{"id": 2, "name": "ALICE"},
+		map[string]any{"id": 3, "name": "Bob"},
+	}
+	var path = "$[?(@.name == 'alice')].id"
+	var expected = []any{1, 2}
+	// act
+	result, err := Get(data, path, WithEquality(foldEqual))
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestWithEquality2(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"id": 1, "name": " Alice "},
+		map[string]any{"id": 2, "name": "Bob"},
+	}
+	var path = "$[?(@.name != 'ALICE')].id"
+	var expected = []any{2}
+	// act
+	result, err := Get(data, path, WithEquality(foldEqual))
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestWithEquality3(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"id": 1, "status": "ok"},
+		map[string]any{"id": 2, "status": 200},
+		map[string]any{"id": 3, "status": "failed"},
+	}
+	var path = "$[?(@.status in ['OK', 200])].id"
+	var expected = []any{1, 2}
+	// act
+	result, err := Get(data, path, WithEquality(foldEqual))
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestWithEquality4(t *testing.T) {
+	// arrange
+	var data = []any{map[string]any{"id": 1, "name": "alice"}}
+	// test cases (options changing the default equality conflict with the custom equality)
+	tcs := []struct {
+		name     string
+		options  []Option
+		expected string
+	}{
+		{name: "empty string is null", options: []Option{EmptyStringIsNull()}, expected: "WithEquality option cannot be combined with EmptyStringIsNull, the custom equality decides whether values are equal"},
+		{name: "loose equality", options: []Option{LooseEquality()}, expected: "WithEquality option cannot be combined with LooseEquality, the custom equality decides whether values are equal"},
+		{name: "equality across types", options: []Option{EqualityAcrossTypes()}, expected: "WithEquality option cannot be combined with EqualityAcrossTypes, the custom equality decides whether values are equal"},
+		{name: "several options", options: []Option{LooseEquality(), EmptyStringIsNull()}, expected: "WithEquality option cannot be combined with EmptyStringIsNull, LooseEquality, the custom equality decides whether values are equal"},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// act
+			_, err := Get(data, "$[?(@.name == 'ALICE')].id", append(tc.options, WithEquality(foldEqual))...)
+			if err == nil {
+				t.Fatalf("Expected error")
+			}
+			if err.Error() != tc.expected {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestWithEquality5(t *testing.T) {
+	// arrange (times are compared by ordering comparisons only)
+	var data = []any{
+		map[string]any{"id": 1, "at": "2023-06-01T00:00:00Z", "name": "ALICE"},
+		map[string]any{"id": 2, "at": "2022-06-01T00:00:00Z", "name": "alice"},
+		map[string]any{"id": 3, "at": "2023-01-01T00:00:00+00:00", "name": "bob"},
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected []any
+	}{
+		{path: "$[?(@.at > '2023-01-01T00:00:00Z' && @.name == 'alice')].id", expected: []any{1}},
+		{path: "$[?(@.at == '2023-01-01T00:00:00Z')].id", expected: []any{}},
+		{path: "$[?(@.at >= '2023-01-01T00:00:00Z')].id", expected: []any{1, 3}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path, WithEquality(foldEqual), CompareTimes())
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestNestedFilterOptions(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"id": 1, "items": []any{map[string]any{"x": "", "code": "200", "tags": []any{"A"}}}},
+		map[string]any{"id": 2, "items": []any{map[string]any{"x": "y", "code": 404, "tags": []any{"b", "c"}}}},
+	}
+	// test cases (filter options apply to nested filters)
+	tcs := []struct {
+		name     string
+		path     string
+		options  []Option
+		expected []any
+	}{
+		{name: "empty string is null", path: "$[?(@.items[?(@.x == null)])].id", options: []Option{EmptyStringIsNull()}, expected: []any{1}},
+		{name: "loose equality", path: "$[?(@.items[?(@.code == 200)])].id", options: []Option{LooseEquality()}, expected: []any{1}},
+		{name: "unwrap singleton arrays", path: "$[?(@.items[?(@.tags == 'A')])].id", options: []Option{UnwrapSingletonArrays()}, expected: []any{1}},
+		{name: "custom equality", path: "$[?(@.items[?(@.x == 'Y')])].id", options: []Option{WithEquality(foldEqual)}, expected: []any{2}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path, tc.options...)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestLooseEquality1(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"id": 1, "code": 200},
+		map[string]any{"id": 2, "code": "200"},
+		map[string]any{"id": 3, "code": "200.0"},
+		map[string]any{"id": 4, "code": "0x10"},
+		map[string]any{"id": 5, "code": "Inf"},
+		map[string]any{"id": 6, "code": true},
+		map[string]any{"id": 7, "code": 16},
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		options  []Option
+		expected []any
+	}{
+		{path: "$[?(@.code == 200)].id", expected: []any{1}},
+		{path: "$[?(@.code == 200)].id", options: []Option{LooseEquality()}, expected: []any{1, 2, 3}},
+		{path: "$[?(@.code == '200')].id", options: []Option{LooseEquality()}, expected: []any{1, 2}},
+		{path: "$[?(@.code != 200)].id", options: []Option{LooseEquality()}, expected: []any{4, 5, 6, 7}},
+		{path: "$[?(@.code == 16)].id", options: []Option{LooseEquality()}, expected: []any{7}},
+		{path: "$[?(@.code == 'true')].id", options: []Option{LooseEquality()}, expected: []any{}},
+		{path: "$[?(@.code in [200, 16])].id", options: []Option{LooseEquality()}, expected: []any{1, 2, 3, 7}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path, tc.options...)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestLooseEquality2(t *testing.T) {
+	// arrange (ordering comparisons stay strict)
+	var data = []any{
+		map[string]any{"id": 1, "code": "500"},
+		map[string]any{"id": 2, "code": 500},
+	}
+	var path = "$[?(@.code >= 400)].id"
+	var expected = []any{2}
+	// act
+	result, err := Get(data, path, LooseEquality())
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestChainedFilters1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"book": []any{
+			map[string]any{"price": 10, "category": "fiction", "author": "a"},
+			map[string]any{"price": 30, "category": "fiction", "author": "b"},
+			map[string]any{"price": 5, "category": "reference", "author": "c"},
+			map[string]any{"price": 15, "category": "fiction", "author": "d"},
+			map[string]any{"price": 12, "category": "fiction"},
+		},
+	}
+	// test cases (chained filters and the equivalent combined filter)
+	tcs := []struct {
+		chained  string
+		combined string
+		expected []any
+	}{
+		{
+			chained:  "$.book[?(@.price < 20)][?(@.category == 'fiction')].price",
+			combined: "$.book[?(@.price < 20 && @.category == 'fiction')].price",
+			expected: []any{10, 15, 12},
+		},
+		{
+			chained:  "$.book[?(@.price < 20)][?(@.category == 'fiction')][?(@.author)].author",
+			combined: "$.book[?(@.price < 20 && @.category == 'fiction' && @.author)].author",
+			expected: []any{"a", "d"},
+		},
+		{
+			chained:  "$..[?(@.price < 20)][?(@.category == 'fiction')][?(@.price > 10)].price",
+			combined: "$..[?(@.price < 20 && @.category == 'fiction' && @.price > 10)].price",
+			expected: []any{15, 12},
+		},
+		{
+			chained:  "$.book[?(@.price > 100)][?(@.category == 'fiction')]",
+			combined: "$.book[?(@.price > 100 && @.category == 'fiction')]",
+			expected: []any{},
+		},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.chained, func(t *testing.T) {
+			// act
+			chained, err := Get(data, tc.chained)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			combined, err := Get(data, tc.combined)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			// assert
+			if diff := cmp.Diff(tc.expected, chained); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+			if diff := cmp.Diff(combined, chained); diff != "" {
+				t.Errorf("Chained and combined filters differ: %v", diff)
+			}
+		})
+	}
+}
+
+func TestChainedFilters2(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"book": []any{
+			map[string]any{"price": 10, "category": "fiction"},
+			map[string]any{"price": 30, "category": "fiction"},
+			map[string]any{"price": 5, "category": "reference"},
+		},
+	}
+	var path = "$.book[?(@.price < 20)][?(@.category == 'fiction')].price"
+	// act
+	n, err := SetN(data, path, 0)
+	if err != nil {
+		t.Errorf("Failed to set value: %v", err)
+	}
+	// assert
+	if n != 1 {
+		t.Errorf("Unexpected count: %d", n)
+	}
+	expected := []any{
+		map[string]any{"price": 0, "category": "fiction"},
+		map[string]any{"price": 30, "category": "fiction"},
+		map[string]any{"price": 5, "category": "reference"},
+	}
+	if diff := cmp.Diff(expected, data["book"]); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestChainedFilters3(t *testing.T) {
+	// arrange (arrays kept by a filter are filtered item by item)
+	var data = []any{[]any{1, 2}, []any{3}, []any{}}
+	var path = "$[?(@[0])][?(@ > 1)]"
+	var expected = []any{2, 3}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestMixedNumberComparisons(t *testing.T) {
+	// arrange (matching values keep their original types)
+	var data = []any{2, 2.0, 2.5, int64(2), float32(2), "2"}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected []any
+	}{
+		{path: "$[?(@ == 2)]", expected: []any{2, 2.0, int64(2), float32(2)}},
+		{path: "$[?(@ == 2.0)]", expected: []any{2, 2.0, int64(2), float32(2)}},
+		{path: "$[?(@ == 2.5)]", expected: []any{2.5}},
+		{path: "$[?(@ != 2)]", expected: []any{2.5, "2"}},
+		{path: "$[?(@ > 2)]", expected: []any{2.5}},
+		{path: "$[?(@ >= 2.0)]", expected: []any{2, 2.0, 2.5, int64(2), float32(2)}},
+		{path: "$[?(@ < 2.5)]", expected: []any{2, 2.0, int64(2), float32(2)}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestUnwrapSingletonArrays(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"id": 1, "x": []any{5}},
+		map[string]any{"id": 2, "x": []any{5, 6}},
+		map[string]any{"id": 3, "x": []any{2}},
+		map[string]any{"id": 4, "x": 5},
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		options  []Option
+		expected []any
+	}{
+		{path: "$[?(@.x > 3)].id", options: []Option{UnwrapSingletonArrays()}, expected: []any{1, 4}},
+		{path: "$[?(@.x == 5)].id", options: []Option{UnwrapSingletonArrays()}, expected: []any{1, 4}},
+		{path: "$[?(@.x in [2, 5])].id", options: []Option{UnwrapSingletonArrays()}, expected: []any{1, 3, 4}},
+		{path: "$[?(@.x is 'array')].id", options: []Option{UnwrapSingletonArrays()}, expected: []any{1, 2, 3}},
+		{path: "$[?(@.x > 3)].id", expected: []any{4}},
+		{path: "$[?(@.x == 5)].id", expected: []any{4}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path, tc.options...)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+func TestEqualityAcrossTypes1(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"id": 1, "v": 8},
+		map[string]any{"id": 2, "v": "8"},
+		map[string]any{"id": 3, "v": 8.5},
+		map[string]any{"id": 4, "v": "8.5"},
+		map[string]any{"id": 5, "v": true},
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		options  []Option
+		expected []any
+	}{
+		{path: "$[?(@.v == 8)].id", expected: []any{1}},
+		{path: "$[?(@.v == '8')].id", expected: []any{2}},
+		{path: "$[?(@.v == 8)].id", options: []Option{EqualityAcrossTypes()}, expected: []any{1, 2}},
+		{path: "$[?(@.v == '8')].id", options: []Option{EqualityAcrossTypes()}, expected: []any{1, 2}},
+		{path: "$[?(@.v == 8.5)].id", options: []Option{EqualityAcrossTypes()}, expected: []any{3, 4}},
+		{path: "$[?(@.v != 8)].id", options: []Option{EqualityAcrossTypes()}, expected: []any{3, 4, 5}},
+		{path: "$[?(@.v == 'true')].id", options: []Option{EqualityAcrossTypes()}, expected: []any{5}},
+		{path: "$[?(@.v in [8, 'x'])].id", options: []Option{EqualityAcrossTypes()}, expected: []any{1, 2}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path, tc.options...)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestEqualityAcrossTypes2(t *testing.T) {
+	// arrange (ordering comparisons and null values are not affected)
+	var data = []any{
+		map[string]any{"id": 1, "v": "9"},
+		map[string]any{"id": 2, "v": nil},
+	}
+	var path = "$[?(@.v > 8 || @.v == 'null')].id"
+	var expected = []any{}
+	// act
+	result, err := Get(data, path, EqualityAcrossTypes())
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestEmptyStringIsNull(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"id": 1, "x": ""},
+		map[string]any{"id": 2, "x": nil},
+		map[string]any{"id": 3, "x": "a"},
+		map[string]any{"id": 4},
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		options  []Option
+		expected []any
+	}{
+		{path: "$[?(@.x == null)].id", expected: []any{2}},
+		{path: "$[?(@.x != '')].id", expected: []any{2, 3}},
+		{path: "$[?(@.x == '')].id", expected: []any{1}},
+		{path: "$[?(@.x == null)].id", options: []Option{EmptyStringIsNull()}, expected: []any{1, 2}},
+		{path: "$[?(@.x != '')].id", options: []Option{EmptyStringIsNull()}, expected: []any{3}},
+		{path: "$[?(@.x == '')].id", options: []Option{EmptyStringIsNull()}, expected: []any{1, 2}},
+		{path: "$[?(@.x != null)].id", options: []Option{EmptyStringIsNull()}, expected: []any{3}},
+		{path: "$[?(@.x in [null])].id", options: []Option{EmptyStringIsNull()}, expected: []any{1, 2}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path, tc.options...)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestPipeDecoders1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"payload": base64.StdEncoding.EncodeToString([]byte(`{"items": [1, 2]}`)),
+	}
+	var path = "$.payload|base64|json.items[*]"
+	var expected = []any{float64(1), float64(2)}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestPipeDecoders2(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"p": `{"a": 1}`},
+		map[string]any{"p": "not json"},
+		map[string]any{"p": 1},
+	}
+	var path = "$[*].p|json.a"
+	var expected = []any{float64(1)}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestPipeDecoders3(t *testing.T) {
+	// arrange
+	RegisterDecoder("upper", func(value any) (any, error) {
+		// value must be a string
+		s, ok := value.(string)
+		if !ok {
+			return nil, errors.New("not a string")
+		}
+		return strings.ToUpper(s), nil
+	})
+	var data = []any{
+		map[string]any{"name": "ann"},
+		map[string]any{"name": "bob"},
+	}
+	var path = "$[?(@.name|upper == 'BOB')].name"
+	var expected = []any{"bob"}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestPipeDecoders4(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": "x"}
+	var path = "$.a|unknown"
+	// act
+	_, err := Get(data, path)
+	// assert
+	if err == nil || err.Error() != `unknown decoder "unknown"` {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestMaxNodeVisits1(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": map[string]any{"b": 1}}
+	var path = "$.a.b"
+	var expected = 1
+	// act
+	result, err := Get(data, path, MaxNodeVisits(10))
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestMaxNodeVisits2(t *testing.T) {
+	// arrange
+	var data = []any{}
+	for i := 0; i < 1000; i++ {
+		data = append(data, map[string]any{"id": i, "tags": []any{"a", "b"}})
+	}
+	var path = "$..[?(@.id >= 0)].tags[*]"
+	// act
+	ctx, p, err := compile(path, []Option{MaxNodeVisits(100)})
+	if err != nil {
+		t.Errorf("Failed to compile path: %v", err)
+	}
+	result := p.Evaluate(data)
+	// assert
+	if err := ctx.checkBudget(); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if visits := ctx.budget.visits.Load(); visits != 101 {
+		t.Errorf("Unexpected node visits: %d", visits)
+	}
+	if len(result) >= 2000 {
+		t.Errorf("Unexpected result length: %d", len(result))
+	}
+}
+
+func TestMaxNodeVisits3(t *testing.T) {
+	// arrange
+	var data = []any{}
+	for i := 0; i < 1000; i++ {
+		data = append(data, map[string]any{"a": i})
+	}
+	var path = "$[*].a"
+	// act
+	err := Set(data, path, 0, MaxNodeVisits(100))
+	// assert
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]any{"a": 1}, data[1]); diff != "" {
+		t.Errorf("Data must not be modified: %v", diff)
+	}
+}
+
+func TestMaxNodeVisits4(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": []any{1, 2, 3}}
+	var path = "$.a[*]"
+	var options = []Option{MaxNodeVisits(10)}
+	var expected = []any{1, 2, 3}
+	// act (each call has its own budget)
+	for i := 0; i < 3; i++ {
+		result, err := Get(data, path, options...)
+		if err != nil {
+			t.Errorf("Failed to get value: %v", err)
+		}
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Errorf("Unexpected result: %v", diff)
+		}
+	}
+}
+
+func TestMaxNodeVisits5(t *testing.T) {
+	// arrange
+	var expected = "MaxNodeVisits option is not supported by compiled paths and lenses, use functions returning errors (e.g. Get)"
+	// act
+	_, errPath := NewPath("$.a[*]", MaxNodeVisits(10))
+	_, errAll := CompileAll([]string{"$.a[*]"}, MaxNodeVisits(10))
+	_, errLens := NewLens("$.a", MaxNodeVisits(10))
+	// assert
+	for _, err := range []error{errPath, errAll, errLens} {
+		if err == nil || err.Error() != expected {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+}
+
+func TestGetTimeout1(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": []any{1, 2, 3}}
+	var path = "$.a[0]"
+	var expected = []any{1}
+	// act
+	result, err := GetTimeout(data, path, time.Minute)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestGetTimeout2(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": []any{1, 2, 3}}
+	var path = "$..*"
+	// act
+	result, err := GetTimeout(data, path, 0)
+	// assert
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if result != nil {
+		t.Errorf("Unexpected result: %v", result)
+	}
+}
+
+func TestGetTimeout3(t *testing.T) {
+	// arrange
+	var data = []any{}
+	for i := 0; i < 1000; i++ {
+		data = append(data, map[string]any{"id": i, "tags": []any{"a", "b"}})
+	}
+	var path = "$..tags[*]"
+	deadline, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// expire deadline while recursing
+	visits := 0
+	recurseInto := RecurseInto(func(any) bool {
+		// count visits
+		visits++
+		if visits == 10 {
+			cancel()
+		}
+		return true
+	})
+	// act
+	ctx, p, err := compile(path, []Option{recurseInto, withDeadline(deadline)})
+	if err != nil {
+		t.Errorf("Failed to compile path: %v", err)
+	}
+	result := p.Evaluate(data)
+	// assert
+	if err := ctx.checkErrors(); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if len(result) >= 2000 {
+		t.Errorf("Unexpected result length: %d", len(result))
+	}
+}
+
+func TestGetTimeout4(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": 1}
+	var path = "$.a["
+	// act
+	_, err := GetTimeout(data, path, time.Minute)
+	// assert
+	if err == nil || errors.Is(err, ErrTimeout) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestGetTimeout5(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": []any{1, 2, 3}}
+	var path = "$..*"
+	var expected = []any{[]any{1, 2, 3}, 1, 2, 3}
+	deadline, cancel := context.WithCancel(context.Background())
+	// act
+	ctx, p, err := compile(path, []Option{withDeadline(deadline)})
+	if err != nil {
+		t.Errorf("Failed to compile path: %v", err)
+	}
+	result := p.Evaluate(data)
+	// expire deadline once the evaluation completed
+	cancel()
+	// assert
+	if err := ctx.checkErrors(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestGetPartial1(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": []any{1, 2, 3}}
+	var path = "$.a[*]"
+	var expected = []any{1, 2, 3}
+	// act
+	result, truncated, err := GetPartial(context.Background(), data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	// assert
+	if truncated {
+		t.Error("Unexpected truncated result")
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestGetPartial2(t *testing.T) {
+	// arrange
+	var data = []any{}
+	for i := 0; i < 1000; i++ {
+		data = append(data, map[string]any{"id": i, "tags": []any{"a", "b"}})
+	}
+	var path = "$..tags[*]"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// cancel after a few results
+	visits := 0
+	recurseInto := RecurseInto(func(any) bool {
+		// count visits
+		visits++
+		if visits == 10 {
+			cancel()
+		}
+		return true
+	})
+	// act
+	result, truncated, err := GetPartial(ctx, data, path, recurseInto)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	// assert
+	if !truncated {
+		t.Error("Expected truncated result")
+	}
+	if len(result) == 0 || len(result) >= 2000 {
+		t.Errorf("Unexpected result length: %d", len(result))
+	}
+	for _, v := range result {
+		if v != "a" && v != "b" {
+			t.Errorf("Unexpected value: %v", v)
+		}
+	}
+}
+
+func TestGetPartial3(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": 1}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// act
+	result, truncated, err := GetPartial(ctx, data, "$.a")
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	// assert
+	if !truncated {
+		t.Error("Expected truncated result")
+	}
+	if diff := cmp.Diff([]any{}, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+	// invalid expressions are errors
+	if _, _, err := GetPartial(ctx, data, "$.a["); err == nil {
+		t.Error("Expected error")
+	}
+}
+
+func TestStrictTypes1(t *testing.T) {
+	// arrange
+	var data = []any{map[string]any{"a": 7}, map[string]any{"a": "x"}}
+	var path = "$[?(@.a == 'x')]"
+	// act
+	_, err := Get(data, path, StrictTypes())
+	// assert
+	if !errors.Is(err, ErrIncompatibleTypes) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err != nil && err.Error() != "incompatible types in filter comparison: number 7 == string 'x'" {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
+func TestStrictTypes2(t *testing.T) {
+	// arrange
+	var data = []any{map[string]any{"a": 7}, map[string]any{"a": "x"}}
+	var path = "$[?(@.a == 'x')]"
+	var expected = []any{map[string]any{"a": "x"}}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestStrictTypes3(t *testing.T) {
+	// arrange
+	var data = []any{map[string]any{"a": 7}, map[string]any{"a": "x"}, map[string]any{"a": nil}}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected []any
+	}{
+		{path: "$[?(@.a != null)].a", expected: []any{7, "x"}},
+		{path: "$[?(@.a in [7, 'x'])].a", expected: []any{7, "x"}},
+		{path: "$[?(@.a is 'number' && @.a > 5)].a", expected: []any{7}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path, StrictTypes())
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestStrictTypes4(t *testing.T) {
+	// arrange
+	var data = []any{map[string]any{"a": 7, "b": 1}, map[string]any{"a": "x", "b": 2}}
+	var path = "$[?(@.a > 5)].b"
+	// act
+	err := Set(data, path, 0, StrictTypes())
+	// assert
+	if !errors.Is(err, ErrIncompatibleTypes) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]any{"a": 7, "b": 1}, data[0]); diff != "" {
+		t.Errorf("Data must not be modified: %v", diff)
+	}
+}
+
+func TestStrictTypes5(t *testing.T) {
+	// arrange
+	var path = "$[?(@.a == 'x')]"
+	var options = []Option{StrictTypes()}
+	// act (each call collects its own errors)
+	_, err1 := Get([]any{map[string]any{"a": 7}}, path, options...)
+	result, err2 := Get([]any{map[string]any{"a": "x"}}, path, options...)
+	// assert
+	if !errors.Is(err1, ErrIncompatibleTypes) {
+		t.Errorf("Unexpected error: %v", err1)
+	}
+	if err2 != nil {
+		t.Errorf("Failed to get value: %v", err2)
+	}
+	if diff := cmp.Diff([]any{map[string]any{"a": "x"}}, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestStrictTypes6(t *testing.T) {
+	// arrange
+	var expected = "StrictTypes option is not supported by compiled paths and lenses, use functions returning errors (e.g. Get)"
+	// act
+	_, errPath := NewPath("$[?(@.a == 'x')]", StrictTypes())
+	_, errAll := CompileAll([]string{"$[?(@.a == 'x')]"}, StrictTypes())
+	_, errLens := NewLens("$.a", StrictTypes())
+	// assert
+	for _, err := range []error{errPath, errAll, errLens} {
+		if err == nil || err.Error() != expected {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+}
+
+func TestGetArray1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"store": map[string]any{
+			"book": []any{map[string]any{"id": 1}, map[string]any{"id": 2}},
+		},
+	}
+	var path = "$.store.book"
+	var expected = []any{map[string]any{"id": 1}, map[string]any{"id": 2}}
+	// act
+	result, err := GetArray(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestGetArray2(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"store": map[string]any{"name": "x"},
+	}
+	var cases = map[string]string{
+		"$.store":       "path $.store matched a map[string]interface {} value, expected an array",
+		"$.store.book":  "path $.store.book did not match any value",
+		"$.store[*]":    "path $.store[*] is not definite",
+		"$.store.name~": "path $.store.name~ matched a string value, expected an array",
+	}
+	for path, expected := range cases {
+		// act
+		_, err := GetArray(data, path)
+		// assert
+		if err == nil || err.Error() != expected {
+			t.Errorf("Unexpected error for %s: %v", path, err)
+		}
+	}
+}
+
+func TestGetObject1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"store": map[string]any{
+			"book": []any{map[string]any{"id": 1}, map[string]any{"id": 2}},
+		},
+	}
+	var path = "$.store.book[1]"
+	var expected = map[string]any{"id": 2}
+	// act
+	result, err := GetObject(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestGetObject2(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"store": map[string]any{
+			"book": []any{1, 2},
+		},
+	}
+	var path = "$.store.book"
+	// act
+	_, err := GetObject(data, path)
+	// assert
+	if err == nil || err.Error() != "path $.store.book matched a []interface {} value, expected an object" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestGetObject3(t *testing.T) {
+	// arrange
+	var ordered = NewOrderedMap()
+	ordered.Set("b", 1)
+	ordered.Set("a", []any{2})
+	var synced sync.Map
+	synced.Store("c", 3)
+	var data = map[string]any{
+		"ordered":   ordered,
+		"interface": map[any]any{"d": 4, 5: "e"},
+		"synced":    FromSyncMap(&synced),
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected map[string]any
+	}{
+		{path: "$.ordered", expected: map[string]any{"a": []any{2}, "b": 1}},
+		{path: "$.interface", expected: map[string]any{"d": 4, "5": "e"}},
+		{path: "$.synced", expected: map[string]any{"c": 3}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := GetObject(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
 func TestSetObjectField1(t *testing.T) {
 	// arrange
-	var data = map[string]any{}
+	var data = map[string]any{}
+	var path = "$.b"
+	var expected = map[string]any{"b": 1}
+	// act
+	err := Set(data, path, 1)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSetObjectField2(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": 1, "b": 2}
+	var path = "$.*"
+	var expected = map[string]any{"a": 3, "b": 3}
+	// act
+	err := Set(data, path, 3)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSetObjectField3(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": 1, "b": 2, "c": 3}
+	var path = `$["a", "c"]`
+	var expected = map[string]any{"a": nil, "b": 2, "c": nil}
+	// act
+	err := Set(data, path, nil)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSetObjectField4(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": 1, "b": 2, "c": 3}
+	var path = `$[*]`
+	var expected = map[string]any{"a": nil, "b": nil, "c": nil}
+	// act
+	err := Set(data, path, nil)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSetObjectField5(t *testing.T) {
+	// arrange
+	var data = []any{map[string]any{"a": 1}}
+	var path = `$[*].*`
+	var expected = []any{map[string]any{"a": nil}}
+	// act
+	err := Set(data, path, nil)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSetArrayField1(t *testing.T) {
+	// arrange
+	var data = []any{2}
+	var path = "$[0]"
+	var expected = []any{1}
+	// act
+	err := Set(data, path, 1)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSetArrayField2(t *testing.T) {
+	// arrange
+	var data = []any{1, 1, 1}
+	var path = "$.*"
+	var expected = []any{3, 3, 3}
+	// act
+	err := Set(data, path, 3)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSetArrayField3(t *testing.T) {
+	// arrange
+	var data = []any{1, 2, 3}
+	var path = `$[0, 2]`
+	var expected = []any{nil, 2, nil}
+	// act
+	err := Set(data, path, nil)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSetArrayField4(t *testing.T) {
+	// arrange
+	var data = []any{1, 2, 3}
+	var path = `$[*]`
+	var expected = []any{nil, nil, nil}
+	// act
+	err := Set(data, path, nil)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSetLiteralWildcardKey1(t *testing.T) {
+	// arrange
+	var data = map[string]any{"*": 1, "a": 2}
+	var path = `$['*']`
+	var expected = map[string]any{"*": 3, "a": 2}
+	// act
+	err := Set(data, path, 3)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSetLiteralWildcardKey2(t *testing.T) {
+	// arrange
+	var data = map[string]any{"*": 1, "a": 2}
+	var path = `$.\*`
+	var expected = map[string]any{"*": 3, "a": 2}
+	// act
+	err := Set(data, path, 3)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSetFirst1(t *testing.T) {
+	// arrange
+	var data = []any{1, 2, 3}
+	var path = "$[*]"
+	var expected = []any{0, 2, 3}
+	// act
+	set, err := SetFirst(data, path, 0)
+	if err != nil {
+		t.Errorf("Failed to set value: %v", err)
+	}
+	if !set {
+		t.Error("Expected value to be set")
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSetFirst2(t *testing.T) {
+	// arrange
+	var data = []any{map[string]any{"a": 1}, map[string]any{"a": 2}}
+	var path = "$.*.a"
+	var expected = []any{map[string]any{"a": 0}, map[string]any{"a": 2}}
+	// act
+	set, err := SetFirst(data, path, 0)
+	if err != nil {
+		t.Errorf("Failed to set value: %v", err)
+	}
+	if !set {
+		t.Error("Expected value to be set")
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSetFirst3(t *testing.T) {
+	// arrange
+	var data = []any{}
+	var path = "$[*]"
+	var expected = []any{}
+	// act
+	set, err := SetFirst(data, path, 0)
+	if err != nil {
+		t.Errorf("Failed to set value: %v", err)
+	}
+	if set {
+		t.Error("Expected no value to be set")
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSetFirst4(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"store": map[string]any{
+			"book": []any{
+				map[string]any{"title": "a", "isbn": "1"},
+				map[string]any{"title": "b"},
+				map[string]any{"title": "c"},
+			},
+		},
+	}
+	var path = "$.store.book[?(!@.isbn)].isbn"
+	var expected = map[string]any{
+		"store": map[string]any{
+			"book": []any{
+				map[string]any{"title": "a", "isbn": "1"},
+				map[string]any{"title": "b", "isbn": "2"},
+				map[string]any{"title": "c"},
+			},
+		},
+	}
+	// act
+	set, err := SetFirst(data, path, "2")
+	if err != nil {
+		t.Errorf("Failed to set value: %v", err)
+	}
+	if !set {
+		t.Error("Expected value to be set")
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSetFirst5(t *testing.T) {
+	// arrange
+	var data = map[string]any{"book": []any{map[string]any{"title": "a", "isbn": "1"}}}
+	var path = "$.book[?(!@.isbn)].isbn"
+	var expected = map[string]any{"book": []any{map[string]any{"title": "a", "isbn": "1"}}}
+	// act
+	set, err := SetFirst(data, path, "2")
+	if err != nil {
+		t.Errorf("Failed to set value: %v", err)
+	}
+	if set {
+		t.Error("Expected no value to be set")
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSetN1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"items": []any{map[string]any{"a": 1}, map[string]any{"a": 2}, map[string]any{"b": 3}},
+	}
+	var path = "$.items[*].a"
+	var expected = map[string]any{
+		"items": []any{map[string]any{"a": 0}, map[string]any{"a": 0}, map[string]any{"a": 0, "b": 3}},
+	}
+	// act
+	count, err := SetN(data, path, 0)
+	if err != nil {
+		t.Errorf("Failed to set value: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Unexpected count: %d", count)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSetN2(t *testing.T) {
+	// arrange
+	var data = map[string]any{"items": []any{1, 2}}
+	var path = "$.items[?(@ > 5)].a"
+	var expected = map[string]any{"items": []any{1, 2}}
+	// act
+	count, err := SetN(data, path, 0)
+	if err != nil {
+		t.Errorf("Failed to set value: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Unexpected count: %d", count)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSetN3(t *testing.T) {
+	// act
+	count, err := SetN(map[string]any{}, "$[?(", 0)
+	// assert
+	if err == nil {
+		t.Error("Expected error")
+	}
+	if count != 0 {
+		t.Errorf("Unexpected count: %d", count)
+	}
+}
+
+func compareVersions(a, b TypedValue) (int, bool) {
+	// only strings are versions
+	if a.Type != StringValue || b.Type != StringValue {
+		return 0, false
+	}
+	// version parts
+	ap := strings.Split(a.Value, ".")
+	bp := strings.Split(b.Value, ".")
+	// compare parts
+	for i := 0; i < len(ap) && i < len(bp); i++ {
+		an, err := strconv.Atoi(ap[i])
+		if err != nil {
+			return 0, false
+		}
+		bn, err := strconv.Atoi(bp[i])
+		if err != nil {
+			return 0, false
+		}
+		if an != bn {
+			return an - bn, true
+		}
+	}
+	return len(ap) - len(bp), true
+}
+
+func TestCompareFunc1(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"version": "1.9.0"},
+		map[string]any{"version": "1.10.0"},
+		map[string]any{"version": "1.11.2"},
+	}
+	var path = "$[?(@.version > '1.9.5')].version"
+	var expected = []any{"1.10.0", "1.11.2"}
+	// act
+	result, err := Get(data, path, WithCompareFunc(compareVersions))
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestCompareFunc2(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"version": "1.9.0", "size": 1},
+		map[string]any{"version": "1.10.0", "size": 2},
+	}
+	var path = "$[?(@.size >= 2)].version"
+	var expected = []any{"1.10.0"}
+	// act
+	result, err := Get(data, path, WithCompareFunc(compareVersions))
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestCompareFunc3(t *testing.T) {
+	// arrange
+	var data = []any{map[string]any{"version": "1.9.0"}}
+	var path = "$[?(@.version > '1.9.5')]"
+	// act
+	_, err := Get(data, path)
+	if err == nil {
+		t.Error("Expected error comparing strings without a compare function")
+	}
+}
+
+func TestRecurseInto(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"name": "root",
+		"public": map[string]any{
+			"name": "a",
+			"items": []any{
+				map[string]any{"name": "b"},
+			},
+		},
+		"private": map[string]any{
+			"internal": true,
+			"name":     "c",
+			"nested":   map[string]any{"name": "d"},
+		},
+	}
+	// skip the members of internal objects (the objects themselves are still visited)
+	into := func(value any) bool {
+		m, ok := value.(map[string]any)
+		return !ok || m["internal"] != true
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected []any
+	}{
+		{path: "$..name", expected: []any{"root", "a", "b", "c"}},
+		{path: "$..[?(@.name)].name", expected: []any{"c", "a", "b", "d"}},
+		{path: "$.private..name", expected: []any{"c"}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path, RecurseInto(into))
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestSkipNullElements1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"a": []any{nil, map[string]any{"b": 1}, nil, map[string]any{"b": nil}, 2, nil},
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected []any
+	}{
+		{path: "$.a[*]", expected: []any{map[string]any{"b": 1}, map[string]any{"b": nil}, 2}},
+		{path: "$.a.*", expected: []any{map[string]any{"b": 1}, map[string]any{"b": nil}, 2}},
+		{path: "$.a[*].b", expected: []any{1, nil}},
+		{path: "$.a[*][?(@ == null)]", expected: []any{}},
+		{path: "$.a[0:3]", expected: []any{nil, map[string]any{"b": 1}, nil}},
+		{path: "$.a[0]", expected: []any{nil}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path, SkipNullElements(), AlwaysReturnList())
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestSkipNullElements2(t *testing.T) {
+	// arrange
+	var data = []any{1, nil, []any{nil, 2}}
+	var path = "$..*"
+	var expected = []any{1, []any{nil, 2}, 2}
+	// act
+	result, err := Get(data, path, SkipNullElements())
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestSkipNullElements3(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": []any{nil, 1, nil, 2}}
+	var path = "$.a[*]"
+	var expected = map[string]any{"a": []any{nil, 0, nil, 0}}
+	// act
+	n, err := SetN(data, path, 0, SkipNullElements())
+	if err != nil {
+		t.Errorf("Failed to set value: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Unexpected count: %d", n)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestRecursiveDepthRange1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"name": "l0",
+		"a": map[string]any{
+			"name": "l1",
+			"b": map[string]any{
+				"name": "l2",
+				"c": map[string]any{
+					"name": "l3",
+				},
+			},
+		},
+	}
+	var path = "$..name"
+	var expected = []any{"l1", "l2"}
+	// act
+	result, err := Get(data, path, RecursiveDepthRange(1, 2))
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestRecursiveDepthRange2(t *testing.T) {
+	// arrange
+	var data = []any{
+		[]any{1, []any{2, []any{3}}},
+	}
+	var path = "$..*"
+	var expected = []any{[]any{1, []any{2, []any{3}}}}
+	// act
+	result, err := Get(data, path, RecursiveDepthRange(0, 0))
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestRecursiveFilter1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"price": 1,
+		"a": map[string]any{
+			"price": 2,
+			"b": []any{
+				map[string]any{"price": 3},
+				4,
+			},
+		},
+	}
+	var path = "$..[?(@.price)]"
+	var expected = []any{
+		map[string]any{
+			"price": 2,
+			"b": []any{
+				map[string]any{"price": 3},
+				4,
+			},
+		},
+		map[string]any{"price": 3},
+	}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestRecursiveFilter2(t *testing.T) {
+	// arrange
+	var data = []any{
+		[]any{1, 5},
+		7,
+	}
+	var path = "$..[?(@ != 1)]"
+	var expected = []any{[]any{1, 5}, 7, 5}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestRecursiveFilterLeavesOnly(t *testing.T) {
+	// arrange
+	var data = []any{
+		[]any{1, 5},
+		7,
+	}
+	var path = "$..[?(@ != 1)]"
+	var expected = []any{7, 5}
+	// act
+	result, err := Get(data, path, RecursiveFilterLeavesOnly())
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestWildcardArraysWithDot1(t *testing.T) {
+	// arrange
+	var data = []any{1, 2, 3}
+	var path = "$.*"
+	var expected = []any{1, 2, 3}
+	// act
+	result, err := Get(data, path, WildcardArraysWithDot(true))
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestWildcardArraysWithDot2(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"a": []any{1, 2},
+		"b": map[string]any{"c": 3},
+	}
+	var path = "$.*.*"
+	var expected = []any{3}
+	// act
+	result, err := Get(data, path, WildcardArraysWithDot(false))
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestWildcardArraysWithDot3(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"a": []any{1, 2},
+	}
+	var path = "$.a[*]"
+	var expected = []any{1, 2}
+	// act
+	result, err := Get(data, path, WildcardArraysWithDot(false))
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestFilterParent1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"teams": []any{
+			map[string]any{
+				"active": true,
+				"lead":   map[string]any{"name": "ann"},
+			},
+			map[string]any{
+				"active": false,
+				"lead":   map[string]any{"name": "bob"},
+			},
+		},
+	}
+	var path = "$..[?(@.name && @^.active == true)]"
+	var expected = []any{map[string]any{"name": "ann"}}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestFilterParent2(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"values": []any{3, 1, 5, 4},
+	}
+	var path = "$.values[?(@ > @^[0])]"
+	var expected = []any{5, 4}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestFilterParent3(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"a": map[string]any{"enabled": true, "value": 1},
+	}
+	var path = "$.a[?(@^)]"
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff([]any{}, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestFilterGrandparent1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"groups": []any{
+			map[string]any{
+				"defaultType": "a",
+				"items":       []any{map[string]any{"id": 1, "type": "a"}, map[string]any{"id": 2, "type": "b"}},
+			},
+			map[string]any{
+				"defaultType": "b",
+				"items":       []any{map[string]any{"id": 3, "type": "a"}, map[string]any{"id": 4, "type": "b"}},
+			},
+		},
+	}
+	var path = "$.groups[*].items[?(@.type == @^^.defaultType)].id"
+	var expected = []any{1, 4}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestFilterGrandparent2(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"a": map[string]any{"limit": 2, "values": []any{1, 2, 3}},
+		"b": map[string]any{"limit": 1, "values": []any{1, 2}},
+	}
+	var path = "$..[?(@ > @^^.limit)]"
+	var expected = []any{2, 3}
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestFilterGrandparent3(t *testing.T) {
+	// arrange
+	var data = []any{1, 2, 3}
+	var path = "$[?(@^^)]"
+	// act
+	result, err := Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff([]any{}, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestFilterGrandparent4(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"groups": []any{
+			map[string]any{"max": 2, "items": []any{map[string]any{"n": 1}, map[string]any{"n": 3}}},
+			map[string]any{"max": 0, "items": []any{map[string]any{"n": 1}}},
+		},
+	}
+	var path = "$.groups[*].items[?(@.n > @^^.max)].n"
+	var expected = map[string]any{
+		"groups": []any{
+			map[string]any{"max": 2, "items": []any{map[string]any{"n": 1}, map[string]any{"n": 0}}},
+			map[string]any{"max": 0, "items": []any{map[string]any{"n": 0}}},
+		},
+	}
+	// act
+	err := Set(data, path, 0)
+	if err != nil {
+		t.Errorf("Failed to set value: %v", err)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestFilterGrandparent5(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"groups": []any{
+			map[string]any{"max": 2, "items": []any{1, 2, 3}},
+		},
+	}
+	var path = "$.groups[*].items[?(@ > @^^.max)]"
+	var expected = []string{"$['groups'][0]['items'][2]"}
+	// act
+	result, err := NormalizedPaths(data, path)
+	if err != nil {
+		t.Errorf("Failed to get paths: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestPlanSet1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"users": []any{
+			map[string]any{"name": "ann", "role": "admin"},
+			map[string]any{"name": "bob"},
+		},
+	}
+	var path = "$.users[*].role"
+	var expected = []string{"$['users'][0]['role']", "$['users'][1]['role']"}
+	// act
+	result, err := PlanSet(data, path)
+	if err != nil {
+		t.Errorf("Failed to plan set: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+	// data must not be modified
+	if diff := cmp.Diff(map[string]any{"name": "bob"}, data["users"].([]any)[1]); diff != "" {
+		t.Errorf("Unexpected data: %v", diff)
+	}
+}
+
+func TestPlanSet2(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"a": []any{1, 2, 3},
+		"b": map[string]any{"c": 4},
+	}
+	var path = "$..[1:]"
+	var expected = []string{"$['a'][1]", "$['a'][2]"}
+	// act
+	result, err := PlanSet(data, path)
+	if err != nil {
+		t.Errorf("Failed to plan set: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestPlanSet3(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": 1}
 	var path = "$.b"
-	var expected = map[string]any{"b": 1}
+	var expected = []string{"$['b']"}
 	// act
-	err := Set(data, path, 1)
+	result, err := PlanSet(data, path)
+	if err != nil {
+		t.Errorf("Failed to plan set: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+	// data must not be modified
+	if diff := cmp.Diff(map[string]any{"a": 1}, data); diff != "" {
+		t.Errorf("Unexpected data: %v", diff)
+	}
+}
+
+func TestGetWithParents1(t *testing.T) {
+	// arrange
+	var books = []any{
+		map[string]any{"title": "a"},
+		map[string]any{"title": "b", "isbn": "1"},
+		map[string]any{"title": "c", "isbn": "2"},
+	}
+	var data = map[string]any{
+		"store": map[string]any{"book": books},
+	}
+	var path = "$..book[?(@.isbn)]"
+	var expected = []ParentMatch{
+		{Value: books[1], Parent: books, Index: 1},
+		{Value: books[2], Parent: books, Index: 2},
+	}
+	// act
+	result, err := GetWithParents(data, path)
 	if err != nil {
 		t.Errorf("Failed to get value: %v", err)
 	}
-	if diff := cmp.Diff(expected, data); diff != "" {
+	if diff := cmp.Diff(expected, result); diff != "" {
 		t.Errorf("Unexpected result: %v", diff)
 	}
 }
 
-func TestSetObjectField2(t *testing.T) {
+func TestGetWithParents2(t *testing.T) {
 	// arrange
-	var data = map[string]any{"a": 1, "b": 2}
+	var book = map[string]any{"title": "a", "isbn": "1"}
+	var data = []any{book}
+	var path = "$[0].isbn"
+	var expected = []ParentMatch{
+		{Value: "1", Parent: book, Index: -1, Key: "isbn"},
+	}
+	// act
+	result, err := GetWithParents(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestGetWithParents3(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": 1}
+	var path = "$"
+	var expected = []ParentMatch{
+		{Value: data, Index: -1},
+	}
+	// act
+	result, err := GetWithParents(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestGetWithParents4(t *testing.T) {
+	// arrange (decoded values and counts are not part of the input data)
+	var data = map[string]any{"a": "eyJiIjogMX0=", "c": []any{1, 2}}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected string
+	}{
+		{path: "$.a|base64|json.b", expected: "path $.a|base64|json.b matches decoded values, which have no location in the input data"},
+		{path: "$.c[*].count()", expected: "path $.c[*].count() matches a count, which has no location in the input data"},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			_, err := GetWithParents(data, tc.path)
+			if err == nil {
+				t.Fatalf("Expected error")
+			}
+			if err.Error() != tc.expected {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNormalizedPaths1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"a": map[string]any{
+			"b": []any{1, 2, 3},
+		},
+	}
+	var path = "$.a.b[1:]"
+	var expected = []string{"$['a']['b'][1]", "$['a']['b'][2]"}
+	// act
+	result, err := NormalizedPaths(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestNormalizedPaths2(t *testing.T) {
+	// arrange
+	var data = []any{1, 2, 3, 4, 5}
+	var path = "$[-3]"
+	var expected = []string{"$[2]"}
+	// act
+	result, err := NormalizedPaths(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestNormalizedPaths3(t *testing.T) {
+	// arrange
+	var data = map[string]any{"it's": 1, "\u000B": 2, "☺": 3, `a\b`: 4}
 	var path = "$.*"
-	var expected = map[string]any{"a": 3, "b": 3}
+	var expected = []string{`$['\u000b']`, `$['a\\b']`, `$['it\'s']`, `$['☺']`}
 	// act
-	err := Set(data, path, 3)
+	result, err := NormalizedPaths(data, path)
 	if err != nil {
 		t.Errorf("Failed to get value: %v", err)
 	}
-	if diff := cmp.Diff(expected, data); diff != "" {
+	if diff := cmp.Diff(expected, result); diff != "" {
 		t.Errorf("Unexpected result: %v", diff)
 	}
 }
 
-func TestSetObjectField3(t *testing.T) {
+func TestNormalizedPaths4(t *testing.T) {
 	// arrange
-	var data = map[string]any{"a": 1, "b": 2, "c": 3}
-	var path = `$["a", "c"]`
-	var expected = map[string]any{"a": nil, "b": 2, "c": nil}
+	var data = map[string]any{
+		"o": map[string]any{"j": 1, "k": 2},
+		"a": []any{5, 3, []any{map[string]any{"j": 4}}},
+	}
+	var path = "$..j"
+	var expected = []string{"$['o']['j']", "$['a'][2][0]['j']"}
 	// act
-	err := Set(data, path, nil)
+	result, err := NormalizedPaths(data, path)
 	if err != nil {
 		t.Errorf("Failed to get value: %v", err)
 	}
-	if diff := cmp.Diff(expected, data); diff != "" {
+	if diff := cmp.Diff(expected, result); diff != "" {
 		t.Errorf("Unexpected result: %v", diff)
 	}
 }
 
-func TestSetObjectField4(t *testing.T) {
+func TestNormalizedPaths5(t *testing.T) {
 	// arrange
-	var data = map[string]any{"a": 1, "b": 2, "c": 3}
-	var path = `$[*]`
-	var expected = map[string]any{"a": nil, "b": nil, "c": nil}
+	var data = map[string]any{
+		"a": []any{
+			map[string]any{"b": 1},
+			map[string]any{"b": 2},
+			map[string]any{"b": 3},
+		},
+	}
+	var path = "$.a[?(@.b >= 2)]"
+	var expected = []string{"$['a'][1]", "$['a'][2]"}
 	// act
-	err := Set(data, path, nil)
+	result, err := NormalizedPaths(data, path)
 	if err != nil {
 		t.Errorf("Failed to get value: %v", err)
 	}
-	if diff := cmp.Diff(expected, data); diff != "" {
+	if diff := cmp.Diff(expected, result); diff != "" {
 		t.Errorf("Unexpected result: %v", diff)
 	}
 }
 
-func TestSetObjectField5(t *testing.T) {
+func TestNormalizedPaths6(t *testing.T) {
 	// arrange
-	var data = []any{map[string]any{"a": 1}}
-	var path = `$[*].*`
-	var expected = []any{map[string]any{"a": nil}}
+	var data = map[string]any{"a": 1}
+	var path = "$"
+	var expected = []string{"$"}
 	// act
-	err := Set(data, path, nil)
+	result, err := NormalizedPaths(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestNormalizedPaths7(t *testing.T) {
+	// arrange (decoded values and counts are not part of the input data)
+	var data = map[string]any{"a": "eyJiIjogMX0=", "c": []any{1, 2}}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected string
+	}{
+		{path: "$.a|base64|json.b", expected: "path $.a|base64|json.b matches decoded values, which have no location in the input data"},
+		{path: "$.c[*].count()", expected: "path $.c[*].count() matches a count, which has no location in the input data"},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			_, err := NormalizedPaths(data, tc.path)
+			if err == nil {
+				t.Fatalf("Expected error")
+			}
+			if err.Error() != tc.expected {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNormalizedPaths8(t *testing.T) {
+	// arrange (decoders inside filters do not change the matching values)
+	var data = map[string]any{"a": []any{map[string]any{"p": `{"b": 1}`}, map[string]any{"p": `{"b": 2}`}}}
+	var path = "$.a[?(@.p|json.b == 2)]"
+	var expected = []string{"$['a'][1]"}
+	// act
+	result, err := NormalizedPaths(data, path)
 	if err != nil {
 		t.Errorf("Failed to get value: %v", err)
 	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestUpdateWhere1(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": "x", "b": 1, "c": "y", "d": true}
+	var path = "$.*"
+	var expected = map[string]any{"a": "X", "b": 1, "c": "Y", "d": true}
+	// act
+	err := UpdateWhere(data, path, func(v any) bool {
+		_, ok := v.(string)
+		return ok
+	}, func(v any) any {
+		return strings.ToUpper(v.(string))
+	})
+	if err != nil {
+		t.Errorf("Failed to update value: %v", err)
+	}
 	if diff := cmp.Diff(expected, data); diff != "" {
 		t.Errorf("Unexpected result: %v", diff)
 	}
 }
 
-func TestSetArrayField1(t *testing.T) {
+func TestUpdateWhere2(t *testing.T) {
 	// arrange
-	var data = []any{2}
-	var path = "$[0]"
-	var expected = []any{1}
+	var data = []any{map[string]any{"a": 1}, map[string]any{"a": "2"}, map[string]any{"b": 3}}
+	var path = "$[*].a"
+	var expected = []any{map[string]any{"a": 1}, map[string]any{"a": "2!"}, map[string]any{"b": 3}}
 	// act
-	err := Set(data, path, 1)
+	err := UpdateWhere(data, path, func(v any) bool {
+		_, ok := v.(string)
+		return ok
+	}, func(v any) any {
+		return v.(string) + "!"
+	})
 	if err != nil {
-		t.Errorf("Failed to get value: %v", err)
+		t.Errorf("Failed to update value: %v", err)
 	}
 	if diff := cmp.Diff(expected, data); diff != "" {
 		t.Errorf("Unexpected result: %v", diff)
 	}
 }
 
-func TestSetArrayField2(t *testing.T) {
+func TestUpdateWhere3(t *testing.T) {
 	// arrange
-	var data = []any{1, 1, 1}
-	var path = "$.*"
-	var expected = []any{3, 3, 3}
+	var data = map[string]any{"a": "x"}
+	var path = "$['a']~"
+	var expected = map[string]any{"a": "x"}
 	// act
-	err := Set(data, path, 3)
+	err := UpdateWhere(data, path, func(v any) bool {
+		return true
+	}, func(v any) any {
+		return "changed"
+	})
 	if err != nil {
-		t.Errorf("Failed to get value: %v", err)
+		t.Errorf("Failed to update value: %v", err)
 	}
 	if diff := cmp.Diff(expected, data); diff != "" {
 		t.Errorf("Unexpected result: %v", diff)
 	}
 }
 
-func TestSetArrayField3(t *testing.T) {
+func BenchmarkGetWideArray(b *testing.B) {
+	// 10k books
+	books := make([]any, 0, 10000)
+	for i := 0; i < 10000; i++ {
+		books = append(books, map[string]any{"author": strconv.Itoa(i), "price": i})
+	}
+	data := map[string]any{
+		"store": map[string]any{"book": books},
+	}
+	// reset timer
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Get(data, "$.store.book[*].author"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestPrune1(t *testing.T) {
 	// arrange
-	var data = []any{1, 2, 3}
-	var path = `$[0, 2]`
-	var expected = []any{nil, 2, nil}
+	var data = map[string]any{
+		"user": map[string]any{"name": "a", "password": "x", "ssn": "1"},
+		"sessions": []any{
+			map[string]any{"id": 1, "token": "t1"},
+			map[string]any{"id": 2, "token": "t2"},
+		},
+	}
+	var expressions = []string{"$..password", "$..token", "$..ssn"}
+	var expected = map[string]any{
+		"user": map[string]any{"name": "a"},
+		"sessions": []any{
+			map[string]any{"id": 1},
+			map[string]any{"id": 2},
+		},
+	}
 	// act
-	err := Set(data, path, nil)
+	count, err := Prune(data, expressions)
 	if err != nil {
-		t.Errorf("Failed to get value: %v", err)
+		t.Errorf("Failed to prune values: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("Unexpected count: %d", count)
 	}
 	if diff := cmp.Diff(expected, data); diff != "" {
 		t.Errorf("Unexpected result: %v", diff)
 	}
 }
 
-func TestSetArrayField4(t *testing.T) {
+func TestPrune2(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"items": []any{
+			map[string]any{"secret": true, "tags": []any{"a", "b", "c"}},
+			map[string]any{"secret": false, "tags": []any{"d", "e"}},
+			map[string]any{"secret": true},
+		},
+	}
+	var expressions = []string{"$.items[*].tags[0,2]", "$.items[?(@.secret == true)]"}
+	var expected = map[string]any{
+		"items": []any{
+			map[string]any{"secret": false, "tags": []any{"e"}},
+		},
+	}
+	// act
+	count, err := Prune(data, expressions)
+	if err != nil {
+		t.Errorf("Failed to prune values: %v", err)
+	}
+	if count != 5 {
+		t.Errorf("Unexpected count: %d", count)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestPrune3(t *testing.T) {
 	// arrange
 	var data = []any{1, 2, 3}
-	var path = `$[*]`
-	var expected = []any{nil, nil, nil}
+	var expressions = []string{"$[0]"}
 	// act
-	err := Set(data, path, nil)
+	_, err := Prune(data, expressions)
+	if err == nil {
+		t.Error("Expected error removing root array items")
+	}
+}
+
+func TestPrune4(t *testing.T) {
+	// arrange (nothing matches)
+	var data = map[string]any{"a": 1}
+	var expected = map[string]any{"a": 1}
+	// act
+	count, err := Prune(data, []string{"$.b", "$..c"})
 	if err != nil {
-		t.Errorf("Failed to get value: %v", err)
+		t.Errorf("Failed to prune values: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Unexpected count: %d", count)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestPrune5(t *testing.T) {
+	// test cases (overlapping recursive descents match the same values more than once)
+	tcs := []struct {
+		name       string
+		data       map[string]any
+		expression string
+		count      int
+		expected   map[string]any
+	}{
+		{
+			name:       "array item",
+			data:       map[string]any{"a": map[string]any{"arr": []any{1, 2, 3}}},
+			expression: "$..*..[0]",
+			count:      1,
+			expected:   map[string]any{"a": map[string]any{"arr": []any{2, 3}}},
+		},
+		{
+			name:       "object member",
+			data:       map[string]any{"a": map[string]any{"b": map[string]any{"p": 1}, "p": 2}},
+			expression: "$..*..p",
+			count:      2,
+			expected:   map[string]any{"a": map[string]any{"b": map[string]any{}}},
+		},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// act
+			count, err := Prune(tc.data, []string{tc.expression})
+			if err != nil {
+				t.Errorf("Failed to prune values: %v", err)
+			}
+			if count != tc.count {
+				t.Errorf("Unexpected count: %d", count)
+			}
+			if diff := cmp.Diff(tc.expected, tc.data); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestRedact1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"user": map[string]any{"name": "a", "password": "x", "billing": map[string]any{"creditCard": "4111"}},
+		"cards": []any{
+			map[string]any{"creditCard": "5500", "type": "mc"},
+			map[string]any{"type": "visa"},
+		},
+	}
+	var expressions = []string{"$..password", "$..creditCard"}
+	var expected = map[string]any{
+		"user": map[string]any{"name": "a", "password": "***", "billing": map[string]any{"creditCard": "***"}},
+		"cards": []any{
+			map[string]any{"creditCard": "***", "type": "mc"},
+			map[string]any{"type": "visa"},
+		},
+	}
+	// act
+	count, err := Redact(data, expressions, "***")
+	if err != nil {
+		t.Errorf("Failed to redact values: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Unexpected count: %d", count)
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestRedact2(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"tokens": []any{"t1", "t2", "t3"},
+		"nested": []any{[]any{"a", "b"}},
+	}
+	var expressions = []string{"$.tokens[0,2]", "$.nested[0][*]", "$.missing"}
+	var expected = map[string]any{
+		"tokens": []any{nil, "t2", nil},
+		"nested": []any{[]any{nil, nil}},
+	}
+	// act
+	count, err := Redact(data, expressions, nil)
+	if err != nil {
+		t.Errorf("Failed to redact values: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("Unexpected count: %d", count)
 	}
 	if diff := cmp.Diff(expected, data); diff != "" {
 		t.Errorf("Unexpected result: %v", diff)
 	}
 }
+
+func TestNegativeIndexUnion(t *testing.T) {
+	// test cases
+	tcs := []struct {
+		path     string
+		data     []any
+		expected any
+	}{
+		{path: "$[-1,-2]", data: []any{1, 2, 3}, expected: []any{3, 2}},
+		{path: "$[-1, 0]", data: []any{1, 2, 3}, expected: []any{3, 1}},
+		{path: "$[-1,-2]", data: []any{1}, expected: []any{1}},
+		{path: "$[-2,-1]", data: []any{1, 2}, expected: []any{1, 2}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(tc.data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestTypePredicate(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"values": []any{1, "two", 3.5, nil, true, []any{4}, map[string]any{"five": 5}},
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected any
+	}{
+		{path: "$.values[?(@ is 'number')]", expected: []any{1, 3.5}},
+		{path: "$.values[?(@ is \"string\")]", expected: []any{"two"}},
+		{path: "$.values[?(@ is 'null')]", expected: []any{nil}},
+		{path: "$.values[?(@ is 'boolean')]", expected: []any{true}},
+		{path: "$.values[?(@ is 'array')]", expected: []any{[]any{4}}},
+		{path: "$.values[?(@ is 'object')]", expected: []any{map[string]any{"five": 5}}},
+		{path: "$.values[?(!(@ is 'number') && @ is 'string')]", expected: []any{"two"}},
+		{path: "$.values[*][?(@ is 'number')]", expected: []any{1, 3.5, 4}},
+		{path: "$[?(@.values[0] is 'number')].values[1]", expected: []any{"two"}},
+		{path: "$.values[?(@ in ['two', 1])]", expected: []any{1, "two"}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestGetNumbers(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"values": []any{1, "two", 3.5, nil, int64(4), uint8(5), []any{6}},
+	}
+	var expected = []float64{1, 3.5, 4, 5}
+	// act
+	result, err := GetNumbers(data, "$.values[*]")
+	if err != nil {
+		t.Errorf("Failed to get values: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestGetStrings(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"values": []any{1, "two", 3.5, nil, "four", []any{"six"}},
+	}
+	var expected = []string{"two", "four"}
+	// act
+	result, err := GetStrings(data, "$.values[*]")
+	if err != nil {
+		t.Errorf("Failed to get values: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestDistinctKeys(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"store": map[string]any{
+			"book": []any{
+				map[string]any{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+				map[string]any{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+				map[string]any{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+				map[string]any{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99},
+			},
+			"bicycle": map[string]any{"color": "red", "price": 19.95},
+		},
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected []string
+	}{
+		{path: "$.store.book[*]", expected: []string{"author", "category", "isbn", "price", "title"}},
+		{path: "$.store.book[:2]", expected: []string{"author", "category", "price", "title"}},
+		{path: "$.store.*", expected: []string{"color", "price"}},
+		{path: "$.store.book[*].price", expected: []string{}},
+		{path: "$.missing", expected: []string{}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := DistinctKeys(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get keys: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestDynamicRegularExpression(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"pattern": "^x",
+		"items": []any{
+			map[string]any{"id": 1, "name": "apple", "pattern": "^a"},
+			map[string]any{"id": 2, "name": "banana", "pattern": "^a"},
+			map[string]any{"id": 3, "name": "cherry", "pattern": "rr"},
+			map[string]any{"id": 4, "name": "date", "pattern": "("},
+			map[string]any{"id": 5, "name": "elder", "pattern": 1},
+			map[string]any{"id": 6, "name": "xigua"},
+		},
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected []any
+	}{
+		{path: "$.items[?(@.name =~ @.pattern)].id", expected: []any{1, 3}},
+		{path: "$.items[?(!(@.name =~ @.pattern))].id", expected: []any{2, 4, 5, 6}},
+		{path: "$.items[?(@.name =~ $.pattern)].id", expected: []any{6}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestConcurrentGet(t *testing.T) {
+	// arrange (a shared document queried by many goroutines, run with -race)
+	var data = map[string]any{
+		"store": map[string]any{
+			"book": []any{
+				map[string]any{"title": "a", "price": 8.95, "tags": []any{"x", nil, "y"}, "pattern": "^a"},
+				map[string]any{"title": "b", "price": 12.99, "isbn": "1", "pattern": "^c"},
+				map[string]any{"title": "c", "price": 22.99, "isbn": "2", "pattern": "^c"},
+			},
+			"bicycle": map[string]any{"color": "red", "price": 19.95},
+		},
+	}
+	var expressions = []string{
+		"$..price",
+		"$.store.book[?(@.price > 10)].title",
+		"$..book[?(@.isbn)]",
+		"$.store.book[*].tags[*]",
+		"$.store.book[?(@.title =~ @.pattern)].title",
+		"$.store.book[?(@.price > @^[0].price && @^^.bicycle)].title",
+		"$..*~",
+		"$.store.book[-1:]",
+	}
+	// shared paths
+	var paths []*Path
+	for _, expression := range []string{"$..book[?(@.price < 20)].title", "$..[?(@.isbn)].title", "$.store..[?(@ == 'x')]", "$.store.book..*", "$.store.book..*~"} {
+		path, err := NewPath(expression, SkipNullElements())
+		if err != nil {
+			t.Fatalf("invalid path: %s", err)
+		}
+		paths = append(paths, path)
+	}
+	// expected results
+	expected := make([]any, len(expressions))
+	for i, expression := range expressions {
+		var err error
+		expected[i], err = Get(data, expression)
+		if err != nil {
+			t.Fatalf("Failed to get value: %v", err)
+		}
+	}
+	expectedPaths := make([][]any, len(paths))
+	for i, path := range paths {
+		expectedPaths[i] = path.Evaluate(data)
+	}
+	// act
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 20; n++ {
+				// loop expressions
+				for i, expression := range expressions {
+					result, err := Get(data, expression)
+					if err != nil {
+						t.Errorf("Failed to get value: %v", err)
+						return
+					}
+					if diff := cmp.Diff(expected[i], result); diff != "" {
+						t.Errorf("Unexpected result for %s: %v", expression, diff)
+						return
+					}
+				}
+				// evaluate shared paths
+				for i, path := range paths {
+					if diff := cmp.Diff(expectedPaths[i], path.Evaluate(data)); diff != "" {
+						t.Errorf("Unexpected result: %v", diff)
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestLogicalOperatorsInLiterals(t *testing.T) {
+	// arrange
+	var data = []any{
+		map[string]any{"id": 1, "x": "a&&b", "y": "a"},
+		map[string]any{"id": 2, "x": "c||d", "y": "b"},
+		map[string]any{"id": 3, "x": "a", "y": "||"},
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		expected []any
+	}{
+		{path: "$[?(@.x == 'a&&b')].id", expected: []any{1}},
+		{path: `$[?(@.x == "c||d" || @.y == 'a')].id`, expected: []any{1, 2}},
+		{path: "$[?(@.y == '||' && @.x == 'a')].id", expected: []any{3}},
+		{path: `$[?(@.x =~ /\|\|/)].id`, expected: []any{2}},
+		{path: "$[?(@.x =~ /a&&b/ && @.y == 'a')].id", expected: []any{1}},
+		{path: "$[?(@.x in ['a&&b', 'c||d'])].id", expected: []any{1, 2}},
+		{path: "$[?(normalize(@.x) == 'a&&b')].id", expected: []any{1}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkKnownKeys checks the child names selected by the lexer token are known, if known keys were provided
+func (ctx *pathContext) checkKnownKeys(token lexeme) error {
+	// check known keys
+	if ctx.knownKeys == nil {
+		return nil
+	}
+	// child names (unescaped, wildcards are always valid)
+	var names []string
+	// process token type
+	switch token.typ {
+
+	case lexemeDotChild, lexemePropertyName:
+		// .name or .name~
+		names = unescapedChildNames(strings.TrimSuffix(strings.TrimPrefix(token.val, dot), propertyName))
+
+	case lexemeUndottedChild:
+		// name
+		names = unescapedChildNames(token.val)
+
+	case lexemeRecursiveDescent:
+		// ..name or ..name~
+		name, _ := trimPropertyName(strings.TrimPrefix(token.val, recursiveDescent))
+		names = unescapedChildNames(name)
+
+	case lexemeBracketChild, lexemeBracketPropertyName, lexemeBracketUnion:
+		// ['a', 'b'], ['a', 'b']~ or ['a', 0]
+		members := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(token.val), propertyName))
+		members = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(members, leftBracket), rightBracket))
+		// loop members
+		for _, member := range bracketMembers(members) {
+			// check member is a quoted name (array indexes are skipped)
+			if strings.HasPrefix(member, "'") || strings.HasPrefix(member, `"`) {
+				// append unquoted name (quoted names are never wildcards)
+				names = append(names, unquoteChildName(member))
+			}
+		}
+	}
+	// loop names
+	for _, name := range names {
+		// check name
+		if !ctx.knownKeys[name] {
+			return fmt.Errorf("unknown property name %q", name)
+		}
+	}
+	return nil
+}
+
+// unescapedChildNames returns the unescaped child name of a dot child or recursive descent, none for wildcards (an
+// escaped \* is the * member name)
+func unescapedChildNames(raw string) []string {
+	// check wildcard
+	if raw == "" || raw == "*" {
+		return nil
+	}
+	return []string{unescape(raw)}
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"fmt"
+)
+
+// Lens focuses on the single value selected by a definite JsonPath expression, it can be used to get, set and modify
+// that value on any number of documents.
+type Lens struct {
+	path *Path
+}
+
+// NewLens constructs a Lens from a definite JsonPath expression, an error is returned if the expression is not
+// definite (e.g. it contains wildcards, unions, slices, filters or recursive descent).
+func NewLens(expression string, options ...Option) (*Lens, error) {
+	// create context and Path
+	ctx, path, err := compile(expression, options)
+	if err != nil {
+		return nil, err
+	}
+	// check options
+	if err := ctx.checkReusable(); err != nil {
+		return nil, err
+	}
+	// check expression selects a value (count() computes one)
+	if ctx.countsResult {
+		return nil, fmt.Errorf("lens expression %q counts values, lenses cannot use count()", expression)
+	}
+	// check expression is definite
+	if !ctx.definite {
+		return nil, fmt.Errorf("lens expression %q is not definite", expression)
+	}
+	return &Lens{
+		path: path,
+	}, nil
+}
+
+// Get returns the value the lens focuses on in the given data, the second return value is false if there is no
+// such value.
+func (l *Lens) Get(data any) (any, bool) {
+	// evaluate path
+	it := l.path.expression(getOperation, data, data)
+	// first value
+	return it()
+}
+
+// Set sets the value the lens focuses on in the given data. It returns false if the value cannot be set, e.g. the
+// parent of the value does not exist.
+func (l *Lens) Set(data any, value any) bool {
+	// evaluate path
+	it := l.path.expression(setOperation, data, data)
+	// loop iterator
+	for r, ok := it(); ok; r, ok = it() {
+		// current iterator value must be setExpression
+		if f, ok := r.(setExpression); ok {
+			// set value
+			f(value)
+			// single target
+			return true
+		}
+	}
+	return false
+}
+
+// Modify replaces the value the lens focuses on in the given data with the result of calling fn on it (fn receives
+// nil if there is no such value). It returns false if the value cannot be set.
+func (l *Lens) Modify(data any, fn func(any) any) bool {
+	// current value
+	value, _ := l.Get(data)
+	// set new value
+	return l.Set(data, fn(value))
+}
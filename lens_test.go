@@ -0,0 +1,130 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLensGet1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"a": map[string]any{
+			"b": []any{1, 2},
+		},
+	}
+	lens, err := NewLens("$.a.b[1]")
+	if err != nil {
+		t.Fatalf("Failed to create lens: %v", err)
+	}
+	// act
+	value, ok := lens.Get(data)
+	// assert
+	if !ok {
+		t.Errorf("Value not found")
+	}
+	if diff := cmp.Diff(2, value); diff != "" {
+		t.Errorf("Unexpected value: %v", diff)
+	}
+}
+
+func TestLensGet2(t *testing.T) {
+	// arrange
+	var data = map[string]any{"a": 1}
+	lens, err := NewLens("$.b")
+	if err != nil {
+		t.Fatalf("Failed to create lens: %v", err)
+	}
+	// act
+	_, ok := lens.Get(data)
+	// assert
+	if ok {
+		t.Errorf("Unexpected value found")
+	}
+}
+
+func TestLensSet1(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"a": map[string]any{"b": 1},
+	}
+	var expected = map[string]any{
+		"a": map[string]any{"b": 1, "c": 2},
+	}
+	lens, err := NewLens("$.a.c")
+	if err != nil {
+		t.Fatalf("Failed to create lens: %v", err)
+	}
+	// act
+	ok := lens.Set(data, 2)
+	// assert
+	if !ok {
+		t.Errorf("Value not set")
+	}
+	if diff := cmp.Diff(expected, data); diff != "" {
+		t.Errorf("Unexpected data: %v", diff)
+	}
+}
+
+func TestLensSet2(t *testing.T) {
+	// arrange
+	var data = map[string]any{}
+	lens, err := NewLens("$.a.b")
+	if err != nil {
+		t.Fatalf("Failed to create lens: %v", err)
+	}
+	// act
+	ok := lens.Set(data, 1)
+	// assert
+	if ok {
+		t.Errorf("Unexpected value set")
+	}
+	if diff := cmp.Diff(map[string]any{}, data); diff != "" {
+		t.Errorf("Unexpected data: %v", diff)
+	}
+}
+
+func TestLensModify(t *testing.T) {
+	// arrange
+	var documents = []any{
+		map[string]any{"count": 1},
+		map[string]any{"count": 5},
+	}
+	var expected = []any{
+		map[string]any{"count": 2},
+		map[string]any{"count": 6},
+	}
+	lens, err := NewLens("$.count")
+	if err != nil {
+		t.Fatalf("Failed to create lens: %v", err)
+	}
+	// act
+	for _, document := range documents {
+		lens.Modify(document, func(v any) any {
+			return v.(int) + 1
+		})
+	}
+	// assert
+	if diff := cmp.Diff(expected, documents); diff != "" {
+		t.Errorf("Unexpected data: %v", diff)
+	}
+}
+
+func TestNewLensNotDefinite(t *testing.T) {
+	// arrange
+	var expressions = []string{"$.*", "$[*]", "$..a", "$['a','b']", "$[0,1]", "$[0:2]", "$[?(@.a)]", "$.a.count()", "$..a.count()"}
+	// act
+	for _, expression := range expressions {
+		_, err := NewLens(expression)
+		// assert
+		if err == nil {
+			t.Errorf("Expected error for expression %s", expression)
+		}
+	}
+}
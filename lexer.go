@@ -16,7 +16,9 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"unicode"
+	"unicode/utf16"
 	"unicode/utf8"
 )
 
@@ -58,6 +60,30 @@ const (
 	lexemeBracketPropertyName
 	lexemeArraySubscriptPropertyName
 	lexemeRecursiveFilterBegin
+	lexemeFilterAll
+	lexemeFilterAny
+	lexemeFilterParent
+	lexemeBracketUnion
+	lexemeFilterTime
+	lexemeFilterMin
+	lexemeFilterMax
+	lexemePipeDecoder
+	lexemeFilterNormalize
+	lexemeFilterIn
+	lexemeFilterSetBegin
+	lexemeFilterSetEnd
+	lexemeFilterIs
+	lexemeFilterGrandparent
+	lexemeFilterPropertyName
+	lexemeFilterVersion
+	lexemeFilterKeys
+	lexemeFilterAvg
+	lexemeFilterIndex
+	lexemeFilterModulo
+	lexemeCount
+	lexemeFilterLength
+	lexemeFilterByteLength
+	lexemeFilterText
 	lexemeEOF // lexing complete
 )
 
@@ -86,12 +112,16 @@ func (t lexemeType) comparator() comparator {
 	}
 }
 
+func (t lexemeType) isEquality() bool {
+	return t == lexemeFilterEquality || t == lexemeFilterInequality
+}
+
 func (t lexemeType) isComparisonOrMatch() bool {
 	switch t {
 	case lexemeFilterEquality, lexemeFilterInequality,
 		lexemeFilterGreaterThan, lexemeFilterGreaterThanOrEqual,
 		lexemeFilterLessThan, lexemeFilterLessThanOrEqual,
-		lexemeFilterMatchesRegularExpression:
+		lexemeFilterMatchesRegularExpression, lexemeFilterIn, lexemeFilterIs:
 		return true
 	}
 	return false
@@ -106,27 +136,37 @@ type lexeme struct {
 func (l lexeme) literalValue() typedValue {
 	switch l.typ {
 	case lexemeFilterIntegerLiteral:
+		// integer value (validated by the lexer)
+		i, _ := strconv.Atoi(l.val)
 		return typedValue{
 			typ: intValueType,
 			val: l.val,
+			raw: i,
 		}
 
 	case lexemeFilterFloatLiteral:
+		// float value (validated by the lexer)
+		f, _ := strconv.ParseFloat(l.val, 64)
 		return typedValue{
 			typ: floatValueType,
 			val: l.val,
+			raw: f,
 		}
 
 	case lexemeFilterStringLiteral:
+		// unescaped string
+		s := stringLiteralUnescaper.Replace(l.val[1 : len(l.val)-1])
 		return typedValue{
 			typ: stringValueType,
-			val: l.val[1 : len(l.val)-1],
+			val: s,
+			raw: s,
 		}
 
 	case lexemeFilterBooleanLiteral:
 		return typedValue{
 			typ: booleanValueType,
 			val: l.val,
+			raw: l.val == "true",
 		}
 
 	case lexemeFilterNullLiteral:
@@ -149,6 +189,10 @@ func (l lexeme) literalValue() typedValue {
 	}
 }
 
+// stringLiteralUnescaper processes the escaped quotes and backslashes in filter string literals, other backslashes
+// are kept as they are
+var stringLiteralUnescaper = strings.NewReplacer(`\'`, `'`, `\"`, `"`, `\\`, `\`)
+
 func sanitiseRegularExpressionLiteral(re string) string {
 	return strings.ReplaceAll(re[1:len(re)-1], `\/`, `/`)
 }
@@ -172,12 +216,13 @@ type lexer struct {
 	items                 chan lexeme // channel of scanned lexemes
 	lastEmittedStart      int         // start position of last scanned lexeme
 	lastEmittedLexemeType lexemeType  // type of last emitted lexeme (or lexemEOF if no lexeme has been emitted)
+	orderedStrings        bool        // allow strings to be compared using ordering operators
 }
 
-// lex creates a new scanner for the input string.
+// lex creates a new scanner for the input string, a leading byte order mark and surrounding whitespace are ignored.
 func lex(input string) *lexer {
 	l := &lexer{
-		input:                 input,
+		input:                 trimPath(input),
 		state:                 lexPath,
 		stack:                 make([]stateFn, 0),
 		items:                 make(chan lexeme, 2),
@@ -186,6 +231,34 @@ func lex(input string) *lexer {
 	return l
 }
 
+// lexers holds the lexers reused by bulk compilations
+var lexers = sync.Pool{
+	New: func() any {
+		return lex("")
+	},
+}
+
+// reset prepares the lexer to scan the input string, the stack and the channel of scanned lexemes are reused
+func (l *lexer) reset(input string) {
+	// drain scanned lexemes
+	for len(l.items) > 0 {
+		<-l.items
+	}
+	*l = lexer{
+		input:                 trimPath(input),
+		state:                 lexPath,
+		stack:                 l.stack[:0],
+		items:                 l.items,
+		lastEmittedLexemeType: lexemeEOF,
+	}
+}
+
+// trimPath removes a leading UTF-8 byte order mark and surrounding ASCII whitespace from the path, as found in paths
+// copied from editors and files
+func trimPath(input string) string {
+	return strings.Trim(strings.TrimPrefix(input, byteOrderMark), asciiWhitespace)
+}
+
 // push pushes a state function on the stack which will be resumed when parsing terminates.
 func (l *lexer) push(state stateFn) {
 	l.stack = append(l.stack, state)
@@ -442,7 +515,28 @@ const (
 	filterOpenBracket                       string = "("
 	filterCloseBracket                      string = ")"
 	filterNot                               string = "!"
+	filterAll                               string = "all("
+	filterAny                               string = "any("
+	filterTime                              string = "time("
+	filterMin                               string = "min("
+	filterMax                               string = "max("
+	filterAvg                               string = "avg("
+	filterNormalize                         string = "normalize("
+	filterVersion                           string = "version("
+	filterLength                            string = "length("
+	filterByteLength                        string = "bytelength("
+	filterText                              string = "text("
+	filterIn                                string = "in"
+	filterSetBegin                          string = "["
+	filterSetEnd                            string = "]"
+	filterSetSeparator                      string = ","
+	filterIs                                string = "is"
 	filterAt                                string = "@"
+	filterKeys                              string = "@~"
+	filterIndex                             string = "@@index"
+	filterModulo                            string = "%"
+	filterParent                            string = "@^"
+	filterGrandparent                       string = "@^^"
 	filterConjunction                       string = "&&"
 	filterDisjunction                       string = "||"
 	filterEquality                          string = "=="
@@ -454,6 +548,11 @@ const (
 	filterRegularExpressionEscape           string = `\`
 	recursiveDescent                        string = ".."
 	propertyName                            string = "~"
+	pipe                                    string = "|"
+	unicodeEscape                           string = `\u`
+	countSelector                           string = ".count()"
+	byteOrderMark                           string = "\ufeff"
+	asciiWhitespace                         string = " \t\n\v\f\r"
 )
 
 var orderingOperators []orderingOperator
@@ -489,6 +588,63 @@ func lexRoot(l *lexer) stateFn {
 	return lexSubPath
 }
 
+// consumedUnicodeEscape consumes a \uXXXX escape sequence encoding a valid code point and returns true if and only if
+// such a sequence was consumed. A surrogate must be part of a pair, i.e. a high surrogate (\uD800-\uDBFF) followed by
+// a low surrogate (\uDC00-\uDFFF), as in JSON.
+func consumedUnicodeEscape(l *lexer) bool {
+	// code unit
+	r, ok := consumedCodeUnit(l)
+	if !ok {
+		return false
+	}
+	// check low surrogate
+	if utf16.IsSurrogate(r) && r >= 0xDC00 {
+		l.errorf(`lone low surrogate \u%04X in unicode escape sequence`, r)
+		return false
+	}
+	// check high surrogate
+	if utf16.IsSurrogate(r) {
+		// low surrogate must follow
+		if !l.peeked(unicodeEscape) {
+			l.errorf(`lone high surrogate \u%04X in unicode escape sequence`, r)
+			return false
+		}
+		low, ok := consumedCodeUnit(l)
+		if !ok {
+			return false
+		}
+		if utf16.DecodeRune(r, low) == unicode.ReplacementChar {
+			l.errorf(`high surrogate \u%04X followed by \u%04X instead of a low surrogate in unicode escape sequence`, r, low)
+			return false
+		}
+	}
+	return true
+}
+
+// consumedCodeUnit consumes a \u followed by four hexadecimal digits and returns the UTF-16 code unit they encode
+func consumedCodeUnit(l *lexer) (rune, bool) {
+	// consume \u
+	l.consume(unicodeEscape)
+	// hexadecimal digits
+	start := l.pos
+	for i := 0; i < 4 && isHexDigit(l.peek()); i++ {
+		l.next()
+	}
+	// check digits
+	if l.pos-start != 4 {
+		l.errorf(`incomplete unicode escape sequence, \u must be followed by four hexadecimal digits`)
+		return 0, false
+	}
+	// code unit
+	u, _ := strconv.ParseUint(l.input[start:l.pos], 16, 16)
+	return rune(u), true
+}
+
+// isHexDigit checks whether the rune is a hexadecimal digit
+func isHexDigit(r rune) bool {
+	return r >= '0' && r <= '9' || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F'
+}
+
 // consumedEscapedString consumes a string with the given string validly escaped using "\" and returns
 // true if and only if such a string was consumed.
 func consumedEscapedString(l *lexer, quote string) bool {
@@ -498,6 +654,10 @@ func consumedEscapedString(l *lexer, quote string) bool {
 			return true
 		case l.consumed(`\` + quote):
 		case l.consumed(`\\`):
+		case l.peeked(unicodeEscape):
+			if !consumedUnicodeEscape(l) {
+				return false
+			}
 		case l.peeked(`\`):
 			l.errorf("unsupported escape sequence inside %s%s", quote, quote)
 			return false
@@ -523,7 +683,27 @@ func lexSubPath(l *lexer) stateFn {
 		l.emit(lexemeEOF)
 		return nil
 
+	case l.lastEmittedLexemeType == lexemeFilterEnd && l.consumed(propertyName):
+		// property names of the filtered values
+		if l.peek() != eof {
+			return l.errorf("property name operator may only be used on last child in path")
+		}
+		l.emit(lexemeFilterPropertyName)
+		return lexSubPath
+
+	case l.emptyStack() && l.consumed(countSelector):
+		// number of values selected so far
+		if l.peek() != eof {
+			return l.errorf("count() may only be used at the end of the path")
+		}
+		l.emit(lexemeCount)
+		return lexSubPath
+
 	case l.consumed(recursiveDescent):
+		// count() needs a selector to count
+		if l.emptyStack() && l.peeked(countSelector[1:]) {
+			return l.errorf("count() may not follow recursive descent without a child name, wildcard or array access")
+		}
 		childName := false
 		for {
 			le := l.next()
@@ -564,6 +744,9 @@ func lexSubPath(l *lexer) stateFn {
 
 		return lexOptionalArrayIndex
 
+	case l.peeked(leftBracket) && mixedUnion(l.input[l.pos+1:]):
+		return lexBracketUnion
+
 	case l.peekedWhitespaced("[", "'") || l.peekedWhitespaced("[", `"`): // bracketQuote or bracketDoubleQuote
 		l.consumedWhitespaced("[")
 		for {
@@ -578,6 +761,9 @@ func lexSubPath(l *lexer) stateFn {
 			}
 			if l.consumedWhitespaced(",") {
 				if !l.peekedWhitespaced("'") && !l.peekedWhitespaced(`"`) {
+					if _, closed := closingBracket(l.input[l.pos:]); !closed {
+						return l.errorf("unmatched %s", leftBracket)
+					}
 					return l.errorf(`missing %s or %s`, enquote("'"), enquote(`"`))
 				}
 			} else {
@@ -611,6 +797,9 @@ func lexSubPath(l *lexer) stateFn {
 	case l.peeked(leftBracket):
 		return lexOptionalArrayIndex
 
+	case l.peeked(pipe) && !l.peeked(filterDisjunction):
+		return lexPipeDecoder
+
 	case l.lastEmittedLexemeType == lexemeEOF:
 		childName := false
 		for {
@@ -641,18 +830,18 @@ func lexSubPath(l *lexer) stateFn {
 }
 
 func lexOptionalArrayIndex(l *lexer) stateFn {
+	if l.peeked(leftBracket) && mixedUnion(l.input[l.pos+1:]) {
+		return lexBracketUnion
+	}
 	if l.consumed(leftBracket, bracketQuote, bracketDoubleQuote, filterBegin) {
-		subscript := false
-		for {
-			if l.consumed(rightBracket) {
-				break
-			}
-			if l.next() == eof {
-				return l.errorf("unmatched %s", leftBracket)
-			}
-			subscript = true
+		// find matching bracket
+		n, closed := closingBracket(l.input[l.pos:])
+		l.pos += n
+		if !closed {
+			return l.errorf("unmatched %s", leftBracket)
 		}
-		if !subscript {
+		l.consume(rightBracket)
+		if n == 0 {
 			return l.rawErrorf("subscript missing from %s%s before position %d", leftBracket, rightBracket, l.pos)
 		}
 		if !validateArrayIndex(l) {
@@ -674,6 +863,10 @@ func lexOptionalArrayIndex(l *lexer) stateFn {
 		l.emit(lexemeArraySubscript)
 	}
 
+	if l.peeked(pipe) && !l.peeked(filterDisjunction) {
+		return lexPipeDecoder
+	}
+
 	le := l.peek()
 	if le == ' ' || le == '&' || le == '|' || le == '=' || le == '!' || le == '>' || le == '<' {
 		if l.emptyStack() {
@@ -685,6 +878,161 @@ func lexOptionalArrayIndex(l *lexer) stateFn {
 	return lexSubPath
 }
 
+// lexPipeDecoder lexes a decoder applied to the values selected so far, e.g. |base64
+func lexPipeDecoder(l *lexer) stateFn {
+	l.consume(pipe)
+	name := false
+	for {
+		le := l.next()
+		if !(le == '_' || le == '-' || unicode.IsLetter(le) || unicode.IsDigit(le)) {
+			l.backup()
+			break
+		}
+		name = true
+	}
+	if !name {
+		return l.errorf("decoder name missing")
+	}
+	l.emit(lexemePipeDecoder)
+
+	return lexOptionalArrayIndex
+}
+
+// mixedUnion checks whether the input following a left bracket is a union of quoted names and integer indexes,
+// e.g. 'a', 0]
+func mixedUnion(input string) bool {
+	// member kinds
+	names, indexes := false, false
+	// current position
+	i := 0
+	// skip whitespace
+	skipWhitespace := func() {
+		i = len(input) - len(strings.TrimLeftFunc(input[i:], unicode.IsSpace))
+	}
+	// loop members
+	for {
+		// skip whitespace
+		skipWhitespace()
+		// check input
+		if i == len(input) {
+			return false
+		}
+		// process member
+		switch q := input[i]; {
+
+		case q == '\'' || q == '"':
+			// skip quoted name
+			for i++; i < len(input) && input[i] != q; i++ {
+				// skip escaped character
+				if input[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(input) {
+				return false
+			}
+			i++
+			names = true
+
+		default:
+			// optional sign
+			if input[i] == '-' {
+				i++
+			}
+			// digits
+			start := i
+			for i < len(input) && input[i] >= '0' && input[i] <= '9' {
+				i++
+			}
+			if i == start {
+				return false
+			}
+			indexes = true
+		}
+		// skip whitespace
+		skipWhitespace()
+		// check separator or end of union
+		switch {
+
+		case i < len(input) && input[i] == ',':
+			i++
+
+		case i < len(input) && input[i] == ']':
+			return names && indexes
+
+		default:
+			return false
+		}
+	}
+}
+
+// closingBracket returns the length of the input following a left bracket up to its matching right bracket, nested
+// brackets and parentheses and quoted strings are skipped. The bracket is unmatched when the input ends or an enclosing
+// parenthesis is closed first (e.g. 0)] in [?(@.b[0)]), the length up to that point is returned.
+func closingBracket(input string) (int, bool) {
+	// nesting depth
+	depth := 0
+	// loop input
+	for i := 0; i < len(input); i++ {
+		switch c := input[i]; c {
+
+		case '\'', '"':
+			// skip quoted string
+			for i++; i < len(input) && input[i] != c; i++ {
+				// skip escaped character
+				if input[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(input) {
+				return len(input), false
+			}
+
+		case '[', '(':
+			depth++
+
+		case ']':
+			if depth == 0 {
+				return i, true
+			}
+			depth--
+
+		case ')':
+			if depth == 0 {
+				return i, false
+			}
+			depth--
+		}
+	}
+	return len(input), false
+}
+
+// lexBracketUnion lexes a union of quoted names and integer indexes, the input must satisfy mixedUnion
+func lexBracketUnion(l *lexer) stateFn {
+	l.consume(leftBracket)
+	for {
+		l.consumeWhitespace()
+		if q := l.peek(); q == '\'' || q == '"' {
+			quote := string(l.next())
+			if !consumedEscapedString(l, quote) {
+				return nil
+			}
+			l.consume(quote)
+		} else {
+			l.consumed("-")
+			for n := l.peek(); n >= '0' && n <= '9'; n = l.peek() {
+				l.next()
+			}
+		}
+		if !l.consumedWhitespaced(",") {
+			break
+		}
+	}
+	l.consumedWhitespaced("]")
+	l.emit(lexemeBracketUnion)
+	return lexOptionalArrayIndex
+}
+
 func enquote(quote string) string {
 	switch quote {
 	case "'":
@@ -701,6 +1049,10 @@ func enquote(quote string) string {
 func lexFilterExprInitial(l *lexer) stateFn {
 	l.stripWhitespace()
 
+	if nextState, present := lexBareSubpath(l); present {
+		return nextState
+	}
+
 	if nextState, present := lexNumericLiteral(l, lexFilterExpr); present {
 		return nextState
 	}
@@ -730,9 +1082,91 @@ func lexFilterExprInitial(l *lexer) stateFn {
 		l.emit(lexemeFilterNot)
 		return lexFilterExprInitial
 
+	case l.consumed(filterAll):
+		l.emit(lexemeFilterAll)
+		l.push(lexFilterExpr)
+		return lexFilterExprInitial
+
+	case l.consumed(filterTime):
+		l.emit(lexemeFilterTime)
+		l.push(lexFilterExpr)
+		return lexFilterFunctionArgument
+
+	case l.consumed(filterMin):
+		l.emit(lexemeFilterMin)
+		l.push(lexFilterExpr)
+		return lexFilterFunctionArgument
+
+	case l.consumed(filterMax):
+		l.emit(lexemeFilterMax)
+		l.push(lexFilterExpr)
+		return lexFilterFunctionArgument
+
+	case l.consumed(filterAvg):
+		l.emit(lexemeFilterAvg)
+		l.push(lexFilterExpr)
+		return lexFilterFunctionArgument
+
+	case l.consumed(filterNormalize):
+		l.emit(lexemeFilterNormalize)
+		l.push(lexFilterExpr)
+		return lexFilterFunctionArgument
+
+	case l.consumed(filterVersion):
+		l.emit(lexemeFilterVersion)
+		l.push(lexFilterExpr)
+		return lexFilterFunctionArgument
+
+	case l.consumed(filterLength):
+		l.emit(lexemeFilterLength)
+		l.push(lexFilterExpr)
+		return lexFilterFunctionArgument
+
+	case l.consumed(filterByteLength):
+		l.emit(lexemeFilterByteLength)
+		l.push(lexFilterExpr)
+		return lexFilterFunctionArgument
+
+	case l.consumed(filterText):
+		l.emit(lexemeFilterText)
+		l.push(lexFilterExpr)
+		return lexFilterFunctionArgument
+
+	case l.consumed(filterAny):
+		l.emit(lexemeFilterAny)
+		l.push(lexFilterExpr)
+		return lexFilterExprInitial
+
+	case l.consumed(filterGrandparent):
+		l.emit(lexemeFilterGrandparent)
+		if l.peekedWhitespaced("=") || l.peekedWhitespaced("!") || l.peekedWhitespaced(">") || l.peekedWhitespaced("<") ||
+			l.peekedWhitespaced(filterIn) || l.peekedWhitespaced(filterIs) {
+			return lexFilterExpr
+		}
+		l.push(lexFilterExpr)
+		return lexSubPath
+
+	case l.consumed(filterParent):
+		l.emit(lexemeFilterParent)
+		if l.peekedWhitespaced("=") || l.peekedWhitespaced("!") || l.peekedWhitespaced(">") || l.peekedWhitespaced("<") ||
+			l.peekedWhitespaced(filterIn) || l.peekedWhitespaced(filterIs) {
+			return lexFilterExpr
+		}
+		l.push(lexFilterExpr)
+		return lexSubPath
+
+	case l.consumed(filterKeys):
+		l.emit(lexemeFilterKeys)
+		return lexFilterExpr
+
+	case l.consumed(filterIndex):
+		l.emit(lexemeFilterIndex)
+		return lexFilterIndexModulo(lexFilterExpr)
+
 	case l.consumed(filterAt):
 		l.emit(lexemeFilterAt)
-		if l.peekedWhitespaced("=") || l.peekedWhitespaced("!") || l.peekedWhitespaced(">") || l.peekedWhitespaced("<") {
+		if l.peekedWhitespaced("=") || l.peekedWhitespaced("!") || l.peekedWhitespaced(">") || l.peekedWhitespaced("<") ||
+			l.peekedWhitespaced(filterIn) || l.peekedWhitespaced(filterIs) {
 			return lexFilterExpr
 		}
 		l.push(lexFilterExpr)
@@ -805,7 +1239,24 @@ func lexFilterExpr(l *lexer) stateFn {
 		l.emit(lexemeFilterMatchesRegularExpression)
 
 		l.stripWhitespace()
+		// patterns may be read from the document, e.g. @.name =~ @.pattern
+		if l.hasPrefix(filterAt) || l.hasPrefix(root) {
+			l.push(lexFilterExpr)
+			return lexFilterTerm
+		}
 		return lexRegularExpressionLiteral(l, lexFilterExpr)
+
+	case l.hasPrefix(filterIn):
+		l.consume(filterIn)
+		l.emit(lexemeFilterIn)
+		l.stripWhitespace()
+		return lexSetLiteral
+
+	case l.hasPrefix(filterIs):
+		l.consume(filterIs)
+		l.emit(lexemeFilterIs)
+		l.stripWhitespace()
+		return lexTypeName
 	}
 
 	for _, o := range orderingOperators {
@@ -820,6 +1271,93 @@ func lexFilterExpr(l *lexer) stateFn {
 func lexFilterTerm(l *lexer) stateFn {
 	l.stripWhitespace()
 
+	if l.consumed(filterTime) {
+		l.emit(lexemeFilterTime)
+		return lexFilterFunctionArgument
+	}
+
+	if l.consumed(filterMin) {
+		l.emit(lexemeFilterMin)
+		return lexFilterFunctionArgument
+	}
+
+	if l.consumed(filterMax) {
+		l.emit(lexemeFilterMax)
+		return lexFilterFunctionArgument
+	}
+
+	if l.consumed(filterAvg) {
+		l.emit(lexemeFilterAvg)
+		return lexFilterFunctionArgument
+	}
+
+	if l.consumed(filterNormalize) {
+		l.emit(lexemeFilterNormalize)
+		return lexFilterFunctionArgument
+	}
+
+	if l.consumed(filterVersion) {
+		l.emit(lexemeFilterVersion)
+		return lexFilterFunctionArgument
+	}
+
+	if l.consumed(filterLength) {
+		l.emit(lexemeFilterLength)
+		return lexFilterFunctionArgument
+	}
+
+	if l.consumed(filterByteLength) {
+		l.emit(lexemeFilterByteLength)
+		return lexFilterFunctionArgument
+	}
+
+	if l.consumed(filterText) {
+		l.emit(lexemeFilterText)
+		return lexFilterFunctionArgument
+	}
+
+	if l.consumed(filterGrandparent) {
+		l.emit(lexemeFilterGrandparent)
+
+		if l.peekedWhitespaced("|") || l.peekedWhitespaced("&") || l.peekedWhitespaced(")") {
+			if l.emptyStack() {
+				return l.errorf("invalid character %q", l.peek())
+			}
+			return l.pop()
+		}
+		return lexSubPath
+	}
+
+	if l.consumed(filterParent) {
+		l.emit(lexemeFilterParent)
+
+		if l.peekedWhitespaced("|") || l.peekedWhitespaced("&") || l.peekedWhitespaced(")") {
+			if l.emptyStack() {
+				return l.errorf("invalid character %q", l.peek())
+			}
+			return l.pop()
+		}
+		return lexSubPath
+	}
+
+	if l.consumed(filterKeys) {
+		l.emit(lexemeFilterKeys)
+
+		if l.emptyStack() {
+			return l.errorf("invalid character %q", l.peek())
+		}
+		return l.pop()
+	}
+
+	if l.consumed(filterIndex) {
+		l.emit(lexemeFilterIndex)
+
+		if l.emptyStack() {
+			return l.errorf("invalid character %q", l.peek())
+		}
+		return lexFilterIndexModulo(l.pop())
+	}
+
 	if l.consumed(filterAt) {
 		l.emit(lexemeFilterAt)
 
@@ -837,6 +1375,10 @@ func lexFilterTerm(l *lexer) stateFn {
 		return lexSubPath
 	}
 
+	if nextState, present := lexBareSubpath(l); present {
+		return nextState
+	}
+
 	if nextState, present := lexNumericLiteral(l, lexFilterExpr); present {
 		return nextState
 	}
@@ -856,6 +1398,149 @@ func lexFilterTerm(l *lexer) stateFn {
 	return l.errorf("invalid filter term")
 }
 
+// lexFilterFunctionArgument lexes the argument of a filter function, e.g. time(@.ts) or max($.items[*].price), which
+// is either a subpath or a literal
+func lexFilterFunctionArgument(l *lexer) stateFn {
+	l.stripWhitespace()
+
+	if l.consumed(filterAt) {
+		l.emit(lexemeFilterAt)
+		l.push(lexFilterFunctionEnd)
+		return lexSubPath
+	}
+
+	if l.consumed(root) {
+		l.emit(lexemeRoot)
+		l.push(lexFilterFunctionEnd)
+		return lexSubPath
+	}
+
+	if nextState, present := lexNumericLiteral(l, lexFilterFunctionEnd); present {
+		return nextState
+	}
+
+	if nextState, present := lexStringLiteral(l, lexFilterFunctionEnd); present {
+		return nextState
+	}
+
+	return l.errorf("invalid function argument")
+}
+
+// lexFilterIndexModulo returns the state lexing the optional modulo following the @@index term, e.g. @@index % 10,
+// before resuming nextState
+func lexFilterIndexModulo(nextState stateFn) stateFn {
+	return func(l *lexer) stateFn {
+		// check modulo
+		if !l.peekedWhitespaced(filterModulo) {
+			return nextState
+		}
+		l.stripWhitespace()
+		l.consume(filterModulo)
+		l.emit(lexemeFilterModulo)
+		return lexFilterIndexDivisor(nextState)
+	}
+}
+
+// lexFilterIndexDivisor returns the state lexing the divisor of the @@index modulo, which must be a positive integer,
+// before resuming nextState
+func lexFilterIndexDivisor(nextState stateFn) stateFn {
+	return func(l *lexer) stateFn {
+		l.stripWhitespace()
+		// divisor
+		for n := l.peek(); n >= '0' && n <= '9'; n = l.peek() {
+			l.next()
+		}
+		if d, err := strconv.Atoi(l.value()); err != nil || d <= 0 {
+			return l.errorf("modulo divisor must be a positive integer")
+		}
+		l.emit(lexemeFilterIntegerLiteral)
+		return nextState
+	}
+}
+
+// filterTypeNames are the type names accepted by the is operator
+var filterTypeNames = []string{"number", "string", "boolean", "null", "array", "object"}
+
+// lexTypeName lexes the quoted type name following the is operator, e.g. 'number'
+func lexTypeName(l *lexer) stateFn {
+	for _, name := range filterTypeNames {
+		if l.consumed(filterStringLiteralDelimiter+name+filterStringLiteralDelimiter) ||
+			l.consumed(filterStringLiteralAlternateDelimiter+name+filterStringLiteralAlternateDelimiter) {
+			l.emit(lexemeFilterStringLiteral)
+			return lexFilterExpr
+		}
+	}
+	return l.errorf("invalid type name after %s, expected one of '%s'", filterIs, strings.Join(filterTypeNames, "', '"))
+}
+
+// lexSetLiteral lexes the opening bracket of a set literal, e.g. [200, 204, 'OK']
+func lexSetLiteral(l *lexer) stateFn {
+	if !l.consumed(filterSetBegin) {
+		return l.errorf("missing %s after %s", filterSetBegin, filterIn)
+	}
+	l.emit(lexemeFilterSetBegin)
+	l.stripWhitespace()
+
+	if l.consumed(filterSetEnd) {
+		l.emit(lexemeFilterSetEnd)
+		return lexFilterExpr
+	}
+
+	return lexSetLiteralMember
+}
+
+// lexSetLiteralMember lexes a member of a set literal, which must be a literal other than a regular expression
+func lexSetLiteralMember(l *lexer) stateFn {
+	l.stripWhitespace()
+
+	if nextState, present := lexNumericLiteral(l, lexSetLiteralSeparator); present {
+		return nextState
+	}
+
+	if nextState, present := lexStringLiteral(l, lexSetLiteralSeparator); present {
+		return nextState
+	}
+
+	if nextState, present := lexBooleanLiteral(l, lexSetLiteralSeparator); present {
+		return nextState
+	}
+
+	if nextState, present := lexNullLiteral(l, lexSetLiteralSeparator); present {
+		return nextState
+	}
+
+	return l.errorf("invalid set literal member")
+}
+
+// lexSetLiteralSeparator lexes the separator between set literal members or the closing bracket of a set literal
+func lexSetLiteralSeparator(l *lexer) stateFn {
+	l.stripWhitespace()
+
+	if l.consumed(filterSetSeparator) {
+		l.stripWhitespace()
+		return lexSetLiteralMember
+	}
+
+	if l.consumed(filterSetEnd) {
+		l.emit(lexemeFilterSetEnd)
+		return lexFilterExpr
+	}
+
+	return l.errorf("missing %s or %s in set literal", filterSetEnd, filterSetSeparator)
+}
+
+// lexFilterFunctionEnd lexes the closing bracket of a filter function
+func lexFilterFunctionEnd(l *lexer) stateFn {
+	l.stripWhitespace()
+
+	if l.consumed(filterCloseBracket) {
+		l.emit(lexemeFilterCloseBracket)
+		return l.pop()
+	}
+
+	return l.errorf("missing %s after function argument", filterCloseBracket)
+}
+
 func lexFilterEnd(l *lexer) stateFn {
 	if l.hasPrefix(filterEnd) {
 		if l.lastEmittedLexemeType == lexemeFilterBegin {
@@ -866,6 +1551,10 @@ func lexFilterEnd(l *lexer) stateFn {
 		return lexSubPath
 	}
 
+	if l.empty() {
+		return l.errorf("missing end of filter")
+	}
+
 	return l.errorf("invalid filter syntax")
 }
 
@@ -879,6 +1568,15 @@ func validateArrayIndex(l *lexer) bool {
 	return true
 }
 
+// lexBareSubpath reports an error if a filter subpath is not prefixed by @ or $, e.g. .child
+func lexBareSubpath(l *lexer) (stateFn, bool) {
+	// a dot followed by a digit is a floating point number
+	if l.hasPrefix(dot) && !(len(l.input) > l.pos+1 && l.input[l.pos+1] >= '0' && l.input[l.pos+1] <= '9') {
+		return l.errorf("filter subpath must start with %s or %s", filterAt, root), true
+	}
+	return nil, false
+}
+
 func lexNumericLiteral(l *lexer, nextState stateFn) (stateFn, bool) {
 	n := l.peek()
 	if n == '.' || n == '-' || (n >= '0' && n <= '9') {
@@ -902,7 +1600,7 @@ func lexNumericLiteral(l *lexer, nextState stateFn) (stateFn, bool) {
 				return l.rawErrorf("invalid float literal %q: %s before position %d", err.Num, err, l.pos), true
 			}
 			l.emit(lexemeFilterFloatLiteral)
-			return lexFilterExpr, true
+			return nextState, true
 		}
 		// validate integer
 		if _, err := strconv.Atoi(l.value()); err != nil {
@@ -910,7 +1608,7 @@ func lexNumericLiteral(l *lexer, nextState stateFn) (stateFn, bool) {
 			return l.rawErrorf("invalid integer literal %q: %s before position %d", err.Num, err, l.pos), true
 		}
 		l.emit(lexemeFilterIntegerLiteral)
-		return lexFilterExpr, true
+		return nextState, true
 	}
 	return nil, false
 }
@@ -925,13 +1623,15 @@ func lexStringLiteral(l *lexer, nextState stateFn) (stateFn, bool) {
 	if quote != "" {
 		pos := l.pos
 		context := l.context()
-		for {
+		l.next()
+		for !l.hasPrefix(quote) {
+			// escaped quotes and backslashes
+			if l.consumed(`\'`) || l.consumed(`\"`) || l.consumed(`\\`) {
+				continue
+			}
 			if l.next() == eof {
 				return l.rawErrorf(`unmatched string delimiter %s at position %d, following %q`, quote, pos, context), true
 			}
-			if l.hasPrefix(quote) {
-				break
-			}
 		}
 		l.next()
 		l.emit(lexemeFilterStringLiteral)
@@ -969,14 +1669,14 @@ func init() {
 }
 
 func lexComparison(l *lexer, comparisonOperator orderingOperator) stateFn {
-	if l.lastEmittedLexemeType == lexemeFilterStringLiteral {
+	if l.lastEmittedLexemeType == lexemeFilterStringLiteral && !l.orderedStrings {
 		return l.errorf("strings cannot be compared using %s", comparisonOperator)
 	}
 	l.consume(comparisonOperator.String())
 	l.emit(comparisonOperatorLexeme[comparisonOperator])
 
 	l.stripWhitespace()
-	if l.hasPrefix(filterStringLiteralDelimiter) {
+	if l.hasPrefix(filterStringLiteralDelimiter) && !l.orderedStrings {
 		return l.errorf("strings cannot be compared using %s", comparisonOperator)
 	}
 
@@ -145,6 +145,15 @@ func TestLexer(t *testing.T) {
 				{typ: lexemeError, val: "invalid array index [1:2:0] before position 14: array index step value must be non-zero"},
 			},
 		},
+		{
+			name: "dot child with array subscript containing whitespace",
+			path: "$.child[1 2]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeDotChild, val: ".child"},
+				{typ: lexemeError, val: "invalid array index [1 2] before position 12: non-integer array index"},
+			},
+		},
 		{
 			name: "dot child with non-integer array subscript",
 			path: "$.child[1:2:a]",
@@ -163,6 +172,29 @@ func TestLexer(t *testing.T) {
 				{typ: lexemeError, val: `unmatched [ at position 9, following ".child[*"`},
 			},
 		},
+		{
+			name: "filter subpath with unclosed array subscript",
+			path: "$.a[?(@.b[0)]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeDotChild, val: ".a"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".b"},
+				{typ: lexemeError, val: `unmatched [ at position 11, following ".b[0"`},
+			},
+		},
+		{
+			name: "filter subpath with unclosed bracket child",
+			path: "$.a[?(@['x', y)]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeDotChild, val: ".a"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeError, val: `unmatched [ at position 12, following "@['x',"`},
+			},
+		},
 		{
 			name: "dot child with missing array subscript",
 			path: "$.child[]",
@@ -320,13 +352,31 @@ func TestLexer(t *testing.T) {
 		},
 		{
 			name: "bracket child followed by space",
-			path: "$['child'] ",
+			path: "$['child'] ['x']",
 			expected: []lexeme{
 				{typ: lexemeRoot, val: "$"},
 				{typ: lexemeBracketChild, val: "['child']"},
 				{typ: lexemeError, val: `invalid character ' ' at position 10, following "['child']"`},
 			},
 		},
+		{
+			name: "bracket child followed by trailing whitespace",
+			path: "$['child'] \n",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeBracketChild, val: "['child']"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "leading byte order mark",
+			path: "\ufeff$.child",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeDotChild, val: ".child"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
 		{
 			name: "bracket dotted child",
 			path: "$['child1.child2']",
@@ -598,6 +648,35 @@ func TestLexer(t *testing.T) {
 				{typ: lexemeError, val: `property name operator can only be used on last item in path at position 14, following "['child'][*]~"`},
 			},
 		},
+		{
+			name: "property name of filtered values",
+			path: "$.*[?(@ == 'x')]~",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeDotChild, val: ".*"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeFilterEquality, val: "=="},
+				{typ: lexemeFilterStringLiteral, val: "'x'"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeFilterPropertyName, val: "~"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "property name of filtered values with trailing chars",
+			path: "$.*[?(@ == 'x')]~.a",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeDotChild, val: ".*"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeFilterEquality, val: "=="},
+				{typ: lexemeFilterStringLiteral, val: "'x'"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeError, val: `property name operator may only be used on last child in path at position 17, following ")]~"`},
+			},
+		},
 		{
 			name: "property name bracket child with ~ in name",
 			path: "$['child~']~",
@@ -654,7 +733,7 @@ func TestLexer(t *testing.T) {
 		},
 		{
 			name: "property name bracket child followed by space",
-			path: "$['child']~ ",
+			path: "$['child']~ .x",
 			expected: []lexeme{
 				{typ: lexemeRoot, val: "$"},
 				{typ: lexemeBracketPropertyName, val: "['child']~"},
@@ -965,7 +1044,7 @@ func TestLexer(t *testing.T) {
 			expected: []lexeme{
 				{typ: lexemeRoot, val: "$"},
 				{typ: lexemeFilterBegin, val: "[?("},
-				{typ: lexemeError, val: `invalid filter syntax at position 4, following "[?("`},
+				{typ: lexemeError, val: `missing end of filter at position 4, following "[?("`},
 			},
 		},
 		{
@@ -988,6 +1067,538 @@ func TestLexer(t *testing.T) {
 				{typ: lexemeError, val: `invalid filter syntax at position 4, following "[?("`},
 			},
 		},
+		{
+			name: "bracket union of names and indexes",
+			path: "$['a', 0, 'b', -1]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeBracketUnion, val: "['a', 0, 'b', -1]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "bracket union of indexes and names",
+			path: `$.x[0, "a"].y`,
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeDotChild, val: ".x"},
+				{typ: lexemeBracketUnion, val: `[0, "a"]`},
+				{typ: lexemeDotChild, val: ".y"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter string literal with escaped quotes",
+			path: `$[?(@.name == 'O\'Brien' || @.name == "say \"hi\"")]`,
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".name"},
+				{typ: lexemeFilterEquality, val: "=="},
+				{typ: lexemeFilterStringLiteral, val: `'O\'Brien'`},
+				{typ: lexemeFilterOr, val: "||"},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".name"},
+				{typ: lexemeFilterEquality, val: "=="},
+				{typ: lexemeFilterStringLiteral, val: `"say \"hi\""`},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter string literal with escaped backslash",
+			path: `$[?(@ == 'a\\')]`,
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeFilterEquality, val: "=="},
+				{typ: lexemeFilterStringLiteral, val: `'a\\'`},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter string literal with escaped quote and no end",
+			path: `$[?(@ == 'a\')]`,
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeFilterEquality, val: "=="},
+				{typ: lexemeError, val: `unmatched string delimiter ' at position 9, following "== "`},
+			},
+		},
+		{
+			name: "filter time function",
+			path: "$[?(time(@.ts) > time('2023-01-01T00:00:00Z'))]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterTime, val: "time("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".ts"},
+				{typ: lexemeFilterCloseBracket, val: ")"},
+				{typ: lexemeFilterGreaterThan, val: ">"},
+				{typ: lexemeFilterTime, val: "time("},
+				{typ: lexemeFilterStringLiteral, val: "'2023-01-01T00:00:00Z'"},
+				{typ: lexemeFilterCloseBracket, val: ")"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter time function with invalid argument",
+			path: "$[?(time(true) > 1)]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterTime, val: "time("},
+				{typ: lexemeError, val: `invalid function argument at position 9, following "time("`},
+			},
+		},
+		{
+			name: "filter max function",
+			path: "$.players[?(@.score == max($.players[*].score))]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeDotChild, val: ".players"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".score"},
+				{typ: lexemeFilterEquality, val: "=="},
+				{typ: lexemeFilterMax, val: "max("},
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeDotChild, val: ".players"},
+				{typ: lexemeArraySubscript, val: "[*]"},
+				{typ: lexemeDotChild, val: ".score"},
+				{typ: lexemeFilterCloseBracket, val: ")"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter min function",
+			path: "$[?(min(@.*) < 0)]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterMin, val: "min("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".*"},
+				{typ: lexemeFilterCloseBracket, val: ")"},
+				{typ: lexemeFilterLessThan, val: "<"},
+				{typ: lexemeFilterIntegerLiteral, val: "0"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter avg function",
+			path: "$[?(any(@.scores[*] > avg(@.scores[*])))]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAny, val: "any("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".scores"},
+				{typ: lexemeArraySubscript, val: "[*]"},
+				{typ: lexemeFilterGreaterThan, val: ">"},
+				{typ: lexemeFilterAvg, val: "avg("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".scores"},
+				{typ: lexemeArraySubscript, val: "[*]"},
+				{typ: lexemeFilterCloseBracket, val: ")"},
+				{typ: lexemeFilterCloseBracket, val: ")"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter normalize function",
+			path: "$[?(normalize(@.name) == normalize(' a  b '))]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterNormalize, val: "normalize("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".name"},
+				{typ: lexemeFilterCloseBracket, val: ")"},
+				{typ: lexemeFilterEquality, val: "=="},
+				{typ: lexemeFilterNormalize, val: "normalize("},
+				{typ: lexemeFilterStringLiteral, val: "' a  b '"},
+				{typ: lexemeFilterCloseBracket, val: ")"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter length functions",
+			path: "$[?(length(@.name) < bytelength(@.name))]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterLength, val: "length("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".name"},
+				{typ: lexemeFilterCloseBracket, val: ")"},
+				{typ: lexemeFilterLessThan, val: "<"},
+				{typ: lexemeFilterByteLength, val: "bytelength("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".name"},
+				{typ: lexemeFilterCloseBracket, val: ")"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter text function",
+			path: "$..[?(text(@) =~ /needle/)]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeRecursiveDescent, val: ".."},
+				{typ: lexemeRecursiveFilterBegin, val: "[?("},
+				{typ: lexemeFilterText, val: "text("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeFilterCloseBracket, val: ")"},
+				{typ: lexemeFilterMatchesRegularExpression, val: "=~"},
+				{typ: lexemeFilterRegularExpressionLiteral, val: "/needle/"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter version function",
+			path: "$[?(version(@.v) >= version('1.2.0'))]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterVersion, val: "version("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".v"},
+				{typ: lexemeFilterCloseBracket, val: ")"},
+				{typ: lexemeFilterGreaterThanOrEqual, val: ">="},
+				{typ: lexemeFilterVersion, val: "version("},
+				{typ: lexemeFilterStringLiteral, val: "'1.2.0'"},
+				{typ: lexemeFilterCloseBracket, val: ")"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter member names",
+			path: "$[?(@~ =~ /^tmp_/ || 'a' == @~)]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterKeys, val: "@~"},
+				{typ: lexemeFilterMatchesRegularExpression, val: "=~"},
+				{typ: lexemeFilterRegularExpressionLiteral, val: "/^tmp_/"},
+				{typ: lexemeFilterOr, val: "||"},
+				{typ: lexemeFilterStringLiteral, val: "'a'"},
+				{typ: lexemeFilterEquality, val: "=="},
+				{typ: lexemeFilterKeys, val: "@~"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter index modulo",
+			path: "$[?(@@index % 10 == 0 || 1 == @@index)]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterIndex, val: "@@index"},
+				{typ: lexemeFilterModulo, val: "%"},
+				{typ: lexemeFilterIntegerLiteral, val: "10"},
+				{typ: lexemeFilterEquality, val: "=="},
+				{typ: lexemeFilterIntegerLiteral, val: "0"},
+				{typ: lexemeFilterOr, val: "||"},
+				{typ: lexemeFilterIntegerLiteral, val: "1"},
+				{typ: lexemeFilterEquality, val: "=="},
+				{typ: lexemeFilterIndex, val: "@@index"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter index modulo by zero",
+			path: "$[?(@@index % 0 == 0)]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterIndex, val: "@@index"},
+				{typ: lexemeFilterModulo, val: "%"},
+				{typ: lexemeError, val: `modulo divisor must be a positive integer at position 15, following "% 0"`},
+			},
+		},
+		{
+			name: "filter in set literal",
+			path: "$[?(@.status in [200, 2.5, 'OK', true, null])]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".status"},
+				{typ: lexemeFilterIn, val: "in"},
+				{typ: lexemeFilterSetBegin, val: "["},
+				{typ: lexemeFilterIntegerLiteral, val: "200"},
+				{typ: lexemeFilterFloatLiteral, val: "2.5"},
+				{typ: lexemeFilterStringLiteral, val: "'OK'"},
+				{typ: lexemeFilterBooleanLiteral, val: "true"},
+				{typ: lexemeFilterNullLiteral, val: "null"},
+				{typ: lexemeFilterSetEnd, val: "]"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter in unterminated set literal",
+			path: "$[?(@.status in [200)]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".status"},
+				{typ: lexemeFilterIn, val: "in"},
+				{typ: lexemeFilterSetBegin, val: "["},
+				{typ: lexemeFilterIntegerLiteral, val: "200"},
+				{typ: lexemeError, val: `missing ] or , in set literal at position 20, following "200"`},
+			},
+		},
+		{
+			name: "filter in without set literal",
+			path: "$[?(@.status in 200)]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".status"},
+				{typ: lexemeFilterIn, val: "in"},
+				{typ: lexemeError, val: `missing [ after in at position 16, following "in "`},
+			},
+		},
+		{
+			name: "filter is type name",
+			path: "$[?(@ is 'number')]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeFilterIs, val: "is"},
+				{typ: lexemeFilterStringLiteral, val: "'number'"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter is invalid type name",
+			path: "$[?(@.a is 'integer')]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".a"},
+				{typ: lexemeFilterIs, val: "is"},
+				{typ: lexemeError, val: `invalid type name after is, expected one of 'number', 'string', 'boolean', 'null', 'array', 'object' at position 11, following "is "`},
+			},
+		},
+		{
+			name: "filter logical operators in literals",
+			path: `$[?(@.x == 'a&&b' || @.y =~ /c||d/ && @.z != "e||f")]`,
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".x"},
+				{typ: lexemeFilterEquality, val: "=="},
+				{typ: lexemeFilterStringLiteral, val: "'a&&b'"},
+				{typ: lexemeFilterOr, val: "||"},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".y"},
+				{typ: lexemeFilterMatchesRegularExpression, val: "=~"},
+				{typ: lexemeFilterRegularExpressionLiteral, val: "/c||d/"},
+				{typ: lexemeFilterAnd, val: "&&"},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".z"},
+				{typ: lexemeFilterInequality, val: "!="},
+				{typ: lexemeFilterStringLiteral, val: `"e||f"`},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "pipe decoders",
+			path: "$.payload|base64|json.items[*]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeDotChild, val: ".payload"},
+				{typ: lexemePipeDecoder, val: "|base64"},
+				{typ: lexemePipeDecoder, val: "|json"},
+				{typ: lexemeDotChild, val: ".items"},
+				{typ: lexemeArraySubscript, val: "[*]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "pipe decoder in filter",
+			path: "$[?(@.p|json.a == 1)]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".p"},
+				{typ: lexemePipeDecoder, val: "|json"},
+				{typ: lexemeDotChild, val: ".a"},
+				{typ: lexemeFilterEquality, val: "=="},
+				{typ: lexemeFilterIntegerLiteral, val: "1"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "pipe decoder without name",
+			path: "$.payload|",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeDotChild, val: ".payload"},
+				{typ: lexemeError, val: `decoder name missing at position 10, following ".payload|"`},
+			},
+		},
+		{
+			name: "count selector",
+			path: "$.store.book[*].count()",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeDotChild, val: ".store"},
+				{typ: lexemeDotChild, val: ".book"},
+				{typ: lexemeArraySubscript, val: "[*]"},
+				{typ: lexemeCount, val: ".count()"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "count selector not at end",
+			path: "$.store.count().book",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeDotChild, val: ".store"},
+				{typ: lexemeError, val: `count() may only be used at the end of the path at position 15, following ".store.count()"`},
+			},
+		},
+		{
+			name: "count selector after recursive descent",
+			path: "$..count()",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeError, val: `count() may not follow recursive descent without a child name, wildcard or array access at position 3, following "$.."`},
+			},
+		},
+		{
+			name: "count selector after recursive descent child",
+			path: "$..a.count()",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeRecursiveDescent, val: "..a"},
+				{typ: lexemeCount, val: ".count()"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter parent existence",
+			path: "$[?(@^)]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterParent, val: "@^"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter parent comparison",
+			path: "$[?(@^.enabled == true)]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterParent, val: "@^"},
+				{typ: lexemeDotChild, val: ".enabled"},
+				{typ: lexemeFilterEquality, val: "=="},
+				{typ: lexemeFilterBooleanLiteral, val: "true"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter parent on right hand side",
+			path: "$[?(@ > @^[0])]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeFilterGreaterThan, val: ">"},
+				{typ: lexemeFilterParent, val: "@^"},
+				{typ: lexemeArraySubscript, val: "[0]"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter grandparent",
+			path: "$[?(@^^.a == @.b && @ == @^^[0])]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterGrandparent, val: "@^^"},
+				{typ: lexemeDotChild, val: ".a"},
+				{typ: lexemeFilterEquality, val: "=="},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".b"},
+				{typ: lexemeFilterAnd, val: "&&"},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeFilterEquality, val: "=="},
+				{typ: lexemeFilterGrandparent, val: "@^^"},
+				{typ: lexemeArraySubscript, val: "[0]"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter with bare relative subpath",
+			path: "$[?(.x)]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeError, val: `filter subpath must start with @ or $ at position 4, following "[?("`},
+			},
+		},
+		{
+			name: "filter with bare relative subpath in comparison",
+			path: "$[?(@.x == .y)]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".x"},
+				{typ: lexemeFilterEquality, val: "=="},
+				{typ: lexemeError, val: `filter subpath must start with @ or $ at position 11, following "== "`},
+			},
+		},
+		{
+			name: "filter with float literal starting with dot",
+			path: "$[?(@.x > .5)]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".x"},
+				{typ: lexemeFilterGreaterThan, val: ">"},
+				{typ: lexemeFilterFloatLiteral, val: ".5"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
 		{
 			name: "filter with misplaced open bracket",
 			path: "$[?(@.child ()]",
@@ -1916,6 +2527,43 @@ func TestLexer(t *testing.T) {
 				{typ: lexemeIdentity, val: ""},
 			},
 		},
+		{
+			name: "filter all quantifier",
+			path: "$[?(all(@.x[*] > 0))]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAll, val: "all("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".x"},
+				{typ: lexemeArraySubscript, val: "[*]"},
+				{typ: lexemeFilterGreaterThan, val: ">"},
+				{typ: lexemeFilterIntegerLiteral, val: "0"},
+				{typ: lexemeFilterCloseBracket, val: ")"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "filter any quantifier in conjunction",
+			path: "$[?(any(@.x[*]>0) && @.y)]",
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAny, val: "any("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".x"},
+				{typ: lexemeArraySubscript, val: "[*]"},
+				{typ: lexemeFilterGreaterThan, val: ">"},
+				{typ: lexemeFilterIntegerLiteral, val: "0"},
+				{typ: lexemeFilterCloseBracket, val: ")"},
+				{typ: lexemeFilterAnd, val: "&&"},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".y"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
 		{
 			name: "filter negation of comparison (edge case)",
 			path: "$[?(!@.child>1)]",
@@ -1988,6 +2636,27 @@ func TestLexer(t *testing.T) {
 				{typ: lexemeIdentity, val: ""},
 			},
 		},
+		{
+			name: "filter regular expression read from the document",
+			path: `$[?(@.child =~ @.pattern && @.x =~ $.p)]`,
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeFilterBegin, val: "[?("},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".child"},
+				{typ: lexemeFilterMatchesRegularExpression, val: "=~"},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".pattern"},
+				{typ: lexemeFilterAnd, val: "&&"},
+				{typ: lexemeFilterAt, val: "@"},
+				{typ: lexemeDotChild, val: ".x"},
+				{typ: lexemeFilterMatchesRegularExpression, val: "=~"},
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeDotChild, val: ".p"},
+				{typ: lexemeFilterEnd, val: ")]"},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
 		{
 			name: "filter regular expression with missing leading /",
 			path: `$[?(@.child=~.*/)]`,
@@ -2096,6 +2765,71 @@ func TestLexer(t *testing.T) {
 				{typ: lexemeError, val: `unsupported escape sequence inside '' at position 3, following "$['"`},
 			},
 		},
+		{
+			name: "unicode escape sequences in bracket child name",
+			path: `$['a\u0041\uD83D\uDE00']`,
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeBracketChild, val: `['a\u0041\uD83D\uDE00']`},
+				{typ: lexemeIdentity, val: ""},
+			},
+		},
+		{
+			name: "lone high surrogate in bracket child name",
+			path: `$['\uD800']`,
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeError, val: `lone high surrogate \uD800 in unicode escape sequence at position 9, following "$['\\uD800"`},
+			},
+		},
+		{
+			name: "high surrogate followed by other character in bracket child name",
+			path: `$['\uD800x']`,
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeError, val: `lone high surrogate \uD800 in unicode escape sequence at position 9, following "$['\\uD800"`},
+			},
+		},
+		{
+			name: "high surrogate followed by high surrogate in bracket child name",
+			path: `$['\uD83D\uD83D']`,
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeError, val: `high surrogate \uD83D followed by \uD83D instead of a low surrogate in unicode escape sequence at position 15, following "$['\\uD83D\\uD83D"`},
+			},
+		},
+		{
+			name: "lone low surrogate in bracket child name",
+			path: `$['\uDE00']`,
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeError, val: `lone low surrogate \uDE00 in unicode escape sequence at position 9, following "$['\\uDE00"`},
+			},
+		},
+		{
+			name: "incomplete unicode escape sequence in bracket child name",
+			path: `$['\u12']`,
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeError, val: `incomplete unicode escape sequence, \u must be followed by four hexadecimal digits at position 7, following "$['\\u12"`},
+			},
+		},
+		{
+			name: "unicode escape sequence with invalid digit in bracket child name",
+			path: `$['\u12G4']`,
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeError, val: `incomplete unicode escape sequence, \u must be followed by four hexadecimal digits at position 7, following "$['\\u12"`},
+			},
+		},
+		{
+			name: "incomplete low surrogate in bracket child name",
+			path: `$['\uD83D\uDE']`,
+			expected: []lexeme{
+				{typ: lexemeRoot, val: "$"},
+				{typ: lexemeError, val: `incomplete unicode escape sequence, \u must be followed by four hexadecimal digits at position 13, following "$['\\uD83D\\uDE"`},
+			},
+		},
 		{
 			name: "unclosed and empty bracket child name with space",
 			path: `$[ '`,
@@ -0,0 +1,582 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// located is a value together with its location in the document, values are wrapped only while evaluating
+// the locate operation
+type located struct {
+	parent  *located
+	key     any // string (object member) or int (array index), nil for the root value
+	value   any
+	name    bool // value is the property name of the member at key
+	missing bool // value is the null returned for a missing leaf (see ReturnNullForMissingLeaf)
+}
+
+// unwrap returns the value and its location (nil if the value is not located), container types without native
+// support are adapted to the Map interface
+func unwrap(value any) (any, *located) {
+	// check value is located
+	if l, ok := value.(*located); ok {
+		return adapt(l.value), l
+	}
+	return adapt(value), nil
+}
+
+// original returns the value without its location, containers are not adapted
+func original(value any) any {
+	// check value is located
+	if l, ok := value.(*located); ok {
+		return l.value
+	}
+	return value
+}
+
+// member returns the object member value at key, wrapped with its location if the parent is located
+func (l *located) member(key string, value any) any {
+	// check parent is located (the key is not boxed otherwise)
+	if l == nil {
+		return value
+	}
+	return &located{
+		parent: l,
+		key:    key,
+		value:  value,
+	}
+}
+
+// index returns the array item value at index, wrapped with its location if the parent is located
+func (l *located) index(index int, value any) any {
+	// check parent is located (the index is not boxed otherwise)
+	if l == nil {
+		return value
+	}
+	return &located{
+		parent: l,
+		key:    index,
+		value:  value,
+	}
+}
+
+// missingMember returns the null value of the missing object member at key, wrapped with its location if the parent is
+// located
+func (l *located) missingMember(key string) any {
+	// check parent is located
+	if l == nil {
+		return nil
+	}
+	return &located{
+		parent:  l,
+		key:     key,
+		missing: true,
+	}
+}
+
+// property returns the property name of the member at key, wrapped with its location if the parent is located
+func (l *located) property(key string) any {
+	// check parent is located
+	if l == nil {
+		return key
+	}
+	return &located{
+		parent: l,
+		key:    key,
+		value:  key,
+		name:   true,
+	}
+}
+
+// indexName returns the index of the array item at index as a value, wrapped with its location if the array is located
+func (l *located) indexName(index int) any {
+	// check array is located
+	if l == nil {
+		return index
+	}
+	return &located{
+		parent: l,
+		key:    index,
+		value:  index,
+		name:   true,
+	}
+}
+
+// container returns the value containing this location, nil if the value is not located or is the root value
+func (l *located) container() any {
+	// check parent is located
+	if l == nil || l.parent == nil {
+		return nil
+	}
+	// unwrap parent value
+	value, _ := unwrap(l.parent.value)
+	return value
+}
+
+// locatedContainer returns the location of the value containing this location, nil if the value is not located or is
+// the root value
+func (l *located) locatedContainer() any {
+	// check parent is located
+	if l == nil || l.parent == nil {
+		return nil
+	}
+	return l.parent
+}
+
+// set replaces the value at this location in its parent container, it returns false if the value cannot be replaced
+func (l *located) set(value any) bool {
+	// root values and property names cannot be replaced
+	if l.parent == nil || l.name {
+		return false
+	}
+	// process parent type
+	switch c := l.parent.value.(type) {
+
+	case map[string]any:
+		c[l.key.(string)] = value
+
+	case []any:
+		c[l.key.(int)] = value
+
+	case Map:
+		c.Set(l.key.(string), value)
+
+	case map[any]any:
+		anyKeyMap{interfaceMap(c)}.Set(l.key.(string), value)
+
+	case Array:
+		c.Set(l.key.(int), value)
+
+	default:
+		return false
+	}
+	// update location value
+	l.value = value
+	return true
+}
+
+// remove deletes the value at this location from its parent container, array items after it are shifted down and the
+// shortened array replaces the parent value in its own container
+func (l *located) remove() error {
+	// root values and property names cannot be removed
+	if l.parent == nil || l.name {
+		return fmt.Errorf("cannot remove value at %s", l.normalizedPath())
+	}
+	// process parent type
+	switch c := l.parent.value.(type) {
+
+	case map[string]any:
+		delete(c, l.key.(string))
+
+	case Map:
+		c.Delete(l.key.(string))
+
+	case map[any]any:
+		anyKeyMap{interfaceMap(c)}.Delete(l.key.(string))
+
+	case []any:
+		// index
+		index := l.key.(int)
+		// check the shortened array can replace the parent value
+		if l.parent.parent == nil {
+			return fmt.Errorf("cannot remove items from the root array at %s", l.normalizedPath())
+		}
+		// shift items down
+		copy(c[index:], c[index+1:])
+		// clear last item
+		c[len(c)-1] = nil
+		// replace parent value
+		if !l.parent.set(c[:len(c)-1]) {
+			return fmt.Errorf("cannot remove value at %s", l.normalizedPath())
+		}
+
+	default:
+		return fmt.Errorf("cannot remove value at %s", l.normalizedPath())
+	}
+	return nil
+}
+
+// matchedValue returns the value at this location, the member (or item) value if the location is a property name (or
+// an index)
+func (l *located) matchedValue() any {
+	// check property name
+	if !l.name {
+		return l.value
+	}
+	// process container type
+	switch c := l.container().(type) {
+
+	case map[string]any:
+		return c[l.key.(string)]
+
+	case Map:
+		// member value
+		v, _ := c.Values(l.key.(string))()
+		return v
+
+	case []any:
+		return c[l.key.(int)]
+
+	case Array:
+		// item value
+		v, _ := c.Values(false, l.key.(int))()
+		return v
+	}
+	return nil
+}
+
+// parentValue returns the array or object containing this location, nil if the value is not located or is the root
+// value
+func (l *located) parentValue() any {
+	// check parent is located
+	if l == nil || l.parent == nil {
+		return nil
+	}
+	return l.parent.value
+}
+
+// siblings returns the other values of the array or object containing this location, in the order wildcards
+// enumerate them, nil for the root value
+func (l *located) siblings() []any {
+	// check parent
+	if l == nil || l.parent == nil {
+		return nil
+	}
+	// siblings
+	siblings := []any{}
+	// unwrap parent value
+	parent, _ := unwrap(l.parent.value)
+	// process parent type
+	switch c := parent.(type) {
+
+	case []any:
+		// loop items
+		for i, v := range c {
+			// skip this location
+			if i != l.key {
+				siblings = append(siblings, v)
+			}
+		}
+
+	case map[string]any:
+		// loop members
+		loopMap(c, func(k string, v any) {
+			// skip this location
+			if k != l.key {
+				siblings = append(siblings, v)
+			}
+		})
+
+	case Array:
+		// value iterator
+		it := c.Values(false)
+		// loop items
+		i := 0
+		for v, ok := it(); ok; v, ok = it() {
+			// skip this location
+			if i != l.key {
+				siblings = append(siblings, v)
+			}
+			i++
+		}
+
+	case Map:
+		// key iterator
+		it := c.Keys()
+		// loop keys
+		for k, ok := it(); ok; k, ok = it() {
+			// skip this location
+			if k != l.key {
+				// member value
+				v, _ := c.Values(k.(string))()
+				siblings = append(siblings, v)
+			}
+		}
+	}
+	return siblings
+}
+
+// containerIdentity is the identity of an array or object, slices sharing the same backing array are distinct
+// containers unless they have the same length
+type containerIdentity struct {
+	typ     reflect.Type
+	pointer uintptr
+	length  int
+}
+
+// parentIdentity returns a comparable value identifying the array or object containing this location, nil for the
+// root value
+func (l *located) parentIdentity() any {
+	// check parent
+	if l == nil || l.parent == nil {
+		return nil
+	}
+	// parent value
+	v := reflect.ValueOf(l.parent.value)
+	// process value kind
+	switch v.Kind() {
+
+	case reflect.Map, reflect.Pointer:
+		return containerIdentity{typ: v.Type(), pointer: v.Pointer()}
+
+	case reflect.Slice:
+		return containerIdentity{typ: v.Type(), pointer: v.Pointer(), length: v.Len()}
+	}
+	// values without identity (e.g. structs) are identified by their location
+	return l.parent
+}
+
+// depth returns the number of ancestors of this location
+func (l *located) depth() int {
+	// depth
+	depth := 0
+	// loop ancestors
+	for p := l.parent; p != nil; p = p.parent {
+		// increment depth
+		depth++
+	}
+	return depth
+}
+
+// items returns the items in the slice, wrapped with their locations if the slice is located
+func (l *located) items(values []any) []any {
+	// check slice is located
+	if l == nil {
+		return values
+	}
+	// located items
+	items := make([]any, 0, len(values))
+	// loop over slice
+	for i, v := range values {
+		// append item
+		items = append(items, l.index(i, v))
+	}
+	return items
+}
+
+// arrayValues returns an iterator over the array values at indexes (all values if no indexes are provided),
+// wrapped with their locations if the array is located
+func (l *located) arrayValues(a Array, indexes ...int) Iterator {
+	// check array is located
+	if l == nil {
+		return a.Values(false, indexes...)
+	}
+	// check we need all values
+	if len(indexes) == 0 {
+		// length
+		length := a.Len()
+		// all indexes
+		indexes = make([]int, 0, length)
+		for i := 0; i < length; i++ {
+			indexes = append(indexes, i)
+		}
+	}
+	// values
+	values := make([]any, 0, len(indexes))
+	// loop indexes
+	for _, i := range indexes {
+		// value @ i
+		if v, ok := a.Values(false, i)(); ok {
+			// append value
+			values = append(values, l.index(i, v))
+		}
+	}
+	return FromValues(false, values...)
+}
+
+// mapValues returns an iterator over the map values at keys (all values if no keys are provided),
+// wrapped with their locations if the map is located
+func (l *located) mapValues(m Map, keys ...string) Iterator {
+	// check map is located
+	if l == nil {
+		return m.Values(keys...)
+	}
+	// check we need all values
+	if len(keys) == 0 {
+		// key iterator
+		it := m.Keys()
+		// collect keys
+		for k, ok := it(); ok; k, ok = it() {
+			keys = append(keys, k.(string))
+		}
+	}
+	// values
+	values := make([]any, 0, len(keys))
+	// loop keys
+	for _, k := range keys {
+		// value @ k
+		if v, ok := m.Values(k)(); ok {
+			// append value
+			values = append(values, l.member(k, v))
+		}
+	}
+	return FromValues(false, values...)
+}
+
+// keys returns the location keys from the root value to this location
+func (l *located) keys() []any {
+	// count keys
+	n := 0
+	for c := l; c != nil && c.parent != nil; c = c.parent {
+		n++
+	}
+	// keys from root
+	keys := make([]any, n)
+	for c := l; c != nil && c.parent != nil; c = c.parent {
+		n--
+		keys[n] = c.key
+	}
+	return keys
+}
+
+// normalizedPath returns the location in the RFC 9535 normalized path form, e.g. $['a'][0]
+func (l *located) normalizedPath() string {
+	// builder
+	var sb strings.Builder
+	// root
+	sb.WriteString(root)
+	// loop keys
+	for _, key := range l.keys() {
+		// process key type
+		switch k := key.(type) {
+
+		case int:
+			// index selector
+			sb.WriteString(leftBracket)
+			sb.WriteString(strconv.Itoa(k))
+			sb.WriteString(rightBracket)
+
+		case string:
+			// name selector
+			sb.WriteString(leftBracket)
+			sb.WriteString(normalizedName(k))
+			sb.WriteString(rightBracket)
+
+		default:
+			panic(fmt.Sprintf("invalid location key %v", key)) // should never happen
+		}
+	}
+	return sb.String()
+}
+
+// normalizedName returns the member name as a single quoted string escaped according to RFC 9535
+func normalizedName(name string) string {
+	// builder
+	var sb strings.Builder
+	// opening quote
+	sb.WriteByte('\'')
+	// loop runes
+	for _, r := range name {
+		switch r {
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\'':
+			sb.WriteString(`\'`)
+		case '\\':
+			sb.WriteString(`\\`)
+		default:
+			// check control characters
+			if r < 0x20 {
+				// lower case hex digits
+				fmt.Fprintf(&sb, `\u%04x`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	// closing quote
+	sb.WriteByte('\'')
+	return sb.String()
+}
+
+// mapKeys returns an iterator over the map keys (all keys if no keys are provided), wrapped with their
+// locations if the map is located
+func (l *located) mapKeys(m Map, keys ...string) Iterator {
+	// check map is located
+	if l == nil {
+		return m.Keys(keys...)
+	}
+	// key iterator
+	it := m.Keys(keys...)
+	// located keys
+	values := []any{}
+	// loop keys
+	for k, ok := it(); ok; k, ok = it() {
+		// append key
+		values = append(values, l.property(k.(string)))
+	}
+	return FromValues(false, values...)
+}
+
+// uniqueLocations returns the locations without duplicates, locations are the same if they have the same normalized
+// path (regardless of the descents that reached them)
+func uniqueLocations(locations []*located) []*located {
+	// seen normalized paths
+	seen := map[string]bool{}
+	// unique locations
+	unique := make([]*located, 0, len(locations))
+	// loop locations
+	for _, l := range locations {
+		// normalized path
+		path := l.normalizedPath()
+		// check property names (they share the member normalized path)
+		if l.name {
+			path += propertyName
+		}
+		// check location was seen
+		if seen[path] {
+			continue
+		}
+		// update state
+		seen[path] = true
+		unique = append(unique, l)
+	}
+	return unique
+}
+
+// checkLocatable checks the values matched by the path are part of the input data, decoded values (see pipe decoders)
+// and counts have no location
+func (ctx *pathContext) checkLocatable(expression string) error {
+	// check decoders
+	if ctx.decodesValues {
+		return fmt.Errorf("path %s matches decoded values, which have no location in the input data", expression)
+	}
+	// check count
+	if ctx.countsResult {
+		return fmt.Errorf("path %s matches a count, which has no location in the input data", expression)
+	}
+	return nil
+}
+
+// locate evaluates the path on the given data and returns the location of each matching value
+func locate(path *Path, data any) []*located {
+	// evaluate path on located root value
+	it := path.expression(locateOperation, &located{value: data}, data)
+	// locations
+	locations := []*located{}
+	// loop iterator
+	for v, ok := it(); ok; v, ok = it() {
+		// all values must be located
+		if _, loc := unwrap(v); loc != nil {
+			// append location
+			locations = append(locations, loc)
+		}
+	}
+	return locations
+}
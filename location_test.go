@@ -0,0 +1,42 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizedName(t *testing.T) {
+	cases := []struct {
+		name     string
+		key      string
+		expected string
+	}{
+		{name: "plain", key: "a", expected: `'a'`},
+		{name: "empty", key: "", expected: `''`},
+		{name: "single quote", key: "it's", expected: `'it\'s'`},
+		{name: "double quote", key: `say "hi"`, expected: `'say "hi"'`},
+		{name: "backslash", key: `a\b`, expected: `'a\\b'`},
+		{name: "backspace", key: "\b", expected: `'\b'`},
+		{name: "form feed", key: "\f", expected: `'\f'`},
+		{name: "line feed", key: "\n", expected: `'\n'`},
+		{name: "carriage return", key: "\r", expected: `'\r'`},
+		{name: "tab", key: "\t", expected: `'\t'`},
+		{name: "vertical tab", key: "\u000B", expected: `'\u000b'`},
+		{name: "null character", key: "\u0000", expected: `'\u0000'`},
+		{name: "unit separator", key: "\u001F", expected: `'\u001f'`},
+		{name: "delete is not escaped", key: "\u007F", expected: "'\u007F'"},
+		{name: "unicode", key: "☺ü", expected: `'☺ü'`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, normalizedName(tc.key))
+		})
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"encoding/json"
+)
+
+// normalizeNumbers converts numbers into float64 recursively, arrays and objects are copied so the input data is
+// not modified
+func normalizeNumbers(value any) any {
+	// process value type
+	switch v := value.(type) {
+
+	case int:
+		return float64(v)
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+
+	case json.Number:
+		// parse number
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return value
+
+	case map[string]any:
+		// copy map
+		m := make(map[string]any, len(v))
+		// normalize values
+		for k, mv := range v {
+			m[k] = normalizeNumbers(mv)
+		}
+		return m
+
+	case []any:
+		// copy array
+		a := make([]any, len(v))
+		// normalize items
+		for i, av := range v {
+			a[i] = normalizeNumbers(av)
+		}
+		return a
+
+	default:
+		return value
+	}
+}
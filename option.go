@@ -28,3 +28,239 @@ func AlwaysReturnList() Option {
 		},
 	}
 }
+
+// WithCompareFunc uses the given function to compare numbers and strings in filter expressions. Strings may be
+// compared using ordering operators when this option is used.
+func WithCompareFunc(fn CompareFunc) Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.compare = fn
+		},
+	}
+}
+
+// WithEquality replaces the default typed equality used by the == and != filter comparisons and by the in filter
+// operator with the given function, e.g. to compare strings ignoring case. Ordering comparisons are not affected. The
+// function decides on its own whether two values are equal: EmptyStringIsNull, LooseEquality and EqualityAcrossTypes
+// are rejected with this option and CompareTimes applies to ordering comparisons only.
+func WithEquality(fn EqualityFunc) Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.equality = fn
+		},
+	}
+}
+
+// EqualityAcrossTypes makes the == and != filter comparisons of a string with a number or a boolean compare their
+// string forms, e.g. "8" == 8 and "true" == true. By default values of different types are never equal.
+func EqualityAcrossTypes() Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.equalityAcrossTypes = true
+		},
+	}
+}
+
+// LooseEquality makes the == and != filter comparisons of a number with a numeric string compare the string as a
+// number, e.g. "200" == 200 and "8.50" == 8.5. Ordering comparisons stay strict.
+func LooseEquality() Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.looseEquality = true
+		},
+	}
+}
+
+// EmptyStringIsNull makes the == and != filter comparisons treat empty strings as null, so @.x == null also matches
+// empty strings and @.x != "" also excludes null values. By default empty strings and null are distinct.
+func EmptyStringIsNull() Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.emptyStringIsNull = true
+		},
+	}
+}
+
+// UnwrapSingletonArrays makes filter comparisons (including in) treat an array holding a single item as the item
+// itself, e.g. @.x > 3 matches x = [5]. Arrays holding more items are compared as usual.
+func UnwrapSingletonArrays() Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.unwrapSingletonArrays = true
+		},
+	}
+}
+
+// RecursiveDepthRange limits recursive descent (..) to the descendants between min and max levels below the value
+// the descent starts from (the value itself is at level 0). A negative max means no upper bound.
+func RecursiveDepthRange(min, max int) Option {
+	// check lower bound
+	if min < 0 {
+		min = 0
+	}
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.recursiveDepth = &depthRange{
+				min: min,
+				max: max,
+			}
+		},
+	}
+}
+
+// RecurseInto limits recursive descent (..) to the values for which fn returns true, e.g. to skip custom types
+// implementing Array or Map. Values rejected by fn are still visited, their children are not. By default recursive
+// descent enters all arrays and objects.
+func RecurseInto(fn func(value any) bool) Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.recurseInto = fn
+		},
+	}
+}
+
+// SkipNullElements makes wildcards ([*] and .*) skip the null items of arrays, so the following segments and filters
+// are applied to the other items only, e.g. $.a[*] returns no null values. Array indexes and slices, as well as null
+// object members, are not affected.
+func SkipNullElements() Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.skipNullElements = true
+		},
+	}
+}
+
+// RecursiveFilterLeavesOnly applies filters following a recursive descent ($..[?()]) to leaf values only, arrays and
+// objects are not tested. By default every descendant is tested, containers included.
+func RecursiveFilterLeavesOnly() Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.recursiveFilterLeavesOnly = true
+		},
+	}
+}
+
+// NormalizeNumbers converts every number in the result (including numbers nested in arrays and objects) to float64.
+// Arrays and objects holding numbers are copied, the input data is not modified. NewPath, CompileAll and NewLens reject
+// this option.
+func NormalizeNumbers() Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.normalizeNumbers = true
+		},
+	}
+}
+
+// SortByValue sorts the result by value, in descending order if desc is set, e.g. to display the top N values of
+// $.scores.*. Numbers of any Go type (e.g. uint8 or json.Number) are sorted numerically, strings lexicographically and
+// false before true. Values of different types are grouped by type whatever the direction: numbers, strings, booleans,
+// null, arrays and objects. Equal values, arrays and objects keep their order. NewPath, CompileAll and NewLens reject
+// this option.
+func SortByValue(desc bool) Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.sortByValue = true
+			ctx.sortDescending = desc
+		},
+	}
+}
+
+// SkipTypeMismatches makes GetTyped skip the matching values that cannot be converted to the requested type instead
+// of returning an error.
+func SkipTypeMismatches() Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.skipTypeMismatches = true
+		},
+	}
+}
+
+// TimeEpochMillis interprets numbers passed to the time() filter function as milliseconds since the epoch instead of
+// seconds.
+func TimeEpochMillis() Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.epochMillis = true
+		},
+	}
+}
+
+// CompareTimes compares filter operands as instants when both of them are timestamps and at least one of them is a
+// string, e.g. @.created > '2023-01-01T00:00:00Z'. Strings are RFC 3339 timestamps and numbers are epoch seconds (or
+// milliseconds, see TimeEpochMillis), other operands are compared as usual.
+func CompareTimes() Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.compareTimes = true
+		},
+	}
+}
+
+// WildcardArraysWithDot controls whether the dot wildcard (.*) matches the items of arrays, it does by default. When
+// disabled, .* matches object members only and [*] must be used to match array items.
+func WildcardArraysWithDot(enabled bool) Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.dotWildcardObjectsOnly = !enabled
+		},
+	}
+}
+
+// MaxNodeVisits limits the number of nodes visited while evaluating the expression (including filter subpaths), the
+// evaluation is aborted with ErrBudgetExceeded once more than n nodes are visited. Each call (Get, Set, etc.) has its own
+// budget. NewPath, CompileAll and NewLens reject this option since their evaluations cannot report the error.
+func MaxNodeVisits(n int) Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.budget = &budget{max: int64(n)}
+		},
+	}
+}
+
+// StrictTypes makes filter comparisons of incompatible types (e.g. 'x' == 7) an evaluation error wrapping
+// ErrIncompatibleTypes instead of silently not matching, which helps debugging complex filters. Comparisons with null
+// and set membership (in) are not affected. Like MaxNodeVisits, the errors are collected by each call of the functions
+// compiling the expression (Get, Set, etc.) and NewPath, CompileAll and NewLens reject this option.
+func StrictTypes() Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.typeErrors = &typeErrors{}
+		},
+	}
+}
+
+// StrictRFC9535 rejects expressions using syntax extensions not defined by RFC 9535, e.g. undotted children, the
+// property name selector (~), parent references (@^) or the regular expression match (=~), to validate that stored
+// queries are portable to other compliant implementations.
+func StrictRFC9535() Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.strictRFC9535 = true
+		},
+	}
+}
+
+// FlatRootEntry makes Path.EvaluateFlat return an entry for the root value itself ($ holding the whole document) before
+// its descendants, as some JSON flattening conventions do. The root entry is omitted by default.
+func FlatRootEntry() Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.flatRootEntry = true
+		},
+	}
+}
+
+// KnownKeys rejects expressions selecting a child name (in dot or bracket notation, recursive descent or filter
+// subpaths) which is not one of the given keys, e.g. a misspelled $.stroe.book when the document schema is known.
+// Wildcards and array indexes are always accepted.
+func KnownKeys(keys ...string) Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			// known keys
+			ctx.knownKeys = make(map[string]bool, len(keys))
+			for _, key := range keys {
+				ctx.knownKeys[key] = true
+			}
+		},
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+// OrderedMap is an object implementing the Map interface that keeps its members in insertion order, e.g. to query
+// documents decoded with an order preserving JSON library. Wildcards and recursive descent enumerate the members of an
+// OrderedMap in insertion order, so results follow the source document order.
+type OrderedMap struct {
+	keys []string
+	m    map[string]any
+}
+
+// NewOrderedMap creates an empty OrderedMap.
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{
+		m: map[string]any{},
+	}
+}
+
+// Get returns the value of the member with the given key.
+func (o *OrderedMap) Get(key string) (any, bool) {
+	// find member
+	v, ok := o.m[key]
+	return v, ok
+}
+
+// Len returns the number of members.
+func (o *OrderedMap) Len() int {
+	return len(o.keys)
+}
+
+func (o *OrderedMap) Keys(keys ...string) Iterator {
+	// check we need all keys
+	if len(keys) == 0 {
+		keys = o.keys
+	}
+	// keys in map
+	values := make([]any, 0, len(keys))
+	// loop keys
+	for _, k := range keys {
+		// find key in map
+		if _, ok := o.m[k]; ok {
+			// append key
+			values = append(values, k)
+		}
+	}
+	return FromValues(false, values...)
+}
+
+func (o *OrderedMap) Values(keys ...string) Iterator {
+	// check we need all values
+	if len(keys) == 0 {
+		keys = o.keys
+	}
+	// values in map
+	values := make([]any, 0, len(keys))
+	// loop keys
+	for _, k := range keys {
+		// find key in map
+		if v, ok := o.m[k]; ok {
+			// append value
+			values = append(values, v)
+		}
+	}
+	return FromValues(false, values...)
+}
+
+// Set sets the value of the member with the given key, new members are added after the existing ones.
+func (o *OrderedMap) Set(key string, value any) {
+	// check new member
+	if _, ok := o.m[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.m[key] = value
+}
+
+func (o *OrderedMap) Delete(key string) {
+	// check member
+	if _, ok := o.m[key]; !ok {
+		return
+	}
+	delete(o.m, key)
+	// remove key
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// orderedMap creates an OrderedMap with the given members, in insertion order
+func orderedMap(members ...any) *OrderedMap {
+	// map
+	m := NewOrderedMap()
+	// loop key/value pairs
+	for i := 0; i < len(members); i += 2 {
+		m.Set(members[i].(string), members[i+1])
+	}
+	return m
+}
+
+func TestOrderedMapInsertionOrder(t *testing.T) {
+	// test cases
+	tcs := []struct {
+		name     string
+		data     func() *OrderedMap
+		path     string
+		expected []any
+	}{
+		{
+			name:     "wildcard",
+			data:     func() *OrderedMap { return orderedMap("z", 1, "a", 2, "m", 3) },
+			path:     "$.*",
+			expected: []any{1, 2, 3},
+		},
+		{
+			name:     "bracket wildcard property names",
+			data:     func() *OrderedMap { return orderedMap("z", 1, "a", 2, "m", 3) },
+			path:     "$[*]~",
+			expected: []any{"z", "a", "m"},
+		},
+		{
+			name:     "recursive descent",
+			data:     func() *OrderedMap { return orderedMap("z", orderedMap("y", 1, "b", 2), "a", []any{3, 4}, "m", 5) },
+			path:     "$..*",
+			expected: []any{orderedMap("y", 1, "b", 2), []any{3, 4}, 5, 1, 2, 3, 4},
+		},
+		{
+			name:     "recursive descent child",
+			data:     func() *OrderedMap { return orderedMap("z", orderedMap("v", 1), "a", orderedMap("v", 2), "v", 3) },
+			path:     "$..v",
+			expected: []any{3, 1, 2},
+		},
+		{
+			name:     "recursive descent property names",
+			data:     func() *OrderedMap { return orderedMap("z", orderedMap("y", 1, "b", 2), "a", 3) },
+			path:     "$..*~",
+			expected: []any{"z", "a", "y", "b"},
+		},
+		{
+			name: "updated member keeps its position",
+			data: func() *OrderedMap {
+				m := orderedMap("z", 1, "a", 2, "m", 3)
+				m.Set("z", 4)
+				return m
+			},
+			path:     "$.*",
+			expected: []any{4, 2, 3},
+		},
+		{
+			name: "deleted and added members",
+			data: func() *OrderedMap {
+				m := orderedMap("z", 1, "a", 2, "m", 3)
+				m.Delete("z")
+				m.Set("b", 4)
+				m.Set("z", 5)
+				return m
+			},
+			path:     "$[*]~",
+			expected: []any{"a", "m", "b", "z"},
+		},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// act
+			result, err := Get(tc.data(), tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result, cmp.AllowUnexported(OrderedMap{})); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestOrderedMapSet(t *testing.T) {
+	// arrange
+	var data = orderedMap("z", 1, "a", 2)
+	// act (new members are added after the existing ones)
+	if err := Set(data, "$.b", 3); err != nil {
+		t.Errorf("Failed to set value: %v", err)
+	}
+	// assert
+	result, err := Get(data, "$[*]~")
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff([]any{"z", "a", "b"}, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
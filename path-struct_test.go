@@ -109,7 +109,7 @@ func TestRecursiveDescentStructPath3(t *testing.T) {
 	// act
 	result := path.Evaluate(value)
 	// assert
-	if diff := cmp.Diff([]any{TestMap{"a": "test1"}, TestMap{"a": "test2"}, "test2", "test1"}, result); diff != "" {
+	if diff := cmp.Diff([]any{TestMap{"a": "test1"}, TestMap{"a": "test2"}, "test1", "test2"}, result); diff != "" {
 		t.Errorf("invalid result: %s", diff)
 	}
 }
@@ -12,8 +12,14 @@
 package jsonpath
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf16"
 	"unicode/utf8"
 )
 
@@ -23,6 +29,7 @@ const (
 	getOperation operation = iota
 	setOperation
 	deleteOperation
+	locateOperation
 )
 
 type pathExpression func(operation operation, value, root any) Iterator
@@ -33,24 +40,287 @@ type deleteExpression func() error
 
 // Path is a compiled JsonPath expression.
 type Path struct {
-	expression pathExpression
-	terminal   bool
+	expression    pathExpression
+	terminal      bool
+	source        string
+	options       []Option
+	flatRootEntry bool
 }
 
 type pathContext struct {
-	definite                 bool
-	returnNullForMissingLeaf bool
-	returnList               bool
+	definite                  bool
+	returnNullForMissingLeaf  bool
+	returnList                bool
+	compare                   CompareFunc
+	recursiveDepth            *depthRange
+	recurseInto               func(any) bool
+	recursiveFilterLeavesOnly bool
+	normalizeNumbers          bool
+	sortByValue               bool
+	sortDescending            bool
+	skipTypeMismatches        bool
+	epochMillis               bool
+	dotWildcardObjectsOnly    bool
+	budget                    *budget
+	deadline                  *timeLimit
+	typeErrors                *typeErrors
+	knownKeys                 map[string]bool
+	strictRFC9535             bool
+	skipNullElements          bool
+	unwrapSingletonArrays     bool
+	lexers                    *sync.Pool
+	equality                  EqualityFunc
+	compareTimes              bool
+	equalityAcrossTypes       bool
+	emptyStringIsNull         bool
+	looseEquality             bool
+	locatesContainers         bool
+	countsResult              bool
+	decodesValues             bool
+	flatRootEntry             bool
+	options                   []Option
 }
 
-// NewPath constructs a Path from a JsonPath expression.
-func NewPath(path string) (*Path, error) {
+type depthRange struct {
+	min int
+	max int
+}
+
+// NewPath constructs a Path from a JsonPath expression. Options reporting evaluation errors (MaxNodeVisits and
+// StrictTypes) and options post-processing the result (NormalizeNumbers and SortByValue) are rejected since Path
+// evaluations neither return errors nor post-process their values.
+func NewPath(path string, options ...Option) (*Path, error) {
+	// create path instance
+	ctx, p, err := compile(path, options)
+	if err != nil {
+		return nil, err
+	}
+	// check options
+	if err := ctx.checkReusable(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// CompileAll compiles each of the given JsonPath expressions exactly as NewPath does. The lexers used by the
+// compilations are reused, which reduces allocations when compiling large catalogs of paths (e.g. at startup). The error
+// returned for an invalid expression includes its index.
+func CompileAll(expressions []string, options ...Option) ([]*Path, error) {
+	// paths
+	paths := make([]*Path, 0, len(expressions))
+	// loop expressions
+	for i, expression := range expressions {
+		// create context reusing lexers
+		ctx := newContext(options)
+		ctx.lexers = &lexers
+		// create Path
+		p, err := newPathWithContext(ctx, expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path expression at index %d: %w", i, err)
+		}
+		// check options
+		if err := ctx.checkReusable(); err != nil {
+			return nil, err
+		}
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
+
+// checkReusable checks the options can be used by paths evaluated several times without reporting errors (compiled
+// paths and lenses), the node visit budget and the type errors belong to a single evaluation and the result
+// post-processing belongs to the functions collecting the result
+func (ctx *pathContext) checkReusable() error {
+	// check budget
+	if ctx.budget != nil {
+		return errors.New("MaxNodeVisits option is not supported by compiled paths and lenses, use functions returning errors (e.g. Get)")
+	}
+	// check type errors
+	if ctx.typeErrors != nil {
+		return errors.New("StrictTypes option is not supported by compiled paths and lenses, use functions returning errors (e.g. Get)")
+	}
+	// check normalized numbers
+	if ctx.normalizeNumbers {
+		return errors.New("NormalizeNumbers option is not supported by compiled paths and lenses, use functions returning the result (e.g. Get)")
+	}
+	// check sorted result
+	if ctx.sortByValue {
+		return errors.New("SortByValue option is not supported by compiled paths and lenses, use functions returning the result (e.g. Get)")
+	}
+	return nil
+}
+
+// postProcess applies the result options (NormalizeNumbers and SortByValue) to the values collected by an evaluation,
+// the values are updated in place
+func (ctx *pathContext) postProcess(result []any) {
+	// check we need to normalize numbers
+	if ctx.normalizeNumbers {
+		// loop results
+		for i, r := range result {
+			// convert numbers to float64
+			result[i] = normalizeNumbers(r)
+		}
+	}
+	// sort result (if requested)
+	ctx.sortResult(result)
+}
+
+// lexer returns a lexer scanning the input, reused from the context pool (if any)
+func (ctx *pathContext) lexer(input string) *lexer {
+	// check pool
+	if ctx.lexers == nil {
+		return lex(input)
+	}
+	// reuse lexer
+	l := ctx.lexers.Get().(*lexer)
+	l.reset(input)
+	return l
+}
+
+// release returns the lexer to the context pool (if any) once the compilation is complete
+func (ctx *pathContext) release(l *lexer) {
+	// check pool
+	if ctx.lexers != nil {
+		ctx.lexers.Put(l)
+	}
+}
+
+func newPathWithContext(ctx *pathContext, path string) (*Path, error) {
 	// create lexer
-	lexer := lex(path)
-	// create path context, use defaults
-	ctx := &pathContext{}
+	lexer := ctx.lexer(path)
+	defer ctx.release(lexer)
+	// check equality options
+	if err := ctx.checkEquality(); err != nil {
+		return nil, err
+	}
+	// strings can be ordered only when a custom comparison is provided or they are compared as timestamps
+	lexer.orderedStrings = ctx.compare != nil || ctx.compareTimes
 	// create path instance
-	return createPath(ctx, lexer)
+	p, err := createPath(ctx, lexer)
+	if err != nil {
+		return nil, err
+	}
+	// check RFC 9535 syntax
+	if err := ctx.checkRFC9535(path); err != nil {
+		return nil, err
+	}
+	// check filters refer to the container of the container of the values being filtered
+	if ctx.locatesContainers {
+		// track locations
+		p = locatedThen(p)
+	}
+	// check the path ends with count()
+	if ctx.countsResult {
+		// the result is a single number
+		ctx.definite = true
+		// count values
+		p = countThen(p)
+	}
+	// keep the expression and options, prefixes of the expression are compiled with the same options
+	p.source = path
+	p.options = ctx.options
+	// keep the EvaluateFlat root entry option
+	p.flatRootEntry = ctx.flatRootEntry
+	return p, nil
+}
+
+// countThen evaluates the operation and returns the number of values it produces, i.e. the size of the result
+func countThen(path *Path) *Path {
+	return &Path{
+		expression: func(operation operation, value, root any) Iterator {
+			// evaluate operation
+			it := path.expression(operation, value, root)
+			// count values
+			n := 0
+			// loop iterator
+			for _, ok := it(); ok; _, ok = it() {
+				n++
+			}
+			return fromValue(n)
+		},
+		terminal: path.terminal,
+	}
+}
+
+// locatedThen evaluates the operation on the located value and returns the results without their locations, so
+// containers of containers are known in filters
+func locatedThen(path *Path) *Path {
+	return &Path{
+		expression: func(operation operation, value, root any) Iterator {
+			// check value is located already
+			if _, loc := unwrap(value); loc != nil {
+				return path.expression(operation, value, root)
+			}
+			// evaluate operation on located value
+			it := path.expression(operation, &located{value: value}, root)
+			// remove locations
+			return func() (any, bool) {
+				// next value
+				v, ok := it()
+				// check value is located
+				if l, located := v.(*located); located {
+					return l.value, ok
+				}
+				return v, ok
+			}
+		},
+		terminal: path.terminal,
+	}
+}
+
+// filterContext returns the context used to compile filter subpaths, the options applying to the whole expression or
+// to its result are reset.
+func (ctx *pathContext) filterContext() *pathContext {
+	// copy the settings (new options apply to filters unless they are reset below)
+	filterCtx := *ctx
+	// reset the state of the path being compiled
+	filterCtx.definite = false
+	filterCtx.locatesContainers = false
+	filterCtx.countsResult = false
+	filterCtx.decodesValues = false
+	// reset the options applying to the whole expression or to its result only
+	filterCtx.returnNullForMissingLeaf = false
+	filterCtx.returnList = false
+	filterCtx.normalizeNumbers = false
+	filterCtx.sortByValue = false
+	filterCtx.sortDescending = false
+	filterCtx.skipTypeMismatches = false
+	filterCtx.knownKeys = nil
+	filterCtx.strictRFC9535 = false
+	filterCtx.flatRootEntry = false
+	filterCtx.options = nil
+	return &filterCtx
+}
+
+// itemsAreResult checks whether the items selected by a wildcard are the result of the operation, i.e. a get
+// operation with a terminal path, no node visit budget and no deadline, so they can be returned without evaluating the
+// path on each of them
+func (ctx *pathContext) itemsAreResult(operation operation, path *Path, loc *located) bool {
+	return operation == getOperation && path.terminal && loc == nil && ctx.budget == nil && ctx.deadline == nil
+}
+
+// recurse returns the values in the iterator and their descendants, honoring the recursive depth range, the node
+// visit budget and the deadline (if any).
+func (ctx *pathContext) recurse(it Iterator) Iterator {
+	// unbounded depth range
+	min, max := 0, -1
+	// check depth range
+	if ctx.recursiveDepth != nil {
+		min, max = ctx.recursiveDepth.min, ctx.recursiveDepth.max
+	}
+	// recursive iterator
+	it = it.recurseValues(min, max, ctx.recurseInto)
+	// check budget
+	if ctx.budget != nil {
+		// stop recursion once the budget is exceeded
+		it = ctx.budget.stop(it)
+	}
+	// check deadline
+	if ctx.deadline != nil {
+		// stop recursion once the deadline expires
+		it = ctx.deadline.stop(it)
+	}
+	return it
 }
 
 // Evaluate evaluates the compiled JsonPath expression get operation on the given value.
@@ -61,10 +331,290 @@ func (p *Path) Evaluate(value any) []any {
 	return it.ToSlice()
 }
 
+// EvaluateToStructure evaluates the compiled JsonPath expression get operation on the given value and returns a copy
+// of the document holding only the matching values, e.g. $..price returns the objects and arrays leading to each price.
+// Arrays in the copy hold the matching items in document order and are therefore compacted. It returns nil if the
+// expression does not match any value.
+func (p *Path) EvaluateToStructure(value any) any {
+	// locate matching values
+	locations := locate(p, value)
+	// check matches
+	if len(locations) == 0 {
+		return nil
+	}
+	// structure root
+	root := &structureNode{}
+	// loop locations
+	for _, l := range locations {
+		// add location
+		root.add(l.keys(), l.matchedValue())
+	}
+	return root.build()
+}
+
+// FlatValue is a value returned by EvaluateFlat together with its location in the document.
+type FlatValue struct {
+	Path  string
+	Value any
+}
+
+// EvaluateFlat evaluates the compiled JsonPath expression get operation on the given value and returns each matching
+// value followed by all its descendants (array items included), each of them with its RFC 9535 normalized path, e.g.
+// $ flattens the whole document. Containers are returned before their children. The root value itself ($) is only
+// returned when the FlatRootEntry option is used.
+func (p *Path) EvaluateFlat(value any) []FlatValue {
+	// result
+	result := []FlatValue{}
+	// loop matching locations
+	for _, l := range locate(p, value) {
+		// matching value and its descendants
+		it := FromValues(false, l).RecurseValues()
+		// loop values
+		for v, ok := it(); ok; v, ok = it() {
+			// all values are located, the root value is skipped unless requested
+			if _, loc := unwrap(v); loc != nil && (loc.parent != nil || p.flatRootEntry) {
+				// append value
+				result = append(result, FlatValue{
+					Path:  loc.normalizedPath(),
+					Value: loc.matchedValue(),
+				})
+			}
+		}
+	}
+	return result
+}
+
+// Group holds the values matched by EvaluateGrouped in the same array or object. Parent is nil for the root value.
+type Group struct {
+	Parent  any
+	Matches []any
+}
+
+// EvaluateGrouped evaluates the compiled JsonPath expression get operation on the given value and returns the matching
+// values grouped by the array or object containing them, e.g. $..book[?(@.isbn)] returns a group per store. Parents
+// are compared by identity (the same map or the same slice) and groups are returned in order of their first match.
+func (p *Path) EvaluateGrouped(value any) []Group {
+	// result
+	result := []Group{}
+	// group index by parent identity
+	groups := map[any]int{}
+	// loop matching locations
+	for _, l := range locate(p, value) {
+		// parent identity
+		id := l.parentIdentity()
+		// find group
+		i, ok := groups[id]
+		if !ok {
+			// new group
+			i = len(result)
+			groups[id] = i
+			result = append(result, Group{Parent: l.parentValue()})
+		}
+		// append match
+		result[i].Matches = append(result[i].Matches, l.value)
+	}
+	return result
+}
+
+// ValueWithSiblings is a value matched by EvaluateWithSiblings together with the other values of the array or object
+// containing it. Siblings is nil for the root value.
+type ValueWithSiblings struct {
+	Value    any
+	Siblings []any
+}
+
+// EvaluateWithSiblings evaluates the compiled JsonPath expression get operation on the given value and returns each
+// matching value together with its siblings, i.e. the other items of the same array or the other members of the same
+// object, e.g. $..book[?(@.featured)] returns each featured book with the other books of its store. Siblings exclude
+// the match itself and are listed in the order wildcards enumerate them (document order for arrays and Map objects).
+func (p *Path) EvaluateWithSiblings(value any) []ValueWithSiblings {
+	// result
+	result := []ValueWithSiblings{}
+	// loop matching locations
+	for _, l := range locate(p, value) {
+		// append match
+		result = append(result, ValueWithSiblings{
+			Value:    l.value,
+			Siblings: l.siblings(),
+		})
+	}
+	return result
+}
+
+// Match is a value matched by EvaluateMatches together with its location in the document. Missing is true for the
+// null values returned for missing leaves (see ReturnNullForMissingLeaf), which are not part of the document. Path is
+// empty for the values that have no location in the document (decoded by pipe decoders or computed by count()).
+type Match struct {
+	Value   any
+	Path    string
+	Missing bool
+}
+
+// EvaluateMatches evaluates the compiled JsonPath expression get operation on the given value and returns each
+// matching value together with its RFC 9535 normalized path, telling the null values of missing leaves apart from
+// the null values found in the document, e.g. so that they are not written back. The values are the ones returned by
+// Evaluate, e.g. member names for $.*~.
+func (p *Path) EvaluateMatches(value any) []Match {
+	// result
+	result := []Match{}
+	// evaluate path on located root value
+	it := p.expression(locateOperation, &located{value: value}, value)
+	// loop matching values
+	for v, ok := it(); ok; v, ok = it() {
+		// check location (decoded values and counts have none)
+		_, l := unwrap(v)
+		if l == nil {
+			// append match without path
+			result = append(result, Match{Value: v})
+			continue
+		}
+		// append match
+		result = append(result, Match{
+			Value:   l.value,
+			Path:    l.normalizedPath(),
+			Missing: l.missing,
+		})
+	}
+	return result
+}
+
+// EvaluatePartial evaluates the compiled JsonPath expression get operation on the given value like Evaluate and also
+// returns the longest prefix of the expression matching at least one value, which tells how far the expression got
+// when nothing matches, e.g. $.a.b.c returns $.a.b when b has no c child. Prefixes end at segment boundaries (see
+// StepPath) and are relative to $, so $ is returned when the first segment does not match. The expression itself is
+// returned when it matches values.
+func (p *Path) EvaluatePartial(value any) ([]any, string) {
+	// evaluate path
+	results := p.Evaluate(value)
+	// check results
+	if len(results) > 0 {
+		return results, p.source
+	}
+	// segments
+	segments := pathSegments(lex(p.source))
+	// deepest prefix matching values, the root always matches
+	deepest := root
+	// loop prefixes (the whole expression did not match)
+	for i := 1; i < len(segments); i++ {
+		// prefix expression
+		prefix := root + strings.Join(segments[:i], "")
+		// evaluate prefix
+		path, err := NewPath(prefix, p.options...)
+		if err != nil || len(path.Evaluate(value)) == 0 {
+			break
+		}
+		deepest = prefix
+	}
+	return results, deepest
+}
+
+// TypedMatch is a value matched by EvaluateTyped together with its Go type, as formatted by fmt.Sprintf("%T").
+type TypedMatch struct {
+	Value  any
+	GoType string
+}
+
+// EvaluateTyped evaluates the compiled JsonPath expression get operation on the given value and returns each matching
+// value together with its Go type, e.g. float64 or json.Number, which helps debugging unexpected results.
+func (p *Path) EvaluateTyped(value any) []TypedMatch {
+	// matches
+	values := p.Evaluate(value)
+	// result
+	result := make([]TypedMatch, 0, len(values))
+	// loop matches
+	for _, v := range values {
+		// append typed match
+		result = append(result, TypedMatch{
+			Value:  v,
+			GoType: fmt.Sprintf("%T", v),
+		})
+	}
+	return result
+}
+
+// TypeStats evaluates the compiled JsonPath expression get operation on the given value and returns the number of
+// matching values of each Go type, e.g. map[string]int{"float64": 2, "int": 1}. Null values are counted as <nil>.
+func (p *Path) TypeStats(value any) map[string]int {
+	// stats
+	stats := map[string]int{}
+	// loop typed matches
+	for _, m := range p.EvaluateTyped(value) {
+		// count match
+		stats[m.GoType]++
+	}
+	return stats
+}
+
+// DocumentValue is a value matched by EvaluateAll together with the index of the document it was found in.
+type DocumentValue struct {
+	Doc   int
+	Value any
+}
+
+// EvaluateAll evaluates the compiled JsonPath expression get operation on each of the given documents (e.g. the
+// lines of a NDJSON stream). Values are grouped by document, in input order, and tagged with the document index.
+func (p *Path) EvaluateAll(documents []any) []DocumentValue {
+	// result
+	result := []DocumentValue{}
+	// loop documents
+	for doc, document := range documents {
+		// evaluate path
+		it := p.expression(getOperation, document, document)
+		// loop iterator
+		for v, ok := it(); ok; v, ok = it() {
+			// append tagged value
+			result = append(result, DocumentValue{
+				Doc:   doc,
+				Value: v,
+			})
+		}
+	}
+	return result
+}
+
+// EvaluateChan evaluates the compiled JsonPath expression get operation on the given value and sends the matching
+// values to the returned channel, which is closed when evaluation completes. Values are produced as they are consumed.
+// If the context is cancelled, evaluation stops, no further values are sent and the context error is sent to the error
+// channel.
+func (p *Path) EvaluateChan(ctx context.Context, value any) (<-chan any, <-chan error) {
+	// channels
+	values := make(chan any)
+	errs := make(chan error, 1)
+	// evaluate path
+	go func() {
+		// close channels on exit
+		defer close(errs)
+		defer close(values)
+		// evaluate path
+		it := p.expression(getOperation, value, value)
+		// loop iterator
+		for {
+			// stop on context cancellation (a ready receiver must not win over a cancelled context)
+			if err := ctx.Err(); err != nil {
+				errs <- err
+				return
+			}
+			// next value
+			v, ok := it()
+			if !ok {
+				return
+			}
+			// send value or stop on context cancellation
+			select {
+			case values <- v:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+	return values, errs
+}
+
 func new(expression pathExpression) *Path {
 	// create path
 	return &Path{
-		expression: expression,
+		expression: checkReadOnly(expression),
 		terminal:   false,
 	}
 }
@@ -72,14 +622,37 @@ func new(expression pathExpression) *Path {
 func terminal(expression pathExpression) *Path {
 	// create path
 	return &Path{
-		expression: expression,
+		expression: checkReadOnly(expression),
 		terminal:   true,
 	}
 }
 
 func createPath(ctx *pathContext, lexer *lexer) (*Path, error) {
+	// create path step
+	path, err := createPathStep(ctx, lexer)
+	if err != nil {
+		return nil, err
+	}
+	// check budget
+	if ctx.budget != nil {
+		// count node visits
+		path = ctx.budget.limit(path)
+	}
+	// check deadline
+	if ctx.deadline != nil {
+		// stop evaluation once the deadline expires
+		path = ctx.deadline.limit(path)
+	}
+	return path, nil
+}
+
+func createPathStep(ctx *pathContext, lexer *lexer) (*Path, error) {
 	// get next token from lexer
 	token := lexer.nextLexeme()
+	// check child names
+	if err := ctx.checkKnownKeys(token); err != nil {
+		return nil, err
+	}
 
 	// process token
 	switch token.typ {
@@ -99,7 +672,7 @@ func createPath(ctx *pathContext, lexer *lexer) (*Path, error) {
 		// create path expression
 		exp := func(operation operation, value, root any) Iterator {
 			// return iterator
-			return compose(operation, FromValues(false, value), subPath, root)
+			return subPath.expression(operation, value, root)
 		}
 		// create path
 		return new(exp), nil
@@ -118,12 +691,14 @@ func createPath(ctx *pathContext, lexer *lexer) (*Path, error) {
 		switch childName {
 
 		case "*":
+			// children path, created once since evaluations must not modify the context
+			children := allChildrenThen(ctx, subPath)
 			// includes all values, not just mapping ones
 			exp := func(operation operation, value, root any) Iterator {
 				// recursive iterator
-				it := FromValues(false, value).RecurseValues()
+				it := ctx.recurse(FromValues(false, value))
 				// compose iterator
-				return compose(operation, it, allChildrenThen(ctx, subPath), root)
+				return compose(operation, it, children, root)
 			}
 			return new(exp), nil
 
@@ -131,19 +706,46 @@ func createPath(ctx *pathContext, lexer *lexer) (*Path, error) {
 			// include all values
 			exp := func(operation operation, value, root any) Iterator {
 				// recursive iterator
-				it := FromValues(false, value).RecurseValues()
+				it := ctx.recurse(FromValues(false, value))
 				// compose iterator
 				return compose(operation, it, subPath, root)
 			}
 			return new(exp), nil
 
+		case "*" + propertyName:
+			// property names path, created once since evaluations must not modify the context
+			names := propertyNameArraySubscriptThen(ctx, "*", subPath, true, true)
+			// property names of all objects
+			exp := func(operation operation, value, root any) Iterator {
+				// recursive iterator
+				it := ctx.recurse(FromValues(false, value))
+				// compose iterator
+				return compose(operation, it, names, root)
+			}
+			return new(exp), nil
+
 		default:
+			// check property name
+			if childName, ok := trimPropertyName(childName); ok {
+				// property name path
+				name := propertyNameChildThen(childName, subPath, true)
+				// property name of the members with the given name
+				exp := func(operation operation, value, root any) Iterator {
+					// recursive iterator
+					it := ctx.recurse(FromValues(false, value))
+					// compose iterator
+					return compose(operation, it, name, root)
+				}
+				return new(exp), nil
+			}
+			// child path, created once since evaluations must not modify the context
+			child := childThen(ctx, childName, subPath, true)
 			// include all values
 			exp := func(operation operation, value, root any) Iterator {
 				// recursive iterator
-				it := FromValues(false, value).RecurseValues()
+				it := ctx.recurse(FromValues(false, value))
 				// compose iterator
-				return compose(operation, it, childThen(ctx, childName, subPath, true), root)
+				return compose(operation, it, child, root)
 			}
 			return new(exp), nil
 		}
@@ -181,6 +783,17 @@ func createPath(ctx *pathContext, lexer *lexer) (*Path, error) {
 		// []
 		return bracketChildThen(ctx, childNames, subPath, false), nil
 
+	case lexemeBracketUnion:
+		// create sub path
+		subPath, err := createPath(ctx, lexer)
+		if err != nil {
+			return nil, err
+		}
+		// remove [] from token value
+		members := strings.TrimSuffix(strings.TrimPrefix(token.val, "["), "]")
+		// ['a', 0]
+		return bracketUnionThen(ctx, members, subPath, false), nil
+
 	case lexemeArraySubscript:
 		// create sub path
 		subPath, err := createPath(ctx, lexer)
@@ -227,8 +840,23 @@ func createPath(ctx *pathContext, lexer *lexer) (*Path, error) {
 				// should never happen as lexer should have detected an error
 				return nil, errors.New("missing end of filter")
 			}
+			// check child names in filter subpaths
+			if err := ctx.checkKnownKeys(lx); err != nil {
+				return nil, err
+			}
+			// check filter refers to the container of the container or the index of the values being filtered
+			if (lx.typ == lexemeFilterGrandparent || lx.typ == lexemeFilterIndex) && filterNestingLevel == 1 {
+				// locations are needed to find it
+				ctx.locatesContainers = true
+			}
 			filterLexemes = append(filterLexemes, lx)
 		}
+		// parse filter
+		filterNode := newFilterNode(filterLexemes)
+		// validate filter
+		if err := filterNode.validate(); err != nil {
+			return nil, err
+		}
 		// create sub path expression
 		subPath, err := createPath(ctx, lexer)
 		if err != nil {
@@ -236,9 +864,33 @@ func createPath(ctx *pathContext, lexer *lexer) (*Path, error) {
 		}
 		// create recursive filter expression
 		if recursive {
-			return recursiveFilterThen(filterLexemes, subPath, false), nil
+			return recursiveFilterThen(ctx, filterNode, subPath, false), nil
 		}
-		return filterThen(filterLexemes, subPath, false), nil
+		return filterThen(ctx, filterNode, subPath, false), nil
+
+	case lexemeCount:
+		// the values selected so far are counted once the path is created
+		ctx.countsResult = true
+		// create sub path
+		return createPath(ctx, lexer)
+
+	case lexemePipeDecoder:
+		// decoded values are not part of the input data
+		ctx.decodesValues = true
+		// decoder name (remove '|')
+		name := strings.TrimPrefix(token.val, pipe)
+		// find decoder
+		decoder, ok := lookupDecoder(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown decoder %q", name)
+		}
+		// create sub path
+		subPath, err := createPath(ctx, lexer)
+		if err != nil {
+			return nil, err
+		}
+		// |name
+		return decoderThen(decoder, subPath), nil
 
 	case lexemePropertyName:
 		// create sub path
@@ -250,6 +902,11 @@ func createPath(ctx *pathContext, lexer *lexer) (*Path, error) {
 		childName := strings.TrimPrefix(token.val, ".")
 		// remove '~' from child name
 		childName = strings.TrimSuffix(childName, propertyName)
+		// check wildcard
+		if childName == "*" {
+			// property names of all members
+			return propertyNameArraySubscriptThen(ctx, childName, subPath, false, true), nil
+		}
 		// process property name
 		return propertyNameChildThen(childName, subPath, false), nil
 
@@ -270,6 +927,17 @@ func createPath(ctx *pathContext, lexer *lexer) (*Path, error) {
 		// process property name
 		return propertyNameBracketChildThen(ctx, childNames, subPath, false), nil
 
+	case lexemeFilterPropertyName:
+		// create sub path
+		subPath, err := createPath(ctx, lexer)
+		if err != nil {
+			return nil, err
+		}
+		// property names of the filtered values are known from their locations
+		ctx.locatesContainers = true
+		// ~ following a filter
+		return locationNameThen(subPath), nil
+
 	case lexemeArraySubscriptPropertyName:
 		// create sub path
 		subPath, err := createPath(ctx, lexer)
@@ -279,31 +947,57 @@ func createPath(ctx *pathContext, lexer *lexer) (*Path, error) {
 		// trim '[' and ']~' from token value
 		subscript := strings.TrimSuffix(strings.TrimPrefix(token.val, "["), "]~")
 		// process property name
-		return propertyNameArraySubscriptThen(ctx, subscript, subPath, false), nil
+		return propertyNameArraySubscriptThen(ctx, subscript, subPath, false, false), nil
 	}
 	return nil, errors.New("invalid path expression")
 }
 
 func identity(operation operation, value any, root any) Iterator {
 	// return iterator
-	return FromValues(false, value)
+	return fromValue(value)
 }
 
 func empty(operation operation, value any, root any) Iterator {
-	// emoty iterator
-	return FromValues(false)
+	// empty iterator
+	return none
 }
 
 // evaluate path expression for all values in iterator
 func compose(operation operation, it Iterator, path *Path, root any) Iterator {
-	// iterator slice
-	its := []Iterator{}
-	// iterate
-	for v, ok := it(); ok; v, ok = it() {
-		// append
-		its = append(its, path.expression(operation, v, root))
+	// process operation
+	switch operation {
+
+	case setOperation, deleteOperation:
+		// iterator slice, all expressions are collected before the data is modified
+		its := []Iterator{}
+		// iterate
+		for v, ok := it(); ok; v, ok = it() {
+			// append
+			its = append(its, path.expression(operation, v, root))
+		}
+		return FromIterators(its...)
+
+	default:
+		// current iterator
+		var current Iterator = none
+		// evaluate path expression lazily, pulling values from the current iterator
+		return func() (any, bool) {
+			for {
+				// next value in current iterator
+				if value, ok := current(); ok {
+					return value, true
+				}
+				// next value in source iterator
+				v, ok := it()
+				if !ok {
+					// exit
+					return nil, false
+				}
+				// evaluate path expression on value
+				current = path.expression(operation, v, root)
+			}
+		}
 	}
-	return FromIterators(its...)
 }
 
 func propertyNameChildThen(childName string, path *Path, recursive bool) *Path {
@@ -311,6 +1005,8 @@ func propertyNameChildThen(childName string, path *Path, recursive bool) *Path {
 	childName = unescape(childName)
 	// create path expression
 	return new(func(operation operation, value, root any) Iterator {
+		// unwrap located value
+		value, loc := unwrap(value)
 		// check value type (must be an object)
 		switch o := value.(type) {
 
@@ -318,17 +1014,70 @@ func propertyNameChildThen(childName string, path *Path, recursive bool) *Path {
 			// find key in map
 			if _, ok := o[childName]; ok {
 				// return iterator
-				return compose(operation, FromValues(false, childName), path, root)
+				return path.expression(operation, loc.property(childName), root)
 			}
 
 		case Map:
 			// evaluate path expression on each key
-			return compose(operation, o.Keys(childName), path, root)
+			return compose(operation, loc.mapKeys(o, childName), path, root)
 		}
 		return empty(operation, value, root)
 	})
 }
 
+func bracketUnionThen(ctx *pathContext, members string, path *Path, recursive bool) *Path {
+	// expression is not definite
+	ctx.definite = false
+	// union member
+	type member struct {
+		index bool
+		path  *Path
+	}
+	// "['a', 0]" => ['a', 0]
+	union := []member{}
+	// loop members
+	for _, m := range bracketMembers(members) {
+		// check member is a quoted name
+		if strings.HasPrefix(m, "'") || strings.HasPrefix(m, `"`) {
+			// object key
+			union = append(union, member{index: false, path: bracketChildThen(ctx, m, path, recursive)})
+		} else {
+			// array index
+			union = append(union, member{index: true, path: arraySubscriptThen(ctx, m, path, recursive)})
+		}
+	}
+	// create path expression
+	return new(func(operation operation, value, root any) Iterator {
+		// unwrap located value
+		raw, _ := unwrap(value)
+		// members to evaluate
+		var index bool
+		// process value type, names select object keys and integers select array indexes
+		switch raw.(type) {
+
+		case []any, Array:
+			index = true
+
+		case map[string]any, Map:
+			index = false
+
+		default:
+			return empty(operation, value, root)
+		}
+		// iterators
+		its := make([]Iterator, 0, len(union))
+		// loop members
+		for _, m := range union {
+			// check member applies to value
+			if m.index == index {
+				// evaluate member on value
+				its = append(its, m.path.expression(operation, value, root))
+			}
+		}
+		return FromIterators(its...)
+	})
+}
+
 func propertyNameBracketChildThen(ctx *pathContext, childNames string, path *Path, recursive bool) *Path {
 	// "[\"a\", \"b\", \"c\"]" => ["a", "b", "c"]
 	unquotedChildren := bracketChildNames(childNames)
@@ -339,6 +1088,8 @@ func propertyNameBracketChildThen(ctx *pathContext, childNames string, path *Pat
 	}
 	// create path expression
 	return new(func(operation operation, value, root any) Iterator {
+		// unwrap located value
+		value, loc := unwrap(value)
 		// check value type (only objects are allowed)
 		switch o := value.(type) {
 
@@ -350,7 +1101,7 @@ func propertyNameBracketChildThen(ctx *pathContext, childNames string, path *Pat
 				// find key in map
 				if _, ok := o[childName]; ok {
 					// append key to iterators
-					its = append(its, FromValues(false, childName))
+					its = append(its, FromValues(false, loc.property(childName)))
 				}
 			}
 			// evaluate path on keys
@@ -360,7 +1111,7 @@ func propertyNameBracketChildThen(ctx *pathContext, childNames string, path *Pat
 			// check we have keys to evaluate
 			if len(unquotedChildren) > 0 {
 				// evaluate path expression on keys
-				return compose(operation, o.Keys(unquotedChildren...), path, root)
+				return compose(operation, loc.mapKeys(o, unquotedChildren...), path, root)
 			}
 			return empty(operation, value, root)
 		}
@@ -378,6 +1129,8 @@ func bracketChildThen(ctx *pathContext, childNames string, path *Path, recursive
 	}
 	// iterator
 	return new(func(operation operation, value, root any) Iterator {
+		// unwrap located value
+		value, loc := unwrap(value)
 		// process value type (it must be an object)
 		switch v := value.(type) {
 
@@ -400,7 +1153,7 @@ func bracketChildThen(ctx *pathContext, childNames string, path *Path, recursive
 							v[key] = value
 						}
 						// append iterator
-						expressions = append(expressions, f)
+						expressions = append(expressions, loc.member(key, f))
 					}
 					return FromValues(false, expressions...)
 
@@ -431,7 +1184,7 @@ func bracketChildThen(ctx *pathContext, childNames string, path *Path, recursive
 				// find child in map
 				if mv, ok := v[childName]; ok {
 					// append
-					its = append(its, FromValues(false, mv))
+					its = append(its, FromValues(false, loc.member(childName, mv)))
 				}
 			}
 			return compose(operation, FromIterators(its...), path, root)
@@ -455,7 +1208,7 @@ func bracketChildThen(ctx *pathContext, childNames string, path *Path, recursive
 							v.Set(key, value)
 						}
 						// append iterator
-						expressions = append(expressions, f)
+						expressions = append(expressions, loc.member(key, f))
 					}
 					return FromValues(false, expressions...)
 
@@ -482,7 +1235,7 @@ func bracketChildThen(ctx *pathContext, childNames string, path *Path, recursive
 			// check we have keys to evaluate
 			if len(unquotedChildren) > 0 {
 				// evaluate path expression on values @ keys
-				return compose(operation, v.Values(unquotedChildren...), path, root)
+				return compose(operation, loc.mapValues(v, unquotedChildren...), path, root)
 			}
 			return empty(operation, value, root)
 		}
@@ -492,100 +1245,105 @@ func bracketChildThen(ctx *pathContext, childNames string, path *Path, recursive
 }
 
 func bracketChildNames(childNames string) []string {
-	// split names "[\"a\", \"b\", \"c\"]"
-	tokens := strings.Split(childNames, ",")
-	// reconstitute child names with embedded commas
-	children := []string{}
-	accum := ""
-	// loop tokens
-	for _, token := range tokens {
-		// check for balanced quotes "' ... '" or `" ... "`
-		if balanced(token, '\'') && balanced(token, '"') {
-			// check we are accumulating
-			if accum != "" {
-				// append current
-				accum += "," + token
-			} else {
-				// append token to result
-				children = append(children, token)
-				// reset accumulator
-				accum = ""
-			}
-		} else {
-			// accumulate
-			if accum == "" {
-				// initialize accumulator
-				accum = token
-			} else {
-				// append to accumulator
-				accum += "," + token
-				// append accumulated value to result
-				children = append(children, accum)
-				// reset accumulator
-				accum = ""
-			}
-		}
-	}
-	// check for accumulated value
-	if accum != "" {
-		// append last accumulated value
-		children = append(children, accum)
-	}
-	// unquote children
-	result := []string{}
-	for _, token := range children {
-		// trim
-		token = strings.TrimSpace(token)
-		// check for single or double quotes
-		if strings.HasPrefix(token, "'") {
-			// remove outer quotes
-			token = strings.TrimSuffix(strings.TrimPrefix(token, "'"), "'")
-		} else {
-			// remove outer quotes
-			token = strings.TrimSuffix(strings.TrimPrefix(token, `"`), `"`)
-		}
-		// process scaped characters
-		token = unescape(token)
-		// append to result
-		result = append(result, token)
+	// split members
+	result := bracketMembers(childNames)
+	// loop members
+	for i, member := range result {
+		// unquote member
+		result[i] = unquoteChildName(member)
 	}
 	return result
 }
 
-// checks whether a string is balanced with respect to a given quote character
-func balanced(token string, q rune) bool {
-	// flags
-	balanced := true
-	prev := eof
-	// loop over bytes
-	for i := 0; i < len(token); {
-		// rune @ i
-		rune, width := utf8.DecodeRuneInString(token[i:])
-		// advance []byte index by rune width
-		i += width
-		// check rune is the quote character
-		if rune == q {
-			// verify it is escaped "a\"b"
-			if i > 0 && prev == '\\' {
-				// reset prev
-				prev = rune
-				// not the final quote
-				continue
-			}
-			// toggle balanced
-			balanced = !balanced
+// bracketMembers splits the members of a bracket union, members are separated by commas outside quotes,
+// e.g. "'a', 'b,c', 0" => ["'a'", "'b,c'", "0"]
+func bracketMembers(members string) []string {
+	// result
+	result := make([]string, 0, strings.Count(members, ",")+1)
+	// start of current member
+	start := 0
+	// quote character of the current quoted text (zero outside quotes)
+	var quote rune
+	// escaped character flag
+	escaped := false
+	// loop over runes
+	for i, r := range members {
+		switch {
+
+		case escaped:
+			// reset flag
+			escaped = false
+
+		case r == '\\':
+			// next character is escaped
+			escaped = true
+
+		case quote == 0 && r == ',':
+			// append current member to result
+			result = append(result, strings.TrimSpace(members[start:i]))
+			// next member starts after separator
+			start = i + 1
+
+		case quote == 0 && (r == '\'' || r == '"'):
+			// start of quoted text
+			quote = r
+
+		case r == quote:
+			// end of quoted text
+			quote = 0
 		}
-		prev = rune
 	}
-	return balanced
+	// append last member
+	return append(result, strings.TrimSpace(members[start:]))
+}
+
+// trimPropertyName removes the property name operator (~) at the end of the child name unless it is escaped, it
+// reports whether the operator was removed
+func trimPropertyName(childName string) (string, bool) {
+	// check the ~ is not escaped
+	if strings.HasSuffix(childName, propertyName) && !strings.HasSuffix(childName, `\`+propertyName) {
+		return strings.TrimSuffix(childName, propertyName), true
+	}
+	return childName, false
+}
+
+// unquoteChildName removes the whitespace and quotes around a child name and processes escaped characters
+func unquoteChildName(token string) string {
+	// trim
+	token = strings.TrimSpace(token)
+	// check for single or double quotes
+	if strings.HasPrefix(token, "'") {
+		// remove outer quotes
+		token = strings.TrimSuffix(strings.TrimPrefix(token, "'"), "'")
+	} else {
+		// remove outer quotes
+		token = strings.TrimSuffix(strings.TrimPrefix(token, `"`), `"`)
+	}
+	// process escaped characters
+	return unescape(token)
 }
 
 func unescape(raw string) string {
+	// check there are escaped characters
+	if !strings.Contains(raw, `\`) {
+		return raw
+	}
 	// escaped characters flags
-	esc := ""
+	var esc strings.Builder
 	escaped := false
 	// loop over runes
 	for i := 0; i < len(raw); {
+		// check unicode escape sequence (validated by the lexer)
+		if !escaped {
+			if r, width, ok := unescapeUnicode(raw[i:]); ok {
+				// append code point
+				esc.WriteRune(r)
+				// advance index
+				i += width
+				// next
+				continue
+			}
+		}
 		// run @ i
 		rune, width := utf8.DecodeRuneInString(raw[i:])
 		// advance index
@@ -595,7 +1353,7 @@ func unescape(raw string) string {
 			// check current text is escaped
 			if escaped {
 				// append rune
-				esc += string(rune)
+				esc.WriteRune(rune)
 			}
 			// toggle escaped
 			escaped = !escaped
@@ -605,14 +1363,108 @@ func unescape(raw string) string {
 		// reset
 		escaped = false
 		// append escaped rune
-		esc += string(rune)
+		esc.WriteRune(rune)
+	}
+	return esc.String()
+}
+
+// unescapeUnicode decodes the \uXXXX escape sequence (or surrogate pair of sequences) at the start of the string and
+// returns the code point and the number of bytes of the sequence
+func unescapeUnicode(s string) (rune, int, bool) {
+	// code unit
+	r, ok := unicodeCodeUnit(s)
+	if !ok {
+		return 0, 0, false
+	}
+	// check surrogate pair
+	if utf16.IsSurrogate(r) {
+		// low surrogate
+		low, ok := unicodeCodeUnit(s[6:])
+		if !ok {
+			return 0, 0, false
+		}
+		// decode pair
+		r = utf16.DecodeRune(r, low)
+		if r == unicode.ReplacementChar {
+			return 0, 0, false
+		}
+		return r, 12, true
 	}
-	return esc
+	return r, 6, true
+}
+
+// unicodeCodeUnit decodes the UTF-16 code unit of the \uXXXX escape sequence at the start of the string
+func unicodeCodeUnit(s string) (rune, bool) {
+	// check escape sequence
+	if len(s) < 6 || !strings.HasPrefix(s, unicodeEscape) {
+		return 0, false
+	}
+	// parse hexadecimal digits
+	u, err := strconv.ParseUint(s[2:6], 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	return rune(u), true
+}
+
+// decoderThen evaluates the path on the decoded value, values that cannot be decoded are skipped
+func decoderThen(decoder Decoder, path *Path) *Path {
+	// create path expression
+	return new(func(operation operation, value, root any) Iterator {
+		// process operation
+		switch operation {
+
+		case setOperation, deleteOperation:
+			// decoded values are not part of the input data
+			return empty(operation, value, root)
+		}
+		// unwrap located value (decoded values have no location in the input data)
+		value, _ = unwrap(value)
+		// decode value
+		decoded, err := decoder(value)
+		if err != nil {
+			return empty(operation, value, root)
+		}
+		return path.expression(operation, decoded, root)
+	})
+}
+
+// objectChildrenThen is like allChildrenThen but arrays are not matched
+func objectChildrenThen(ctx *pathContext, path *Path) *Path {
+	// all children
+	all := allChildrenThen(ctx, path)
+	// create path expression
+	return new(func(operation operation, value, root any) Iterator {
+		// unwrap located value
+		raw, _ := unwrap(value)
+		// process value type
+		switch raw.(type) {
+
+		case []any, Array:
+			return empty(operation, value, root)
+		}
+		return all.expression(operation, value, root)
+	})
 }
 
 func allChildrenThen(ctx *pathContext, path *Path) *Path {
+	// array items without null values
+	var nonNullItems *Path
+	if ctx.skipNullElements {
+		nonNullItems = arraySubscriptThen(ctx, "*", path, false)
+	}
 	// create path expression
 	return new(func(operation operation, value, root any) Iterator {
+		// check null array items are skipped
+		if nonNullItems != nil {
+			// process value type
+			switch original(value).(type) {
+			case []any, Array:
+				return nonNullItems.expression(operation, value, root)
+			}
+		}
+		// unwrap located value
+		value, loc := unwrap(value)
 		// process value type
 		switch v := value.(type) {
 
@@ -633,7 +1485,7 @@ func allChildrenThen(ctx *pathContext, path *Path) *Path {
 							v[k] = value
 						}
 						// append iterator
-						expressions = append(expressions, f)
+						expressions = append(expressions, loc.member(k, f))
 					})
 					return FromValues(false, expressions...)
 
@@ -658,9 +1510,9 @@ func allChildrenThen(ctx *pathContext, path *Path) *Path {
 			// iterators
 			its := make([]Iterator, 0, len(v))
 			// iterate map
-			loopMap(v, func(_ string, mv any) {
+			loopMap(v, func(k string, mv any) {
 				// append iterator
-				its = append(its, compose(operation, FromValues(false, mv), path, root))
+				its = append(its, path.expression(operation, loc.member(k, mv), root))
 			})
 			return FromIterators(its...)
 
@@ -685,7 +1537,7 @@ func allChildrenThen(ctx *pathContext, path *Path) *Path {
 							v[index] = value
 						}
 						// append iterator
-						expressions = append(expressions, f)
+						expressions = append(expressions, loc.index(index, f))
 					}
 					return FromValues(false, expressions...)
 
@@ -707,8 +1559,12 @@ func allChildrenThen(ctx *pathContext, path *Path) *Path {
 					return FromValues(false, expressions...)
 				}
 			}
+			// check the array items are the result
+			if ctx.itemsAreResult(operation, path, loc) {
+				return FromValues(false, v...)
+			}
 			// evaluate path on array items
-			return compose(operation, FromValues(false, v...), path, root)
+			return compose(operation, FromValues(false, loc.items(v)...), path, root)
 
 		case Map:
 			// check path is terminal
@@ -731,7 +1587,7 @@ func allChildrenThen(ctx *pathContext, path *Path) *Path {
 							v.Set(key, value)
 						}
 						// append iterator
-						expressions = append(expressions, f)
+						expressions = append(expressions, loc.member(key, f))
 					}
 					return FromValues(false, expressions...)
 
@@ -758,7 +1614,7 @@ func allChildrenThen(ctx *pathContext, path *Path) *Path {
 				}
 			}
 			// evaluate path expression on each value
-			return compose(operation, v.Values(), path, root)
+			return compose(operation, loc.mapValues(v), path, root)
 
 		case Array:
 			// check path is terminal
@@ -781,7 +1637,7 @@ func allChildrenThen(ctx *pathContext, path *Path) *Path {
 							v.Set(index, value)
 						}
 						// append iterator
-						expressions = append(expressions, f)
+						expressions = append(expressions, loc.index(index, f))
 					}
 					return FromValues(false, expressions...)
 
@@ -804,7 +1660,7 @@ func allChildrenThen(ctx *pathContext, path *Path) *Path {
 				}
 			}
 			// evaluate path on array items
-			return compose(operation, v.Values(false), path, root)
+			return compose(operation, loc.arrayValues(v), path, root)
 
 		default:
 			// empty
@@ -813,6 +1669,20 @@ func allChildrenThen(ctx *pathContext, path *Path) *Path {
 	})
 }
 
+// nonNullIndexes returns the indexes of the array items which are not null
+func nonNullIndexes(indexes []int, item func(int) any) []int {
+	// indexes of non null items
+	result := make([]int, 0, len(indexes))
+	// loop indexes
+	for _, i := range indexes {
+		// check item
+		if item(i) != nil {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
 func arraySubscriptThen(ctx *pathContext, subscript string, path *Path, recursive bool) *Path {
 	// check for wildcard, union or range
 	if subscript == "*" || strings.Contains(subscript, ",") || strings.Contains(subscript, ":") {
@@ -821,6 +1691,8 @@ func arraySubscriptThen(ctx *pathContext, subscript string, path *Path, recursiv
 	}
 	// create path expression
 	return new(func(operation operation, value, root any) Iterator {
+		// unwrap located value
+		value, loc := unwrap(value)
 		// check wildcard
 		if subscript == "*" {
 			// process value type
@@ -847,7 +1719,7 @@ func arraySubscriptThen(ctx *pathContext, subscript string, path *Path, recursiv
 								v[k] = value
 							}
 							// append iterator
-							expressions = append(expressions, f)
+							expressions = append(expressions, loc.member(k, f))
 						})
 						return FromValues(false, expressions...)
 
@@ -872,9 +1744,9 @@ func arraySubscriptThen(ctx *pathContext, subscript string, path *Path, recursiv
 				// iterators
 				its := make([]Iterator, 0, len(v))
 				// iterate map
-				loopMap(v, func(_ string, mv any) {
+				loopMap(v, func(k string, mv any) {
 					// append iterator
-					its = append(its, compose(operation, FromValues(false, mv), path, root))
+					its = append(its, path.expression(operation, loc.member(k, mv), root))
 				})
 				return FromIterators(its...)
 
@@ -899,7 +1771,7 @@ func arraySubscriptThen(ctx *pathContext, subscript string, path *Path, recursiv
 								v.Set(key, value)
 							}
 							// append iterator
-							expressions = append(expressions, f)
+							expressions = append(expressions, loc.member(key, f))
 						}
 						return FromValues(false, expressions...)
 
@@ -926,7 +1798,7 @@ func arraySubscriptThen(ctx *pathContext, subscript string, path *Path, recursiv
 					}
 				}
 				// evaluate path expression on each value
-				return compose(operation, v.Values(), path, root)
+				return compose(operation, loc.mapValues(v), path, root)
 
 			default:
 				// empty
@@ -937,11 +1809,21 @@ func arraySubscriptThen(ctx *pathContext, subscript string, path *Path, recursiv
 		switch v := value.(type) {
 
 		case []any:
+			// check the array items are the result of the wildcard
+			if subscript == "*" && !ctx.skipNullElements && ctx.itemsAreResult(operation, path, loc) {
+				return FromValues(false, v...)
+			}
 			// process subscript, returns possible array indexes
 			slice, err := slice(subscript, len(v))
 			if err != nil {
 				panic(err) // should not happen, lexer should have detected errors
 			}
+			// check the wildcard skips null items
+			if subscript == "*" && ctx.skipNullElements {
+				slice = nonNullIndexes(slice, func(i int) any {
+					return v[i]
+				})
+			}
 			// check path is terminal
 			if path.terminal {
 				// process operation
@@ -962,7 +1844,7 @@ func arraySubscriptThen(ctx *pathContext, subscript string, path *Path, recursiv
 								v[index] = value
 							}
 							// append index setter
-							expressions = append(expressions, f)
+							expressions = append(expressions, loc.index(index, f))
 						}
 					}
 					return FromValues(false, expressions...)
@@ -993,7 +1875,7 @@ func arraySubscriptThen(ctx *pathContext, subscript string, path *Path, recursiv
 				// check index
 				if i >= 0 && i < len(v) {
 					// evaluate path expression on value
-					its = append(its, compose(operation, FromValues(false, v[i]), path, root))
+					its = append(its, path.expression(operation, loc.index(i, v[i]), root))
 				}
 			}
 			return FromIterators(its...)
@@ -1004,6 +1886,14 @@ func arraySubscriptThen(ctx *pathContext, subscript string, path *Path, recursiv
 			if err != nil {
 				panic(err) // should not happen, lexer should have detected errors
 			}
+			// check the wildcard skips null items
+			if subscript == "*" && ctx.skipNullElements {
+				slice = nonNullIndexes(slice, func(i int) any {
+					// value @ i
+					item, _ := v.Values(false, i)()
+					return item
+				})
+			}
 			// check path is terminal
 			if path.terminal {
 				// process operation
@@ -1024,7 +1914,7 @@ func arraySubscriptThen(ctx *pathContext, subscript string, path *Path, recursiv
 								v.Set(index, value)
 							}
 							// append index setter
-							expressions = append(expressions, f)
+							expressions = append(expressions, loc.index(index, f))
 						}
 					}
 					return FromValues(false, expressions...)
@@ -1051,7 +1941,7 @@ func arraySubscriptThen(ctx *pathContext, subscript string, path *Path, recursiv
 			// check slice contain indexes
 			if len(slice) > 0 {
 				// evaluate path expression on values @ indexes
-				return compose(operation, v.Values(false, slice...), path, root)
+				return compose(operation, loc.arrayValues(v, slice...), path, root)
 			}
 			// empty
 			return empty(operation, value, root)
@@ -1061,24 +1951,27 @@ func arraySubscriptThen(ctx *pathContext, subscript string, path *Path, recursiv
 	})
 }
 
-func filterThen(filterLexemes []lexeme, path *Path, recursive bool) *Path {
-	// create filter from lexer tokens
-	filter := newFilter(newFilterNode(filterLexemes))
+func filterThen(ctx *pathContext, filterNode *filterNode, path *Path, recursive bool) *Path {
+	// create filter from parse tree
+	filter := newFilter(ctx, filterNode)
 	// create path expression
 	return new(func(operation operation, value, root any) Iterator {
-
+		// unwrap located value
+		raw, loc := unwrap(value)
 		// process value type
-		switch v := value.(type) {
+		switch v := raw.(type) {
 
 		case []any:
 			// iterators
 			its := make([]Iterator, 0, len(v))
 			// loop over array
-			for _, av := range v {
-				// evaluate filter on value
-				if filter(av, root) {
+			for i, av := range v {
+				// located value (if the array is located)
+				lv := loc.index(i, av)
+				// evaluate filter on value (the value and its container are located if the array is located)
+				if filter(lv, value, root) {
 					// evaluate path expression on value
-					its = append(its, compose(operation, FromValues(false, av), path, root))
+					its = append(its, path.expression(operation, lv, root))
 				}
 			}
 			return FromIterators(its...)
@@ -1087,29 +1980,31 @@ func filterThen(filterLexemes []lexeme, path *Path, recursive bool) *Path {
 			// iterators
 			its := make([]Iterator, 0, v.Len())
 			// iterator
-			it := v.Values(false)
+			it := loc.arrayValues(v)
 			// loop over iterator
 			for av, ok := it(); ok; av, ok = it() {
-				// evaluate filter on value
-				if filter(av, root) {
+				// evaluate filter on value (located if the array is located)
+				if filter(av, value, root) {
 					// evaluate path expression on value
-					its = append(its, compose(operation, FromValues(false, av), path, root))
+					its = append(its, path.expression(operation, av, root))
 				}
 			}
 			return FromIterators(its...)
 
 		default:
-			// evaluate filter on value
-			if filter(value, root) {
+			// evaluate filter on value, the container is known only if the value is located
+			if filter(value, loc.locatedContainer(), root) {
 				// evaluate path expression on value
-				return compose(operation, FromValues(false, value), path, root)
+				return path.expression(operation, value, root)
 			}
 		}
 		return empty(operation, value, root)
 	})
 }
 
-func propertyNameArraySubscriptThen(ctx *pathContext, subscript string, path *Path, recursive bool) *Path {
+// propertyNameArraySubscriptThen evaluates the path on the member names of objects and the indexes of array items
+// (unless objectsOnly is set) selected by the wildcard subscript
+func propertyNameArraySubscriptThen(ctx *pathContext, subscript string, path *Path, recursive, objectsOnly bool) *Path {
 	// check wildcard
 	if subscript == "*" {
 		// expression is not definite
@@ -1117,9 +2012,11 @@ func propertyNameArraySubscriptThen(ctx *pathContext, subscript string, path *Pa
 	}
 	// create path expression
 	return new(func(operation operation, value, root any) Iterator {
+		// unwrap located value
+		value, loc := unwrap(value)
 		// check wildcard
 		if subscript == "*" {
-			// process value type (only objects)
+			// process value type
 			switch v := value.(type) {
 
 			case map[string]any:
@@ -1128,22 +2025,82 @@ func propertyNameArraySubscriptThen(ctx *pathContext, subscript string, path *Pa
 				// loop over map keys
 				loopMap(v, func(k string, _ any) {
 					// append iterator
-					its = append(its, compose(operation, FromValues(false, k), path, root))
+					its = append(its, path.expression(operation, loc.property(k), root))
 				})
 				return FromIterators(its...)
 
 			case Map:
 				// evaluate path expression on each key
-				return compose(operation, v.Keys(), path, root)
+				return compose(operation, loc.mapKeys(v), path, root)
+
+			case []any:
+				// check array items are matched
+				if !objectsOnly {
+					// evaluate path expression on each index
+					return compose(operation, arrayIndexes(loc, len(v)), path, root)
+				}
+
+			case Array:
+				// check array items are matched
+				if !objectsOnly {
+					// evaluate path expression on each index
+					return compose(operation, arrayIndexes(loc, v.Len()), path, root)
+				}
 			}
 		}
 		return empty(operation, value, root)
 	})
 }
 
+// locationNameThen evaluates the path on the property name (or array index) of the value in its container, values
+// without a container (or which are property names themselves) have no name
+func locationNameThen(path *Path) *Path {
+	// create path expression
+	return new(func(operation operation, value, root any) Iterator {
+		// value location
+		_, loc := unwrap(value)
+		// check container
+		if loc == nil || loc.parent == nil || loc.name {
+			return empty(operation, value, root)
+		}
+		// process key type
+		switch k := loc.key.(type) {
+
+		case string:
+			// member name
+			return path.expression(operation, loc.parent.property(k), root)
+
+		case int:
+			// item index
+			return path.expression(operation, loc.parent.indexName(k), root)
+		}
+		return empty(operation, value, root)
+	})
+}
+
+// arrayIndexes returns an iterator over the indexes of an array with the given length, wrapped with their locations if
+// the array is located
+func arrayIndexes(loc *located, length int) Iterator {
+	// indexes
+	indexes := make([]any, 0, length)
+	// loop indexes
+	for i := 0; i < length; i++ {
+		// append index
+		indexes = append(indexes, loc.indexName(i))
+	}
+	return FromValues(false, indexes...)
+}
+
 func childThen(ctx *pathContext, childName string, path *Path, recursive bool) *Path {
 	// check child name
 	if childName == "*" {
+		// expression is not definite
+		ctx.definite = false
+		// check arrays are matched
+		if ctx.dotWildcardObjectsOnly {
+			// object members only
+			return objectChildrenThen(ctx, path)
+		}
 		// all
 		return allChildrenThen(ctx, path)
 	}
@@ -1151,9 +2108,15 @@ func childThen(ctx *pathContext, childName string, path *Path, recursive bool) *
 	childName = unescape(childName)
 	// return path
 	return new(func(operation operation, value, root any) Iterator {
+		// unwrap located value
+		value, loc := unwrap(value)
 
 		// evaluate array items
 		evaluateArrayItems := func(mv any) Iterator {
+			// located array
+			array := loc.member(childName, mv)
+			// array location
+			_, aloc := unwrap(array)
 			// process array items
 			switch v := mv.(type) {
 
@@ -1161,9 +2124,9 @@ func childThen(ctx *pathContext, childName string, path *Path, recursive bool) *
 				// iterators
 				its := make([]Iterator, 0, len(v)+1)
 				// evaluate path expression on array
-				its = append(its, compose(operation, FromValues(false, v), path, root))
+				its = append(its, path.expression(operation, array, root))
 				// evaluate path on slice items
-				its = append(its, compose(operation, FromValues(false, v...), path, root))
+				its = append(its, compose(operation, FromValues(false, aloc.items(v)...), path, root))
 				// combine iterators
 				return FromIterators(its...)
 
@@ -1171,15 +2134,15 @@ func childThen(ctx *pathContext, childName string, path *Path, recursive bool) *
 				// iterators
 				its := make([]Iterator, 0, v.Len()+1)
 				// evaluate path expression on array
-				its = append(its, compose(operation, FromValues(false, v), path, root))
+				its = append(its, path.expression(operation, array, root))
 				// evaluate path on array items
-				its = append(its, compose(operation, v.Values(false), path, root))
+				its = append(its, compose(operation, aloc.arrayValues(v), path, root))
 				// combine iterators
 				return FromIterators(its...)
 
 			default:
 				// return iterator
-				return compose(operation, FromValues(false, mv), path, root)
+				return path.expression(operation, array, root)
 			}
 		}
 
@@ -1199,7 +2162,7 @@ func childThen(ctx *pathContext, childName string, path *Path, recursive bool) *
 						o[childName] = value
 					}
 					// set
-					return FromValues(false, f)
+					return FromValues(false, loc.member(childName, f))
 
 				case deleteOperation:
 					// delete
@@ -1221,12 +2184,12 @@ func childThen(ctx *pathContext, childName string, path *Path, recursive bool) *
 					return evaluateArrayItems(mv)
 				}
 				// return iterator
-				return compose(operation, FromValues(false, mv), path, root)
+				return path.expression(operation, loc.member(childName, mv), root)
 			}
 			// check we need to return null for missing leaf (this is a terminal path)
 			if ctx.returnNullForMissingLeaf && path.terminal {
 				// null value
-				return FromValues(false, nil)
+				return FromValues(false, loc.missingMember(childName))
 			}
 
 		case Map:
@@ -1241,7 +2204,7 @@ func childThen(ctx *pathContext, childName string, path *Path, recursive bool) *
 						// set value
 						o.Set(childName, value)
 					}
-					return FromValues(false, f)
+					return FromValues(false, loc.member(childName, f))
 
 				case deleteOperation:
 					// delete
@@ -1264,28 +2227,64 @@ func childThen(ctx *pathContext, childName string, path *Path, recursive bool) *
 					return evaluateArrayItems(mv)
 				}
 				// return iterator
-				return compose(operation, FromValues(false, mv), path, root)
+				return path.expression(operation, loc.member(childName, mv), root)
 			}
 			// check we need to return null for missing leaf (this is a terminal path)
 			if ctx.returnNullForMissingLeaf && path.terminal {
 				// null value
-				return FromValues(false, nil)
+				return FromValues(false, loc.missingMember(childName))
 			}
 		}
 		return empty(operation, value, root)
 	})
 }
 
-func recursiveFilterThen(filterLexemes []lexeme, path *Path, recursive bool) *Path {
+// filterScope is passed as the root value to the children of the values reached by a recursive filter, it holds the
+// container of the values being filtered and the actual root value
+type filterScope struct {
+	parent any
+	root   any
+}
+
+func recursiveFilterThen(ctx *pathContext, filterNode *filterNode, path *Path, recursive bool) *Path {
 	// create filter
-	filter := newFilter(newFilterNode(filterLexemes))
+	filter := newFilter(ctx, filterNode)
+	// children path, created once since evaluations must not modify the context
+	children := allChildrenThen(ctx, filterChildThen(ctx, filter, path))
+	// apply filter on the children of each value reached by the recursive descent
+	return new(func(operation operation, value, root any) Iterator {
+		// evaluate filter on children, the value is the (possibly located) container of the values being filtered
+		return children.expression(operation, value, filterScope{parent: value, root: root})
+	})
+}
+
+func filterChildThen(ctx *pathContext, filter filter, path *Path) *Path {
 	// create path expression
 	return new(func(operation operation, value, root any) Iterator {
-		// apply filter on value
-		if filter(value, root) {
+		// container of the value and root value
+		scope := root.(filterScope)
+		// unwrap located value
+		raw, _ := unwrap(value)
+		// check containers must be skipped
+		if ctx.recursiveFilterLeavesOnly && isContainer(raw) {
+			return empty(operation, value, scope.root)
+		}
+		// apply filter on value (located if known)
+		if filter(value, scope.parent, scope.root) {
 			// evaluate path expression on value
-			return compose(operation, FromValues(false, value), path, root)
+			return path.expression(operation, value, scope.root)
 		}
-		return empty(operation, value, root)
+		return empty(operation, value, scope.root)
 	})
 }
+
+// isContainer checks the value is an array or an object.
+func isContainer(value any) bool {
+	// process value type
+	switch value.(type) {
+
+	case []any, map[string]any, Array, Map:
+		return true
+	}
+	return false
+}
@@ -7,6 +7,10 @@
 package jsonpath
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -168,6 +172,175 @@ func TestUndottedChildPath2(t *testing.T) {
 	}
 }
 
+func TestDotWildcardPropertyNamePath1(t *testing.T) {
+	// arrange
+	value := map[string]any{"x": map[string]any{"a": "test1"}, "y": []any{map[string]any{"b": "test2"}}, "z": 1}
+	path, err := NewPath("$.*~")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.Evaluate(value)
+	// assert
+	if diff := cmp.Diff([]any{"x", "y", "z"}, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestDotWildcardPropertyNamePath2(t *testing.T) {
+	// arrange
+	value := map[string]any{"x": map[string]any{"a": "test1"}, "y": []any{map[string]any{"b": "test2"}}, "z": 1}
+	path, err := NewPath("$..*~")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.Evaluate(value)
+	// assert (property names at all depths, array items included)
+	if diff := cmp.Diff([]any{"x", "y", "z", "b", "a"}, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestDotWildcardPropertyNamePath3(t *testing.T) {
+	// arrange
+	value := map[string]any{"x": map[string]any{"a": "test1"}, "y": []any{map[string]any{"a": "test2"}}, "a": 1}
+	path, err := NewPath("$..a~")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result, err := NormalizedPaths(value, "$..a~")
+	if err != nil {
+		t.Errorf("invalid result: %s", err)
+	}
+	// assert
+	if diff := cmp.Diff([]any{"a", "a", "a"}, path.Evaluate(value)); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+	if diff := cmp.Diff([]string{"$['a']", "$['y'][0]['a']", "$['x']['a']"}, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestDotWildcardPropertyNamePath4(t *testing.T) {
+	// arrange (escaped wildcard is a child name)
+	value := map[string]any{"*": 1, "x": 2}
+	path, err := NewPath(`$.\*~`)
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.Evaluate(value)
+	// assert
+	if diff := cmp.Diff([]any{"*"}, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestArraySubscriptPropertyNamePath1(t *testing.T) {
+	// arrange
+	value := map[string]any{"a": []any{"x", "y", "z"}}
+	path, err := NewPath("$.a[*]~")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.Evaluate(value)
+	// assert
+	if diff := cmp.Diff([]any{0, 1, 2}, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestArraySubscriptPropertyNamePath2(t *testing.T) {
+	// arrange
+	value := []any{"a", []any{"b", []any{"c"}}, map[string]any{"d": []any{"e", "f"}}}
+	path, err := NewPath("$..[*]~")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result, err := NormalizedPaths(value, "$..[*]~")
+	if err != nil {
+		t.Errorf("invalid result: %s", err)
+	}
+	// assert (indexes of array items and names of object members at all depths)
+	if diff := cmp.Diff([]any{0, 1, 2, 0, 1, 0, "d", 0, 1}, path.Evaluate(value)); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+	expected := []string{"$[0]", "$[1]", "$[2]", "$[1][0]", "$[1][1]", "$[1][1][0]", "$[2]['d']", "$[2]['d'][0]", "$[2]['d'][1]"}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestArraySubscriptPropertyNamePath3(t *testing.T) {
+	// arrange (dot wildcards return object member names only)
+	value := map[string]any{"a": []any{"x", "y"}}
+	path, err := NewPath("$.a.*~")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.Evaluate(value)
+	// assert
+	if diff := cmp.Diff([]any{}, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestFilterPropertyNamePath1(t *testing.T) {
+	// arrange
+	value := map[string]any{"a": "x", "b": "y", "c": "x"}
+	path, err := NewPath("$.*[?(@ == 'x')]~")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.Evaluate(value)
+	// assert
+	if diff := cmp.Diff([]any{"a", "c"}, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestFilterPropertyNamePath2(t *testing.T) {
+	// arrange
+	value := map[string]any{"a": "x", "n": map[string]any{"d": "x", "e": []any{"x", "y", "x"}}}
+	path, err := NewPath("$..[?(@ == 'x')]~")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result, err := NormalizedPaths(value, "$..[?(@ == 'x')]~")
+	if err != nil {
+		t.Errorf("invalid result: %s", err)
+	}
+	// assert (member names and array indexes at all depths)
+	if diff := cmp.Diff([]any{"a", "d", 0, 2}, path.Evaluate(value)); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+	if diff := cmp.Diff([]string{"$['a']", "$['n']['d']", "$['n']['e'][0]", "$['n']['e'][2]"}, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestFilterPropertyNamePath3(t *testing.T) {
+	// arrange (the root value has no name)
+	value := map[string]any{"a": "x"}
+	path, err := NewPath("$[?(@.a)]~")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.Evaluate(value)
+	// assert
+	if diff := cmp.Diff([]any{}, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
 func TestBracketChildPath1(t *testing.T) {
 	// arrange
 	value := map[string]any{"x": map[string]any{"a": "test1"}, "y": map[string]any{"a": "test2"}}
@@ -351,3 +524,1148 @@ func TestFilterOnRecursiveDescentPath2(t *testing.T) {
 		t.Errorf("invalid result: %s", diff)
 	}
 }
+
+func TestLiteralSpecialKeyPath1(t *testing.T) {
+	// arrange
+	value := map[string]any{"*": "star", "a": "va"}
+	path, err := NewPath(`$['*']`)
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.Evaluate(value)
+	// assert
+	if diff := cmp.Diff([]any{"star"}, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestLiteralSpecialKeyPath2(t *testing.T) {
+	// arrange
+	value := map[string]any{"*": "star", "a": "va"}
+	path, err := NewPath(`$.\*`)
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.Evaluate(value)
+	// assert
+	if diff := cmp.Diff([]any{"star"}, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestLiteralSpecialKeyPath3(t *testing.T) {
+	// arrange
+	value := map[string]any{"?": "question", "a": "va"}
+	path, err := NewPath(`$['?']`)
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.Evaluate(value)
+	// assert
+	if diff := cmp.Diff([]any{"question"}, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestLiteralSpecialKeyPath4(t *testing.T) {
+	// arrange
+	value := map[string]any{"..": "dots", "a": map[string]any{"..": "nested"}}
+	path, err := NewPath(`$['..']`)
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.Evaluate(value)
+	// assert
+	if diff := cmp.Diff([]any{"dots"}, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestLiteralSpecialKeyPath5(t *testing.T) {
+	// arrange
+	value := map[string]any{"*": "star", "a": map[string]any{"*": "nested star", "b": "vb"}}
+	path, err := NewPath(`$..['*']`)
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.Evaluate(value)
+	// assert
+	if diff := cmp.Diff([]any{"star", "nested star"}, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestLiteralSpecialKeyPath6(t *testing.T) {
+	// arrange
+	value := map[string]any{"*": "star", "?": "question", "a": "va"}
+	path, err := NewPath(`$['*', '?']~`)
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.Evaluate(value)
+	// assert
+	if diff := cmp.Diff([]any{"*", "?"}, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestEvaluateAll1(t *testing.T) {
+	// arrange
+	documents := []any{
+		map[string]any{"id": 1, "tags": []any{"a", "b"}},
+		map[string]any{"id": 2},
+		map[string]any{"id": 3, "tags": []any{"c"}},
+	}
+	path, err := NewPath("$.tags[*]")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	expected := []DocumentValue{
+		{Doc: 0, Value: "a"},
+		{Doc: 0, Value: "b"},
+		{Doc: 2, Value: "c"},
+	}
+	// act
+	result := path.EvaluateAll(documents)
+	// assert
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestEvaluateAll2(t *testing.T) {
+	// arrange
+	path, err := NewPath("$.id")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.EvaluateAll(nil)
+	// assert
+	if diff := cmp.Diff([]DocumentValue{}, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestEvaluateToStructure1(t *testing.T) {
+	// arrange
+	data := map[string]any{
+		"store": map[string]any{
+			"name": "s",
+			"book": []any{
+				map[string]any{"title": "a", "price": 8.95},
+				map[string]any{"title": "b"},
+				map[string]any{"title": "c", "price": 22.99},
+			},
+			"bicycle": map[string]any{"color": "red", "price": 19.95},
+		},
+	}
+	path, err := NewPath("$..price")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	expected := map[string]any{
+		"store": map[string]any{
+			"book": []any{
+				map[string]any{"price": 8.95},
+				map[string]any{"price": 22.99},
+			},
+			"bicycle": map[string]any{"price": 19.95},
+		},
+	}
+	// act
+	result := path.EvaluateToStructure(data)
+	// assert
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestEvaluateToStructure2(t *testing.T) {
+	// arrange (matches below a matching value are part of it)
+	data := map[string]any{
+		"a": map[string]any{"b": 1, "c": 2},
+		"d": []any{1, 2, 3},
+	}
+	path, err := NewPath("$..*")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.EvaluateToStructure(data)
+	// assert
+	if diff := cmp.Diff(data, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestEvaluateToStructure3(t *testing.T) {
+	// arrange
+	path, err := NewPath("$.missing")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.EvaluateToStructure(map[string]any{"a": 1})
+	// assert
+	if result != nil {
+		t.Errorf("invalid result: %v", result)
+	}
+}
+
+func TestEvaluateFlat1(t *testing.T) {
+	// arrange
+	data := map[string]any{
+		"a": []any{1, map[string]any{"b": "x"}},
+		"c": true,
+	}
+	path, err := NewPath("$", FlatRootEntry())
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	expected := []FlatValue{
+		{Path: "$", Value: data},
+		{Path: "$['c']", Value: true},
+		{Path: "$['a']", Value: data["a"]},
+		{Path: "$['a'][0]", Value: 1},
+		{Path: "$['a'][1]", Value: map[string]any{"b": "x"}},
+		{Path: "$['a'][1]['b']", Value: "x"},
+	}
+	// act
+	result := path.EvaluateFlat(data)
+	// assert
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestEvaluateFlat2(t *testing.T) {
+	// arrange
+	data := map[string]any{
+		"a": []any{1, 2},
+		"b": []any{3},
+	}
+	path, err := NewPath("$.a")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	expected := []FlatValue{
+		{Path: "$['a']", Value: []any{1, 2}},
+		{Path: "$['a'][0]", Value: 1},
+		{Path: "$['a'][1]", Value: 2},
+	}
+	// act
+	result := path.EvaluateFlat(data)
+	// assert
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestEvaluateFlat3(t *testing.T) {
+	// arrange
+	data := map[string]any{
+		"a": []any{1},
+	}
+	// test cases
+	tcs := []struct {
+		name     string
+		path     string
+		options  []Option
+		data     any
+		expected []FlatValue
+	}{
+		{
+			name:     "scalar root omitted by default",
+			path:     "$",
+			data:     1,
+			expected: []FlatValue{},
+		},
+		{
+			name:     "scalar root entry",
+			path:     "$",
+			options:  []Option{FlatRootEntry()},
+			data:     1,
+			expected: []FlatValue{{Path: "$", Value: 1}},
+		},
+		{
+			name:     "root omitted by default",
+			path:     "$",
+			data:     data,
+			expected: []FlatValue{{Path: "$['a']", Value: []any{1}}, {Path: "$['a'][0]", Value: 1}},
+		},
+		{
+			name:     "root entry",
+			path:     "$",
+			options:  []Option{FlatRootEntry()},
+			data:     data,
+			expected: []FlatValue{{Path: "$", Value: data}, {Path: "$['a']", Value: []any{1}}, {Path: "$['a'][0]", Value: 1}},
+		},
+		{
+			name:     "root entry on child match",
+			path:     "$.*",
+			options:  []Option{FlatRootEntry()},
+			data:     data,
+			expected: []FlatValue{{Path: "$['a']", Value: []any{1}}, {Path: "$['a'][0]", Value: 1}},
+		},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			path, err := NewPath(tc.path, tc.options...)
+			if err != nil {
+				t.Errorf("invalid path: %s", err)
+			}
+			// act
+			result := path.EvaluateFlat(tc.data)
+			// assert
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("invalid result: %s", diff)
+			}
+		})
+	}
+}
+
+func TestEvaluateGrouped1(t *testing.T) {
+	// arrange
+	book1 := map[string]any{"title": "a", "isbn": "1"}
+	book2 := map[string]any{"title": "b"}
+	book3 := map[string]any{"title": "c", "isbn": "3"}
+	book4 := map[string]any{"title": "d", "isbn": "4"}
+	books1 := []any{book1, book2, book3}
+	books2 := []any{book4}
+	data := map[string]any{
+		"stores": []any{
+			map[string]any{"book": books1},
+			map[string]any{"book": books2},
+		},
+	}
+	path, err := NewPath("$..book[?(@.isbn)]")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	expected := []Group{
+		{Parent: books1, Matches: []any{book1, book3}},
+		{Parent: books2, Matches: []any{book4}},
+	}
+	// act
+	result := path.EvaluateGrouped(data)
+	// assert
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestEvaluateGrouped2(t *testing.T) {
+	// arrange
+	data := map[string]any{"a": 1}
+	path, err := NewPath("$")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	expected := []Group{
+		{Parent: nil, Matches: []any{data}},
+	}
+	// act
+	result := path.EvaluateGrouped(data)
+	// assert
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestEvaluateGrouped3(t *testing.T) {
+	// arrange
+	data := map[string]any{
+		"a": []any{1, 2, 3},
+		"b": []any{1, 2, 3},
+	}
+	path, err := NewPath("$['a','b','a'][0,2]")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	expected := []Group{
+		{Parent: data["a"], Matches: []any{1, 3, 1, 3}},
+		{Parent: data["b"], Matches: []any{1, 3}},
+	}
+	// act
+	result := path.EvaluateGrouped(data)
+	// assert
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestEvaluateGrouped4(t *testing.T) {
+	// arrange
+	path, err := NewPath("$.missing[*]")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.EvaluateGrouped(map[string]any{})
+	// assert
+	if diff := cmp.Diff([]Group{}, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestEvaluateWithSiblings1(t *testing.T) {
+	// arrange
+	value := map[string]any{
+		"store": []any{
+			map[string]any{"book": []any{
+				map[string]any{"title": "a", "featured": true},
+				map[string]any{"title": "b"},
+				map[string]any{"title": "c"},
+			}},
+			map[string]any{"book": []any{
+				map[string]any{"title": "d"},
+				map[string]any{"title": "e", "featured": true},
+			}},
+		},
+	}
+	path, err := NewPath("$..book[?(@.featured)]")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.EvaluateWithSiblings(value)
+	// assert
+	expected := []ValueWithSiblings{
+		{
+			Value:    map[string]any{"title": "a", "featured": true},
+			Siblings: []any{map[string]any{"title": "b"}, map[string]any{"title": "c"}},
+		},
+		{
+			Value:    map[string]any{"title": "e", "featured": true},
+			Siblings: []any{map[string]any{"title": "d"}},
+		},
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestEvaluateWithSiblings2(t *testing.T) {
+	// arrange
+	value := NewOrderedMap()
+	value.Set("c", 3)
+	value.Set("a", 1)
+	value.Set("b", 2)
+	path, err := NewPath("$.a")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.EvaluateWithSiblings(value)
+	// assert
+	expected := []ValueWithSiblings{
+		{Value: 1, Siblings: []any{3, 2}},
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestEvaluateWithSiblings3(t *testing.T) {
+	// arrange
+	path, err := NewPath("$")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.EvaluateWithSiblings([]any{1})
+	// assert
+	expected := []ValueWithSiblings{
+		{Value: []any{1}},
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestEvaluateTyped1(t *testing.T) {
+	// arrange
+	value := []any{1, 2.5, json.Number("3"), "x", nil, []any{}}
+	path, err := NewPath("$[*]")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.EvaluateTyped(value)
+	// assert
+	expected := []TypedMatch{
+		{Value: 1, GoType: "int"},
+		{Value: 2.5, GoType: "float64"},
+		{Value: json.Number("3"), GoType: "json.Number"},
+		{Value: "x", GoType: "string"},
+		{Value: nil, GoType: "<nil>"},
+		{Value: []any{}, GoType: "[]interface {}"},
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestTypeStats1(t *testing.T) {
+	// arrange
+	value := map[string]any{"a": []any{1, 2.5, 3.5, nil}}
+	path, err := NewPath("$.a[*]")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.TypeStats(value)
+	// assert
+	if diff := cmp.Diff(map[string]int{"int": 1, "float64": 2, "<nil>": 1}, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestEvaluateMatches1(t *testing.T) {
+	// arrange
+	value := []any{
+		map[string]any{"foo": "foo1", "bar": "bar1"},
+		map[string]any{"foo": "foo2", "bar": nil},
+		map[string]any{"foo": "foo3"},
+	}
+	path, err := NewPath("$[*].bar", ReturnNullForMissingLeaf())
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	expected := []Match{
+		{Value: "bar1", Path: "$[0]['bar']"},
+		{Value: nil, Path: "$[1]['bar']"},
+		{Value: nil, Path: "$[2]['bar']", Missing: true},
+	}
+	// act
+	result := path.EvaluateMatches(value)
+	// assert
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestEvaluateMatches2(t *testing.T) {
+	// arrange
+	value := NewOrderedMap()
+	value.Set("a", nil)
+	// test cases
+	tcs := []struct {
+		path     string
+		options  []Option
+		expected []Match
+	}{
+		{path: "$.a", options: []Option{ReturnNullForMissingLeaf()}, expected: []Match{{Value: nil, Path: "$['a']"}}},
+		{path: "$.b", options: []Option{ReturnNullForMissingLeaf()}, expected: []Match{{Value: nil, Path: "$['b']", Missing: true}}},
+		{path: "$.b", expected: []Match{}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		path, err := NewPath(tc.path, tc.options...)
+		if err != nil {
+			t.Errorf("invalid path: %s", err)
+		}
+		// act
+		result := path.EvaluateMatches(value)
+		// assert
+		if diff := cmp.Diff(tc.expected, result); diff != "" {
+			t.Errorf("invalid result for %s: %s", tc.path, diff)
+		}
+	}
+}
+
+func TestEvaluateMatches3(t *testing.T) {
+	// arrange
+	value := map[string]any{"a": map[string]any{"x": 1}, "b": []any{"eyJjIjogMn0="}}
+	// test cases (values are the ones returned by Evaluate)
+	tcs := []struct {
+		path     string
+		expected []Match
+	}{
+		{path: "$.a.*~", expected: []Match{{Value: "x", Path: "$['a']['x']"}}},
+		{path: "$.b[*]~", expected: []Match{{Value: 0, Path: "$['b'][0]"}}},
+		{path: "$.b[0]|base64|json.c", expected: []Match{{Value: 2.0}}},
+		{path: "$.b[*].count()", expected: []Match{{Value: 1}}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		path, err := NewPath(tc.path)
+		if err != nil {
+			t.Errorf("invalid path: %s", err)
+		}
+		// act
+		result := path.EvaluateMatches(value)
+		// assert
+		if diff := cmp.Diff(tc.expected, result); diff != "" {
+			t.Errorf("invalid result for %s: %s", tc.path, diff)
+		}
+		if diff := cmp.Diff(path.Evaluate(value), matchValues(result)); diff != "" {
+			t.Errorf("invalid values for %s: %s", tc.path, diff)
+		}
+	}
+}
+
+// matchValues returns the values of the matches
+func matchValues(matches []Match) []any {
+	// values
+	values := []any{}
+	// loop matches
+	for _, m := range matches {
+		values = append(values, m.Value)
+	}
+	return values
+}
+
+func TestEvaluatePartial(t *testing.T) {
+	// arrange
+	value := map[string]any{
+		"a": map[string]any{
+			"b": map[string]any{"d": 1},
+			"l": []any{1, 2},
+		},
+		"w": []any{[]any{5}},
+	}
+	// test cases
+	tcs := []struct {
+		path     string
+		options  []Option
+		expected []any
+		prefix   string
+	}{
+		{path: "$.a.b.c", expected: []any{}, prefix: "$.a.b"},
+		{path: "$.a.b.d", expected: []any{1}, prefix: "$.a.b.d"},
+		{path: "$.x.y", expected: []any{}, prefix: "$"},
+		{path: "a.b.c", expected: []any{}, prefix: "$.a.b"},
+		{path: "$.a.l[5]", expected: []any{}, prefix: "$.a.l"},
+		{path: "$.a.l[?(@ > 5)].x", expected: []any{}, prefix: "$.a.l"},
+		{path: "$..b.c", expected: []any{}, prefix: "$..b"},
+		{path: "$.w[?(@ > 3)].x", expected: []any{}, prefix: "$.w"},
+		{path: "$.w[?(@ > 3)].x", options: []Option{UnwrapSingletonArrays()}, expected: []any{}, prefix: "$.w[?(@>3)]"},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		path, err := NewPath(tc.path, tc.options...)
+		if err != nil {
+			t.Errorf("invalid path: %s", err)
+		}
+		// act
+		result, prefix := path.EvaluatePartial(value)
+		// assert
+		if diff := cmp.Diff(tc.expected, result); diff != "" {
+			t.Errorf("invalid result for %s: %s", tc.path, diff)
+		}
+		if diff := cmp.Diff(tc.prefix, prefix); diff != "" {
+			t.Errorf("invalid prefix for %s: %s", tc.path, diff)
+		}
+	}
+}
+
+func TestEvaluateChan1(t *testing.T) {
+	// arrange
+	value := []any{1, 2, 3}
+	path, err := NewPath("$[*]")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	values, errs := path.EvaluateChan(context.Background(), value)
+	// collect values
+	result := []any{}
+	for v := range values {
+		result = append(result, v)
+	}
+	// assert
+	if err := <-errs; err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if diff := cmp.Diff(value, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestEvaluateChan2(t *testing.T) {
+	// arrange
+	value := []any{1, 2, 3}
+	path, err := NewPath("$[*]")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	// act
+	values, errs := path.EvaluateChan(ctx, value)
+	// consume first value and cancel
+	first := <-values
+	cancel()
+	// assert
+	if first != 1 {
+		t.Errorf("unexpected first value: %v", first)
+	}
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+	// values channel must be closed
+	for range values {
+	}
+}
+
+func TestEvaluateChan3(t *testing.T) {
+	// arrange
+	value := []any{1, 2, 3}
+	path, err := NewPath("$[*]")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// repeat (a ready receiver and a cancelled context are selected randomly)
+	for i := 0; i < 100; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		// act
+		values, errs := path.EvaluateChan(ctx, value)
+		// collect values
+		result := []any{}
+		for v := range values {
+			result = append(result, v)
+		}
+		// assert
+		if err := <-errs; err != context.Canceled {
+			t.Errorf("expected context.Canceled, got: %v", err)
+		}
+		if len(result) != 0 {
+			t.Fatalf("unexpected values after cancellation: %v", result)
+		}
+	}
+}
+
+func TestFilterLiteralMustBeCompared1(t *testing.T) {
+	// act
+	_, err := NewPath("$[?(1)]")
+	// assert
+	if err == nil || err.Error() != "filter literal 1 must be compared" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestFilterLiteralMustBeCompared2(t *testing.T) {
+	// act
+	_, err := NewPath("$[?(@.a && 'x')]")
+	// assert
+	if err == nil || err.Error() != "filter literal 'x' must be compared" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestFilterLiteralMustBeCompared3(t *testing.T) {
+	// arrange
+	value := []any{1, 2}
+	path, err := NewPath("$[?(true)]")
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.Evaluate(value)
+	// assert
+	if diff := cmp.Diff(value, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func BenchmarkBracketChildNames(b *testing.B) {
+	// 5000 names union
+	names := make([]string, 0, 5000)
+	for i := 0; i < 5000; i++ {
+		names = append(names, fmt.Sprintf("'name,%d'", i))
+	}
+	union := strings.Join(names, ", ")
+	// reset timer
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bracketChildNames(union)
+	}
+}
+
+// catalog returns n path expressions of different shapes
+func catalog(n int) []string {
+	// shapes
+	shapes := []string{
+		"$.store.book[%d].title",
+		"$..book[?(@.price > %d && @.category == 'fiction')].author",
+		"$['store']['bicycle', 'book'][%d:]",
+		"$.items[?(@.tags[*] == 'tag%d')].name",
+	}
+	// expressions
+	expressions := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		expressions = append(expressions, fmt.Sprintf(shapes[i%len(shapes)], i))
+	}
+	return expressions
+}
+
+func BenchmarkCompile(b *testing.B) {
+	// arrange
+	expressions := catalog(1000)
+	// reset timer
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, expression := range expressions {
+			if _, err := NewPath(expression); err != nil {
+				b.Errorf("invalid path: %s", err)
+			}
+		}
+	}
+}
+
+func BenchmarkCompileAll(b *testing.B) {
+	// arrange
+	expressions := catalog(1000)
+	// reset timer
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := CompileAll(expressions); err != nil {
+			b.Errorf("invalid path: %s", err)
+		}
+	}
+}
+
+func filterDocument(n int) map[string]any {
+	// items
+	items := make([]any, 0, n)
+	for i := 0; i < n; i++ {
+		items = append(items, map[string]any{"name": fmt.Sprintf("item%d", i), "price": i})
+	}
+	return map[string]any{"store": map[string]any{"items": items}}
+}
+
+func BenchmarkRecursiveFilter(b *testing.B) {
+	// arrange
+	data := filterDocument(10000)
+	path, err := NewPath("$..[?(@.name =~ /^item1/ && @.price > 10)].name")
+	if err != nil {
+		b.Errorf("invalid path: %s", err)
+	}
+	// reset timer
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		path.Evaluate(data)
+	}
+}
+
+func BenchmarkWildcardLargeArray(b *testing.B) {
+	// arrange
+	data := make([]any, 1000000)
+	for i := range data {
+		data[i] = i
+	}
+	// loop wildcards
+	for _, expression := range []string{"$[*]", "$.*"} {
+		b.Run(expression, func(b *testing.B) {
+			// compile path
+			path, err := NewPath(expression)
+			if err != nil {
+				b.Errorf("invalid path: %s", err)
+			}
+			// reset timer
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				path.Evaluate(data)
+			}
+		})
+	}
+}
+
+func TestBracketChildNames(t *testing.T) {
+	// arrange
+	cases := []struct {
+		childNames string
+		expected   []string
+	}{
+		{childNames: `'a'`, expected: []string{"a"}},
+		{childNames: `'a', 'b'`, expected: []string{"a", "b"}},
+		{childNames: `"a","b"`, expected: []string{"a", "b"}},
+		{childNames: `'a,b', 'c'`, expected: []string{"a,b", "c"}},
+		{childNames: `"a'b", 'c'`, expected: []string{"a'b", "c"}},
+		{childNames: `'a\',b', 'c'`, expected: []string{"a',b", "c"}},
+		{childNames: `'a\\', 'b'`, expected: []string{`a\`, "b"}},
+	}
+	for _, tc := range cases {
+		// act
+		result := bracketChildNames(tc.childNames)
+		// assert
+		if diff := cmp.Diff(tc.expected, result); diff != "" {
+			t.Errorf("invalid result for %s: %s", tc.childNames, diff)
+		}
+	}
+}
+
+func TestUnterminatedPaths(t *testing.T) {
+	// arrange
+	cases := []struct {
+		path     string
+		expected string
+	}{
+		{path: `$[0`, expected: `unmatched [ at position 3, following "$[0"`},
+		{path: `$.a[0:`, expected: `unmatched [ at position 6, following ".a[0:"`},
+		{path: `$['a`, expected: `unmatched "'" at position 4, following "$['a"`},
+		{path: `$["a`, expected: `unmatched '"' at position 4, following "$[\"a"`},
+		{path: `$['a'`, expected: `missing "]" or "," at position 5, following "$['a'"`},
+		{path: `$['a', 0`, expected: `unmatched [ at position 6, following "$['a',"`},
+		{path: `$[?(`, expected: `missing end of filter at position 4, following "[?("`},
+		{path: `$[?(@.a`, expected: `missing end of filter at position 7, following ".a"`},
+		{path: `$[?(@.a)`, expected: `missing end of filter at position 8, following ")"`},
+		{path: `$[?(@.a == 'x`, expected: `unmatched string delimiter ' at position 11, following "== "`},
+		{path: `$[?(@.a =~ /x`, expected: `unmatched regular expression delimiter / at position 11, following "=~ "`},
+		{path: `$[?(time(@.a`, expected: `missing ) after function argument at position 12, following ".a"`},
+	}
+	for _, tc := range cases {
+		// act
+		_, err := NewPath(tc.path)
+		// assert
+		if err == nil || err.Error() != tc.expected {
+			t.Errorf("unexpected error for %s: %v", tc.path, err)
+		}
+	}
+}
+
+func TestKnownKeys1(t *testing.T) {
+	// arrange
+	value := map[string]any{"store": map[string]any{"book": []any{map[string]any{"title": "a", "price": 1}}}}
+	path, err := NewPath("$.store['book'][?(@.price > 0)].title", KnownKeys("store", "book", "title", "price"))
+	if err != nil {
+		t.Errorf("invalid path: %s", err)
+	}
+	// act
+	result := path.Evaluate(value)
+	// assert
+	if diff := cmp.Diff([]any{"a"}, result); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestKnownKeys2(t *testing.T) {
+	// arrange
+	cases := []struct {
+		path     string
+		expected string
+	}{
+		{path: "$.stroe.book", expected: `unknown property name "stroe"`},
+		{path: "$.store['bok']", expected: `unknown property name "bok"`},
+		{path: "$['store', 'boook', 0]", expected: `unknown property name "boook"`},
+		{path: "$..titel", expected: `unknown property name "titel"`},
+		{path: "$.store.book[?(@.pricee > 1)]", expected: `unknown property name "pricee"`},
+		{path: "stroe", expected: `unknown property name "stroe"`},
+	}
+	for _, tc := range cases {
+		// act
+		_, err := NewPath(tc.path, KnownKeys("store", "book", "title", "price"))
+		// assert
+		if err == nil || err.Error() != tc.expected {
+			t.Errorf("unexpected error for %s: %v", tc.path, err)
+		}
+	}
+}
+
+func TestKnownKeys3(t *testing.T) {
+	// arrange (property names and escaped names are checked as the member names they select)
+	paths := []string{"$..title~", "$.title~", "$.a\\*b", "$..a\\*b.title", "$.\\*", "$.*", "$..*", "$..*~", "$.a\\*b[*]~"}
+	for _, path := range paths {
+		// act
+		_, err := NewPath(path, KnownKeys("title", "a*b", "*"))
+		// assert
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", path, err)
+		}
+	}
+}
+
+func TestKnownKeys4(t *testing.T) {
+	// arrange
+	cases := []struct {
+		path     string
+		expected string
+	}{
+		{path: "$..titel~", expected: `unknown property name "titel"`},
+		{path: "$.a\\*c", expected: `unknown property name "a*c"`},
+		{path: "$.\\*", expected: `unknown property name "*"`},
+		{path: "$..\\*", expected: `unknown property name "*"`},
+	}
+	for _, tc := range cases {
+		// act
+		_, err := NewPath(tc.path, KnownKeys("title", "a*b"))
+		// assert
+		if err == nil || err.Error() != tc.expected {
+			t.Errorf("unexpected error for %s: %v", tc.path, err)
+		}
+	}
+}
+
+func TestStrictRFC95351(t *testing.T) {
+	// arrange
+	paths := []string{
+		"$",
+		"$.store.book[*].author",
+		"$..author",
+		"$.store.*",
+		"$['store']['book'][0, 1]",
+		"$..book[-1:]",
+		"$..book[?(@.isbn)]",
+		"$..[?(@.price < 10 && @.category == 'fiction')]",
+		"$.store.book[?($.expensive > @.price)].title",
+		"$._private.é1",
+		"$..book[?(length(@.title) > 10)]",
+	}
+	for _, path := range paths {
+		// act
+		_, err := NewPath(path, StrictRFC9535())
+		// assert
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", path, err)
+		}
+	}
+}
+
+func TestStrictRFC95352(t *testing.T) {
+	// arrange
+	cases := []struct {
+		path     string
+		expected string
+	}{
+		{path: "store.book", expected: `RFC 9535 paths must start with "$"`},
+		{path: "", expected: `RFC 9535 paths must start with "$"`},
+		{path: "$.store~", expected: `property name selector (~) is not supported by RFC 9535: ".store~"`},
+		{path: "$['a', 'b']~", expected: `property name selector (~) is not supported by RFC 9535: "['a', 'b']~"`},
+		{path: "$.a[*]~", expected: `property name selector (~) is not supported by RFC 9535: "[*]~"`},
+		{path: "$..a~", expected: `property name selector (~) is not supported by RFC 9535: "..a~"`},
+		{path: "$.*[?(@ == 'x')]~", expected: `property name selector (~) is not supported by RFC 9535: "~"`},
+		{path: "$[?(@^.a)]", expected: `parent reference (@^) is not supported by RFC 9535: "@^"`},
+		{path: "$[?(@.a =~ /x/)]", expected: `regular expression match (=~) is not supported by RFC 9535: "=~"`},
+		{path: "$[?(@.a in [1, 2])]", expected: `set membership (in) is not supported by RFC 9535: "in"`},
+		{path: "$[?(any(@.a == 1))]", expected: `any() filter is not supported by RFC 9535: "any("`},
+		{path: "$[?(min(@.a) > 1)]", expected: `min() function is not supported by RFC 9535: "min("`},
+		{path: "$[?(avg(@.a) > 1)]", expected: `avg() function is not supported by RFC 9535: "avg("`},
+		{path: "$[?(@~ =~ /^tmp_/)]", expected: `member names (@~) is not supported by RFC 9535: "@~"`},
+		{path: "$.a[*].count()", expected: `count() selector is not supported by RFC 9535: ".count()"`},
+		{path: "$[?(@@index % 2 == 1)]", expected: `array index (@@index) is not supported by RFC 9535: "@@index"`},
+		{path: "$[?(bytelength(@.a) > 1)]", expected: `bytelength() function is not supported by RFC 9535: "bytelength("`},
+		{path: "$[?(text(@) =~ /x/)]", expected: `text() function is not supported by RFC 9535: "text("`},
+		{path: "$[?(version(@.v) > version('1.2.0'))]", expected: `version() function is not supported by RFC 9535: "version("`},
+		{path: "$.a-b", expected: `invalid RFC 9535 member name shorthand: ".a-b"`},
+		{path: "$.1a", expected: `invalid RFC 9535 member name shorthand: ".1a"`},
+		{path: "$..a-b", expected: `invalid RFC 9535 member name shorthand: "..a-b"`},
+	}
+	for _, tc := range cases {
+		// act
+		_, err := NewPath(tc.path, StrictRFC9535())
+		// assert
+		if err == nil || err.Error() != tc.expected {
+			t.Errorf("unexpected error for %s: %v", tc.path, err)
+		}
+		// extensions are accepted by default
+		if _, err := NewPath(tc.path); err != nil {
+			t.Errorf("unexpected error for %s: %v", tc.path, err)
+		}
+	}
+}
+
+func TestCompileAll1(t *testing.T) {
+	// arrange
+	value := map[string]any{
+		"store": map[string]any{
+			"book": []any{
+				map[string]any{"title": "a", "price": 5, "category": "fiction", "author": "x"},
+				map[string]any{"title": "b", "price": 15, "category": "fiction", "author": "y"},
+			},
+		},
+		"items": []any{map[string]any{"name": "i", "tags": []any{"tag3"}}},
+	}
+	expressions := append(catalog(8), "$.store.book[*]~", "store.book[0]")
+	// act
+	paths, err := CompileAll(expressions)
+	if err != nil {
+		t.Errorf("invalid paths: %s", err)
+	}
+	// assert (same results as paths compiled one by one)
+	for i, expression := range expressions {
+		path, err := NewPath(expression)
+		if err != nil {
+			t.Errorf("invalid path: %s", err)
+		}
+		if diff := cmp.Diff(path.Evaluate(value), paths[i].Evaluate(value)); diff != "" {
+			t.Errorf("invalid result for %s: %s", expression, diff)
+		}
+	}
+}
+
+func TestCompileAll2(t *testing.T) {
+	// act
+	paths, err := CompileAll([]string{"$.a", "$[?(@.a ==)]", "$.b"}, KnownKeys("a", "b"))
+	// assert
+	if paths != nil {
+		t.Errorf("unexpected paths: %v", paths)
+	}
+	_, expected := NewPath("$[?(@.a ==)]", KnownKeys("a", "b"))
+	if err == nil || err.Error() != "invalid path expression at index 1: "+expected.Error() {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestPathTrimming1(t *testing.T) {
+	// arrange
+	value := map[string]any{"a": []any{map[string]any{"b": 1}, map[string]any{"b": 2}}}
+	expressions := []string{"\ufeff$.a[*].b", "$.a[*].b\n", "\ufeff  $.a[*].b \r\n", "\t$.a[?(@.b > 0)].b\n"}
+	// act
+	for _, expression := range expressions {
+		path, err := NewPath(expression, StrictRFC9535())
+		// assert
+		if err != nil {
+			t.Errorf("invalid path %q: %s", expression, err)
+			continue
+		}
+		if diff := cmp.Diff([]any{1, 2}, path.Evaluate(value)); diff != "" {
+			t.Errorf("invalid result for %q: %s", expression, diff)
+		}
+	}
+}
+
+func TestPathTrimming2(t *testing.T) {
+	// arrange
+	cases := []struct {
+		path     string
+		expected string
+	}{
+		{path: "\ufeff$.a[0] x\n", expected: `invalid character ' ' at position 6, following "[0]"`},
+		{path: "$\ufeff.a", expected: `invalid path syntax at position 1, following "$"`},
+	}
+	for _, tc := range cases {
+		// act
+		_, err := NewPath(tc.path)
+		// assert
+		if err == nil || err.Error() != tc.expected {
+			t.Errorf("unexpected error for %q: %v", tc.path, err)
+		}
+	}
+}
+
+func TestFullSliceNotDefinite(t *testing.T) {
+	// arrange
+	var expressions = []string{"$[:]", "$[::]", "$[::2]"}
+	// act
+	for _, expression := range expressions {
+		ctx, _, err := compile(expression, nil)
+		// assert
+		if err != nil {
+			t.Errorf("invalid path %s: %s", expression, err)
+		}
+		if ctx.definite {
+			t.Errorf("path %s must not be definite", expression)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+module github.com/SteelBridgeLabs/jsonpath/protobuf
+
+go 1.20
+
+require (
+	github.com/SteelBridgeLabs/jsonpath v0.0.0
+	github.com/google/go-cmp v0.5.9
+	google.golang.org/protobuf v1.33.0
+)
+
+require gopkg.in/yaml.v3 v3.0.1 // indirect
+
+replace github.com/SteelBridgeLabs/jsonpath => ../
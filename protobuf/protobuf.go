@@ -0,0 +1,201 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package protobuf adapts protobuf dynamic messages (structpb values) to the jsonpath Map and Array interfaces.
+package protobuf
+
+import (
+	"sort"
+
+	"github.com/SteelBridgeLabs/jsonpath"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// protoStruct adapts structpb.Struct to the jsonpath.Map interface
+type protoStruct struct {
+	s *structpb.Struct
+}
+
+// protoList adapts structpb.ListValue to the jsonpath.Array interface
+type protoList struct {
+	l *structpb.ListValue
+}
+
+// FromStruct adapts a protobuf dynamic message (structpb.Struct) to the jsonpath.Map interface so it can be queried and
+// updated with JsonPath expressions. Member values are converted to their Go counterparts: numbers are float64,
+// nested structs and lists are adapted as well and null values are nil. Wildcards enumerate members in sorted key
+// order.
+func FromStruct(s *structpb.Struct) jsonpath.Map {
+	return protoStruct{s: s}
+}
+
+// FromList adapts a protobuf dynamic list (structpb.ListValue) to the jsonpath.Array interface, values are converted
+// as in FromStruct.
+func FromList(l *structpb.ListValue) jsonpath.Array {
+	return protoList{l: l}
+}
+
+// fromProtoValue converts a structpb.Value into its Go counterpart, adapting structs and lists
+func fromProtoValue(v *structpb.Value) any {
+	// process value kind
+	switch k := v.GetKind().(type) {
+
+	case *structpb.Value_NumberValue:
+		return k.NumberValue
+
+	case *structpb.Value_StringValue:
+		return k.StringValue
+
+	case *structpb.Value_BoolValue:
+		return k.BoolValue
+
+	case *structpb.Value_StructValue:
+		return protoStruct{s: k.StructValue}
+
+	case *structpb.Value_ListValue:
+		return protoList{l: k.ListValue}
+
+	default:
+		// null value (or no value at all)
+		return nil
+	}
+}
+
+// toProtoValue converts a Go value into a structpb.Value, values that cannot be represented (e.g. Go structs) are
+// converted into null values
+func toProtoValue(value any) *structpb.Value {
+	// process value type
+	switch v := value.(type) {
+
+	case *structpb.Value:
+		return v
+
+	case protoStruct:
+		return structpb.NewStructValue(v.s)
+
+	case protoList:
+		return structpb.NewListValue(v.l)
+
+	case *structpb.Struct:
+		return structpb.NewStructValue(v)
+
+	case *structpb.ListValue:
+		return structpb.NewListValue(v)
+	}
+	// convert value
+	pv, err := structpb.NewValue(value)
+	if err != nil {
+		return structpb.NewNullValue()
+	}
+	return pv
+}
+
+// sortedKeys returns the struct member names in sorted order
+func (m protoStruct) sortedKeys() []string {
+	// keys
+	keys := make([]string, 0, len(m.s.GetFields()))
+	// loop fields
+	for k := range m.s.GetFields() {
+		keys = append(keys, k)
+	}
+	// sort keys
+	sort.Strings(keys)
+	return keys
+}
+
+func (m protoStruct) Keys(keys ...string) jsonpath.Iterator {
+	// check we need specific keys
+	if len(keys) > 0 {
+		// keys in struct
+		values := make([]any, 0, len(keys))
+		// loop keys
+		for _, k := range keys {
+			// find key in struct
+			if _, ok := m.s.GetFields()[k]; ok {
+				// append key
+				values = append(values, k)
+			}
+		}
+		return jsonpath.FromValues(false, values...)
+	}
+	// all keys in struct
+	sorted := m.sortedKeys()
+	values := make([]any, 0, len(sorted))
+	// loop keys
+	for _, k := range sorted {
+		// append key
+		values = append(values, k)
+	}
+	return jsonpath.FromValues(false, values...)
+}
+
+func (m protoStruct) Values(keys ...string) jsonpath.Iterator {
+	// check we need all values
+	if len(keys) == 0 {
+		keys = m.sortedKeys()
+	}
+	// values in struct
+	values := make([]any, 0, len(keys))
+	// loop keys
+	for _, k := range keys {
+		// find key in struct (a missing member is absent, a null member is nil)
+		if v, ok := m.s.GetFields()[k]; ok {
+			// append value
+			values = append(values, fromProtoValue(v))
+		}
+	}
+	return jsonpath.FromValues(false, values...)
+}
+
+func (m protoStruct) Set(key string, value any) {
+	// check fields
+	if m.s.Fields == nil {
+		m.s.Fields = map[string]*structpb.Value{}
+	}
+	m.s.Fields[key] = toProtoValue(value)
+}
+
+func (m protoStruct) Delete(key string) {
+	delete(m.s.Fields, key)
+}
+
+func (a protoList) Len() int {
+	return len(a.l.GetValues())
+}
+
+func (a protoList) Values(reverse bool, indexes ...int) jsonpath.Iterator {
+	// list values
+	items := a.l.GetValues()
+	// check we need specific indexes
+	if len(indexes) > 0 {
+		// values in list
+		values := make([]any, 0, len(indexes))
+		// loop indexes
+		for _, i := range indexes {
+			// check bounds
+			if i >= 0 && i < len(items) {
+				// append value
+				values = append(values, fromProtoValue(items[i]))
+			}
+		}
+		return jsonpath.FromValues(reverse, values...)
+	}
+	// all values
+	values := make([]any, 0, len(items))
+	// loop items
+	for _, v := range items {
+		// append value
+		values = append(values, fromProtoValue(v))
+	}
+	return jsonpath.FromValues(reverse, values...)
+}
+
+func (a protoList) Set(index int, value any) {
+	// check bounds
+	if index >= 0 && index < len(a.l.GetValues()) {
+		a.l.Values[index] = toProtoValue(value)
+	}
+}
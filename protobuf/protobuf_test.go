@@ -0,0 +1,155 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package protobuf
+
+import (
+	"testing"
+
+	"github.com/SteelBridgeLabs/jsonpath"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func protoStructDocument(t *testing.T) *structpb.Struct {
+	// document
+	s, err := structpb.NewStruct(map[string]any{
+		"name":  "store",
+		"open":  true,
+		"owner": nil,
+		"address": map[string]any{
+			"city": "Lisbon",
+			"zip":  "1000-001",
+		},
+		"books": []any{
+			map[string]any{"title": "Dune", "price": 9.5, "tags": []any{"sf", "classic"}},
+			map[string]any{"title": "Emma", "price": 12, "discount": nil},
+			map[string]any{"title": "Ulysses", "price": 15.25},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create struct: %v", err)
+	}
+	return s
+}
+
+func TestProtoStructGet(t *testing.T) {
+	// arrange
+	var data = FromStruct(protoStructDocument(t))
+	// test cases
+	tcs := []struct {
+		path     string
+		expected any
+	}{
+		{path: "$.name", expected: "store"},
+		{path: "$.address.city", expected: "Lisbon"},
+		{path: "$.address.*", expected: []any{"Lisbon", "1000-001"}},
+		{path: "$.books[1].price", expected: float64(12)},
+		{path: "$.books[-1].title", expected: "Ulysses"},
+		{path: "$.books[0].tags[*]", expected: []any{"sf", "classic"}},
+		{path: "$.books[?(@.price > 10)].title", expected: []any{"Emma", "Ulysses"}},
+		{path: "$..tags[1]", expected: []any{"classic"}},
+		{path: "$.owner", expected: nil},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := jsonpath.Get(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestProtoStructMissingMember(t *testing.T) {
+	// arrange
+	var data = FromStruct(protoStructDocument(t))
+	// test cases
+	tcs := []struct {
+		path     string
+		expected any
+	}{
+		{path: "$.books[?(@.discount == null)].title", expected: []any{"Emma"}},
+		{path: "$.books[?(@.discount)].title", expected: []any{"Emma"}},
+		{path: "$.books[?(@.tags)].title", expected: []any{"Dune"}},
+		{path: "$.books[?(!@.tags)].title", expected: []any{"Emma", "Ulysses"}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := jsonpath.Get(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestProtoStructSet(t *testing.T) {
+	// arrange
+	var document = protoStructDocument(t)
+	var data = FromStruct(document)
+	// act
+	err := jsonpath.Set(data, "$.books[*].price", 10)
+	if err != nil {
+		t.Errorf("Failed to set value: %v", err)
+	}
+	err = jsonpath.Set(data, "$.address.country", "Portugal")
+	if err != nil {
+		t.Errorf("Failed to set value: %v", err)
+	}
+	err = jsonpath.Set(data, "$.books[0].tags[0]", map[string]any{"name": "sf"})
+	if err != nil {
+		t.Errorf("Failed to set value: %v", err)
+	}
+	// assert
+	var expected = map[string]any{
+		"name":  "store",
+		"open":  true,
+		"owner": nil,
+		"address": map[string]any{
+			"city":    "Lisbon",
+			"zip":     "1000-001",
+			"country": "Portugal",
+		},
+		"books": []any{
+			map[string]any{"title": "Dune", "price": float64(10), "tags": []any{map[string]any{"name": "sf"}, "classic"}},
+			map[string]any{"title": "Emma", "price": float64(10), "discount": nil},
+			map[string]any{"title": "Ulysses", "price": float64(10)},
+		},
+	}
+	if diff := cmp.Diff(expected, document.AsMap()); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestProtoList(t *testing.T) {
+	// arrange
+	list, err := structpb.NewList([]any{1, "a", []any{2, 3}, map[string]any{"b": 4}})
+	if err != nil {
+		t.Fatalf("Failed to create list: %v", err)
+	}
+	var data = FromList(list)
+	var path = "$..[?(@ > 1)]"
+	var expected = []any{float64(2), float64(3), float64(4)}
+	// act
+	result, err := jsonpath.Get(data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
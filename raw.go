@@ -0,0 +1,216 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// rawStep is a step of a simple path evaluated on raw JSON
+type rawStep struct {
+	names     []string // object member names
+	subscript string   // array subscript, e.g. 0, 1:3 or *
+	wildcard  bool     // all object members and array items
+}
+
+// GetRaw evaluates the given JsonPath expression on the JSON document and returns the original bytes of each matching
+// value, the values are not decoded so they can be re-emitted as they are. Only simple paths are supported, i.e. child
+// names, array subscripts and wildcards; filters, recursive descent and property names are rejected. Wildcards return
+// object members in document order. The returned slices share the memory of data.
+func GetRaw(data []byte, expression string) ([]json.RawMessage, error) {
+	// parse steps
+	steps, err := rawSteps(expression)
+	if err != nil {
+		return nil, err
+	}
+	// check document
+	if !json.Valid(data) {
+		return nil, errors.New("invalid JSON document")
+	}
+	// document without surrounding white space
+	document := bytes.TrimSpace(data)
+	// current values (capacity is limited so appending to a value never overwrites the document)
+	values := [][]byte{document[:len(document):len(document)]}
+	// loop steps
+	for _, step := range steps {
+		// matches
+		matches := [][]byte{}
+		// loop values
+		for _, value := range values {
+			// evaluate step on value
+			m, err := step.evaluate(value)
+			if err != nil {
+				return nil, err
+			}
+			matches = append(matches, m...)
+		}
+		values = matches
+	}
+	// result
+	result := make([]json.RawMessage, 0, len(values))
+	// loop values
+	for _, v := range values {
+		// append value
+		result = append(result, json.RawMessage(v))
+	}
+	return result, nil
+}
+
+// rawSteps parses the expression into the steps evaluated by GetRaw
+func rawSteps(expression string) ([]rawStep, error) {
+	// lexer
+	lexer := lex(expression)
+	// steps
+	steps := []rawStep{}
+	// loop lexemes
+	for {
+		// next lexeme
+		token := lexer.nextLexeme()
+		// process token
+		switch token.typ {
+
+		case lexemeError:
+			return nil, errors.New(token.val)
+
+		case lexemeIdentity, lexemeEOF:
+			return steps, nil
+
+		case lexemeRoot:
+			// root value is the document
+
+		case lexemeDotChild, lexemeUndottedChild:
+			// child name (remove '.')
+			childName := strings.TrimPrefix(token.val, ".")
+			// check wildcard
+			if childName == "*" {
+				steps = append(steps, rawStep{wildcard: true})
+				break
+			}
+			steps = append(steps, rawStep{names: []string{unescape(childName)}})
+
+		case lexemeBracketChild:
+			// child names from lexer token
+			childNames := strings.TrimSpace(token.val)
+			childNames = strings.TrimSuffix(strings.TrimPrefix(childNames, "["), "]")
+			// ['a', 'b']
+			steps = append(steps, rawStep{names: bracketChildNames(strings.TrimSpace(childNames))})
+
+		case lexemeArraySubscript:
+			// remove [] from token value
+			subscript := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(token.val, "["), "]"))
+			// check wildcard
+			if subscript == "*" {
+				steps = append(steps, rawStep{wildcard: true})
+				break
+			}
+			steps = append(steps, rawStep{subscript: subscript})
+
+		default:
+			return nil, fmt.Errorf("GetRaw does not support %q in path expression, only child names, array subscripts and wildcards are supported", token.val)
+		}
+	}
+}
+
+// evaluate returns the raw values matched by the step on the raw value
+func (s rawStep) evaluate(value []byte) ([][]byte, error) {
+	// check container
+	if len(value) == 0 || (value[0] != '{' && value[0] != '[') {
+		return nil, nil
+	}
+	// members
+	keys, values, err := rawChildren(value)
+	if err != nil {
+		return nil, err
+	}
+	// check wildcard
+	if s.wildcard {
+		return values, nil
+	}
+	// check object
+	if value[0] == '{' {
+		// array subscripts do not match object members
+		if s.names == nil {
+			return nil, nil
+		}
+		// matches
+		matches := [][]byte{}
+		// loop names
+		for _, name := range s.names {
+			// find last member with name (duplicate names are decoded the same way)
+			for i := len(keys) - 1; i >= 0; i-- {
+				// check name
+				if keys[i] == name {
+					matches = append(matches, values[i])
+					break
+				}
+			}
+		}
+		return matches, nil
+	}
+	// child names do not match array items
+	if s.names != nil {
+		return nil, nil
+	}
+	// indexes
+	indexes, err := slice(s.subscript, len(values))
+	if err != nil {
+		return nil, err
+	}
+	// matches
+	matches := make([][]byte, 0, len(indexes))
+	// loop indexes
+	for _, i := range indexes {
+		matches = append(matches, values[i])
+	}
+	return matches, nil
+}
+
+// rawChildren returns the member names (nil for arrays) and the raw values of the object or array members, each value
+// is a slice of the container bytes
+func rawChildren(container []byte) ([]string, [][]byte, error) {
+	// decoder
+	decoder := json.NewDecoder(bytes.NewReader(container))
+	// consume { or [
+	if _, err := decoder.Token(); err != nil {
+		return nil, nil, err
+	}
+	// object flag
+	object := container[0] == '{'
+	// members
+	var keys []string
+	values := [][]byte{}
+	// loop members
+	for decoder.More() {
+		// check object
+		if object {
+			// member name
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, nil, err
+			}
+			keys = append(keys, key.(string))
+		}
+		// offset before value (it may include separators and white space)
+		start := decoder.InputOffset()
+		// skip value
+		var skip json.RawMessage
+		if err := decoder.Decode(&skip); err != nil {
+			return nil, nil, err
+		}
+		// offset after value
+		end := decoder.InputOffset()
+		// value bytes (capacity is limited so appending to a value never overwrites the document)
+		value := container[start:end:end]
+		// remove separators and white space
+		values = append(values, bytes.TrimLeft(value, " \t\r\n:,"))
+	}
+	return keys, values, nil
+}
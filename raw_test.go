@@ -0,0 +1,117 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetRaw(t *testing.T) {
+	// arrange
+	document := []byte(`{
+  "store": {
+    "name": "main!",
+    "book": [
+      { "title": "Sayings",  "price": 8.95 },
+      { "title": "Sword",    "price": 12.990, "tags": [ "a", "b" ] },
+      { "title": "Moby Dick", "price": 8.99e0 }
+    ],
+    "open": true,
+    "owner": null
+  }
+}`)
+	// test cases
+	tcs := []struct {
+		expression string
+		expected   []string
+	}{
+		{expression: "$.store.name", expected: []string{`"main!"`}},
+		{expression: "$.store.book[1]", expected: []string{`{ "title": "Sword",    "price": 12.990, "tags": [ "a", "b" ] }`}},
+		{expression: "$.store.book[*].price", expected: []string{"8.95", "12.990", "8.99e0"}},
+		{expression: "$.store.book[-1:].title", expected: []string{`"Moby Dick"`}},
+		{expression: "$.store.book[0,2].price", expected: []string{"8.95", "8.99e0"}},
+		{expression: "$.store.book[1].tags", expected: []string{`[ "a", "b" ]`}},
+		{expression: "$.store['open','owner']", expected: []string{"true", "null"}},
+		{expression: "$.store.book[1].*", expected: []string{`"Sword"`, "12.990", `[ "a", "b" ]`}},
+		{expression: "$.store.missing", expected: []string{}},
+		{expression: "$.store.name.length", expected: []string{}},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.expression, func(t *testing.T) {
+			// act
+			result, err := GetRaw(document, tc.expression)
+			if err != nil {
+				t.Fatalf("Failed to get raw values: %v", err)
+			}
+			// raw values as strings
+			values := []string{}
+			for _, r := range result {
+				values = append(values, string(r))
+			}
+			// assert
+			if diff := cmp.Diff(tc.expected, values); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestGetRawSharesDocument(t *testing.T) {
+	// arrange
+	document := []byte(`[ {"a": [1, 2]}, {"a": [3]} ]`)
+	// act
+	result, err := GetRaw(document, "$[*].a")
+	if err != nil {
+		t.Fatalf("Failed to get raw values: %v", err)
+	}
+	// assert
+	if len(result) != 2 {
+		t.Fatalf("Unexpected number of values: %d", len(result))
+	}
+	// loop values
+	for _, r := range result {
+		// value must be the document bytes at its original offset
+		offset := bytes.Index(document, r)
+		if offset < 0 || &document[offset] != &r[0] {
+			t.Errorf("Value %s does not share the document bytes", r)
+		}
+	}
+	// appending to a value must not overwrite the document
+	_ = append(result[0], '!')
+	if string(document) != `[ {"a": [1, 2]}, {"a": [3]} ]` {
+		t.Errorf("Document was modified: %s", document)
+	}
+}
+
+func TestGetRawErrors(t *testing.T) {
+	// test cases
+	tcs := []struct {
+		name       string
+		document   string
+		expression string
+		expected   string
+	}{
+		{name: "filter", document: `[]`, expression: "$[?(@.a)]", expected: `GetRaw does not support "[?(" in path expression, only child names, array subscripts and wildcards are supported`},
+		{name: "recursive descent", document: `{}`, expression: "$..a", expected: `GetRaw does not support "..a" in path expression, only child names, array subscripts and wildcards are supported`},
+		{name: "invalid document", document: `{"a":`, expression: "$.a", expected: "invalid JSON document"},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// act
+			_, err := GetRaw([]byte(tc.document), tc.expression)
+			// assert
+			if err == nil || err.Error() != tc.expected {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+//go:build test
+// +build test
+
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"fmt"
+)
+
+// checkReadOnly panics if a get or locate operation of the path expression produces a set or delete expression, i.e.
+// the evaluation took a code path that modifies the data
+func checkReadOnly(expression pathExpression) pathExpression {
+	return func(operation operation, value, root any) Iterator {
+		// evaluate expression
+		it := expression(operation, value, root)
+		// check read only operation
+		if operation != getOperation && operation != locateOperation {
+			return it
+		}
+		return func() (any, bool) {
+			// next value
+			v, ok := it()
+			// value without location
+			raw := v
+			if l, located := v.(*located); located {
+				raw = l.value
+			}
+			// process value type
+			switch raw.(type) {
+			case setExpression, deleteExpression:
+				panic(fmt.Sprintf("read only operation %d produced %T", operation, raw))
+			}
+			return v, ok
+		}
+	}
+}
@@ -0,0 +1,44 @@
+//go:build test
+// +build test
+
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"testing"
+)
+
+func TestCheckReadOnly(t *testing.T) {
+	// arrange (an expression producing a setter in get operations)
+	var f setExpression = func(value any) {}
+	path := new(func(operation operation, value, root any) Iterator {
+		return FromValues(false, f)
+	})
+	// act
+	defer func() {
+		// assert
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	path.expression(getOperation, nil, nil).ToSlice()
+}
+
+func TestCheckReadOnlySet(t *testing.T) {
+	// arrange (setters are expected in set operations)
+	var f setExpression = func(value any) {}
+	path := new(func(operation operation, value, root any) Iterator {
+		return FromValues(false, f)
+	})
+	// act
+	result := path.expression(setOperation, nil, nil).ToSlice()
+	// assert
+	if len(result) != 1 {
+		t.Errorf("invalid result: %v", result)
+	}
+}
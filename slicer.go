@@ -65,9 +65,9 @@ func slice(index string, length int) ([]int, error) {
 	for i, s := range subscr {
 		s = strings.TrimSpace(s)
 		if s != "" {
-			n, err := strconv.Atoi(s)
+			n, err := parseIndex(s)
 			if err != nil {
-				return nil, errors.New("non-integer array index")
+				return nil, err
 			}
 			subscripts[i] = subscript{
 				present: true,
@@ -128,6 +128,23 @@ func slice(index string, length int) ([]int, error) {
 	return indices(from, to, step, length), nil
 }
 
+// parseIndex parses an optionally negative decimal integer, signs other than '-' and embedded whitespace (e.g. "1 2")
+// are rejected
+func parseIndex(s string) (int, error) {
+	// digits
+	digits := strings.TrimPrefix(s, "-")
+	// check digits
+	if digits == "" || strings.TrimLeft(digits, "0123456789") != "" {
+		return 0, errors.New("non-integer array index")
+	}
+	// parse integer
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.New("array index out of range")
+	}
+	return n, nil
+}
+
 func indices(from, to, step, length int) []int {
 	slice := []int{}
 	if step > 0 {
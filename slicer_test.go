@@ -205,6 +205,36 @@ func TestSlicer(t *testing.T) {
 			length:      10,
 			expectedErr: "non-integer array index",
 		},
+		{
+			name:        "array index with embedded whitespace",
+			index:       "1 2",
+			length:      10,
+			expectedErr: "non-integer array index",
+		},
+		{
+			name:        "array index with plus sign",
+			index:       "+1",
+			length:      10,
+			expectedErr: "non-integer array index",
+		},
+		{
+			name:        "array index with trailing garbage",
+			index:       "1a",
+			length:      10,
+			expectedErr: "non-integer array index",
+		},
+		{
+			name:        "range with empty member and too many colons",
+			index:       "1::2:3",
+			length:      10,
+			expectedErr: "malformed array index, too many colons",
+		},
+		{
+			name:        "array index out of range",
+			index:       "99999999999999999999",
+			length:      10,
+			expectedErr: "array index out of range",
+		},
 		{
 			name:        "zero step",
 			index:       "1:2:0",
@@ -236,6 +266,42 @@ func TestSlicer(t *testing.T) {
 			expected:    []int{1, 0, 1},
 			expectedErr: "",
 		},
+		{
+			name:     "union with negative indices",
+			index:    "-1,-2",
+			length:   5,
+			expected: []int{4, 3},
+		},
+		{
+			name:     "union with negative and positive indices",
+			index:    "-1, 0",
+			length:   5,
+			expected: []int{4, 0},
+		},
+		{
+			name:     "union with negative indices on short array",
+			index:    "-1,-2",
+			length:   2,
+			expected: []int{1, 0},
+		},
+		{
+			name:     "union with negative indices out of range",
+			index:    "-3,-1",
+			length:   2,
+			expected: []int{1},
+		},
+		{
+			name:     "union with negative and positive indices selecting the same item",
+			index:    "-1,0",
+			length:   1,
+			expected: []int{0, 0},
+		},
+		{
+			name:     "union with negative indices on empty array",
+			index:    "-1,-2",
+			length:   0,
+			expected: []int{},
+		},
 		{
 			name:        "union with wildcard and index",
 			index:       "*,1",
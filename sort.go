@@ -0,0 +1,84 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import "sort"
+
+// valueRank returns the position of the value type in the order of sorted results and the typed value used to compare
+// values of the same type: numbers, strings, booleans, null, arrays and objects
+func valueRank(value any) (int, typedValue) {
+	// numbers of any Go type (e.g. json.Number or uint) are compared as float64, containers are not copied
+	if !isContainer(value) {
+		if f, ok := normalizeNumbers(value).(float64); ok {
+			// typed value
+			tv := typedValueOfFloat64(f)
+			// keep raw value
+			tv.raw = value
+			return 0, tv
+		}
+	}
+	// typed value
+	tv := typedValueOfNode(value)
+	// keep raw value
+	tv.raw = value
+	// process value type
+	switch tv.typ {
+	case stringValueType:
+		return 1, tv
+	case booleanValueType:
+		return 2, tv
+	case nullValueType:
+		return 3, tv
+	}
+	// process container type
+	switch tv.typeName() {
+	case "array":
+		return 4, tv
+	case "object":
+		return 5, tv
+	}
+	return 6, tv
+}
+
+// lessValue compares two values of the same rank, numbers numerically, strings lexicographically and false before true,
+// other values are equal
+func lessValue(l, r typedValue) bool {
+	// process value type
+	switch {
+	case l.typ.isNumeric():
+		return mustParseFloat64(l.val) < mustParseFloat64(r.val)
+	case l.typ == stringValueType:
+		return l.val < r.val
+	case l.typ == booleanValueType:
+		return l.val == "false" && r.val == "true"
+	}
+	return false
+}
+
+// sortResult sorts the result by value if requested by the SortByValue option, values of different types are sorted
+// by type (numbers first) whatever the direction
+func (ctx *pathContext) sortResult(result []any) {
+	// check option
+	if !ctx.sortByValue {
+		return
+	}
+	// sort values (equal values keep their order)
+	sort.SliceStable(result, func(i, j int) bool {
+		// ranks
+		ri, vi := valueRank(result[i])
+		rj, vj := valueRank(result[j])
+		// check types
+		if ri != rj {
+			return ri < rj
+		}
+		// check direction
+		if ctx.sortDescending {
+			return lessValue(vj, vi)
+		}
+		return lessValue(vi, vj)
+	})
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"strings"
+)
+
+// StepPath splits the given JsonPath expression into its first segment and the rest of the expression, both returned
+// as expressions relative to $, e.g. $.store.book[0] => $.store and $.book[0]. Evaluating the rest on each value
+// matched by the segment is equivalent to evaluating the whole expression, so a document can be navigated one segment
+// at a time. The rest is empty when the expression has a single segment, both are empty when it has no segments.
+// Note that $ terms in the filters of the rest refer to the value the rest is evaluated on, not the document root.
+func StepPath(expression string) (string, string, error) {
+	// validate expression
+	if _, _, err := compile(expression, nil); err != nil {
+		return "", "", err
+	}
+	// segments
+	segments := pathSegments(lex(expression))
+	// check segments
+	switch len(segments) {
+	case 0:
+		return "", "", nil
+	case 1:
+		return root + segments[0], "", nil
+	default:
+		return root + segments[0], root + strings.Join(segments[1:], ""), nil
+	}
+}
+
+// pathSegments returns the segments of the path scanned by the lexer, each segment is a child, array access, pipe
+// decoder or filter (including a preceding recursive descent without child name)
+func pathSegments(lexer *lexer) []string {
+	// segments
+	segments := []string{}
+	// current segment
+	var segment strings.Builder
+	// filter nesting level
+	filterNestingLevel := 0
+	// loop lexer tokens
+	for {
+		// next lexer token
+		token := lexer.nextLexeme()
+		// process token type
+		switch token.typ {
+
+		case lexemeIdentity, lexemeEOF, lexemeError:
+			return segments
+
+		case lexemeRoot:
+			// check root is part of a filter
+			if filterNestingLevel == 0 {
+				continue
+			}
+
+		case lexemeUndottedChild:
+			// undotted child at the start of the path
+			segment.WriteString(dot)
+
+		case lexemeFilterBegin, lexemeRecursiveFilterBegin:
+			filterNestingLevel++
+
+		case lexemeFilterEnd:
+			filterNestingLevel--
+		}
+		// append token to segment
+		segment.WriteString(token.val)
+		// check segment is complete (recursive descent without child name is part of the next segment)
+		if filterNestingLevel == 0 && !(token.typ == lexemeRecursiveDescent && token.val == recursiveDescent) {
+			// append segment
+			segments = append(segments, segment.String())
+			// reset segment
+			segment.Reset()
+		}
+	}
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStepPath(t *testing.T) {
+	// arrange
+	cases := []struct {
+		expression string
+		segment    string
+		rest       string
+	}{
+		{expression: "$", segment: "", rest: ""},
+		{expression: "$.a", segment: "$.a", rest: ""},
+		{expression: "$.store.book[0]", segment: "$.store", rest: "$.book[0]"},
+		{expression: "store.book", segment: "$.store", rest: "$.book"},
+		{expression: "$['a','b'][1:3]", segment: "$['a','b']", rest: "$[1:3]"},
+		{expression: "$..[0].a", segment: "$..[0]", rest: "$.a"},
+		{expression: "$..book[?(@.price > 10)].title", segment: "$..book", rest: "$[?(@.price>10)].title"},
+		{expression: "$[?(@.tags[?(@ == 'x')])].a", segment: "$[?(@.tags[?(@=='x')])]", rest: "$.a"},
+	}
+	for _, tc := range cases {
+		// act
+		segment, rest, err := StepPath(tc.expression)
+		// assert
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tc.expression, err)
+		}
+		if diff := cmp.Diff([]string{tc.segment, tc.rest}, []string{segment, rest}); diff != "" {
+			t.Errorf("invalid result for %s: %s", tc.expression, diff)
+		}
+	}
+}
+
+func TestStepPathNavigation(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"store": map[string]any{
+			"book": []any{
+				map[string]any{"title": "a", "price": 5},
+				map[string]any{"title": "b", "price": 15},
+			},
+		},
+	}
+	var expression = "$..book[?(@.price > 10)].title"
+	expected, err := Get(data, expression)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	// act
+	values := []any{data}
+	for rest := expression; rest != ""; {
+		// next segment
+		var segment string
+		segment, rest, err = StepPath(rest)
+		if err != nil {
+			t.Errorf("Failed to step path: %v", err)
+		}
+		// evaluate segment on current values
+		path, err := NewPath(segment)
+		if err != nil {
+			t.Errorf("invalid path: %s", err)
+		}
+		next := []any{}
+		for _, value := range values {
+			next = append(next, path.Evaluate(value)...)
+		}
+		values = next
+	}
+	// assert
+	if diff := cmp.Diff(expected, values); diff != "" {
+		t.Errorf("invalid result: %s", diff)
+	}
+}
+
+func TestStepPathInvalid(t *testing.T) {
+	// act
+	_, _, err := StepPath("$[0")
+	// assert
+	if err == nil || err.Error() != `unmatched [ at position 3, following "$[0"` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
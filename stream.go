@@ -0,0 +1,148 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// GetStreamArray evaluates the given JsonPath expression on the JSON document read from r and calls fn with each
+// matching value, it stops and returns the error returned by fn. When the document is an array and the expression
+// starts with a wildcard or a filter, e.g. $[?(@.level == 'ERROR')], the array items are decoded and evaluated one at
+// a time without holding the whole array in memory. Other documents and expressions, including expressions referring
+// to the whole array or to the item indexes (i.e. using $, @^, @^^ or @@index filter terms) and expressions ending
+// with .count(), are fully decoded before the evaluation.
+func GetStreamArray(r io.Reader, expression string, fn func(value any) error, options ...Option) error {
+	// create context and Path
+	ctx, path, err := compile(expression, options)
+	if err != nil {
+		return err
+	}
+	// buffered reader
+	reader := bufio.NewReader(r)
+	// check document is an array that can be streamed
+	if streamsArrayItems(expression) && peekNonSpace(reader) == '[' {
+		// decoder
+		decoder := json.NewDecoder(reader)
+		// consume [
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+		// loop array items
+		for decoder.More() {
+			// decode item
+			var item any
+			if err := decoder.Decode(&item); err != nil {
+				return err
+			}
+			// evaluate path on an array holding the item
+			if err := streamValues(ctx, path, []any{item}, fn); err != nil {
+				return err
+			}
+		}
+		// consume ]
+		_, err := decoder.Token()
+		return err
+	}
+	// decode document
+	var data any
+	if err := json.NewDecoder(reader).Decode(&data); err != nil {
+		return err
+	}
+	return streamValues(ctx, path, data, fn)
+}
+
+// streamsArrayItems checks whether the expression evaluates each item of the root array independently, i.e. it starts
+// with a wildcard or a filter, its filters do not refer to the root array or to the item indexes and it does not count
+// values
+func streamsArrayItems(expression string) bool {
+	// lexer
+	lexer := lex(expression)
+	// first lexeme must be root
+	if lexer.nextLexeme().typ != lexemeRoot {
+		return false
+	}
+	// process second lexeme
+	token := lexer.nextLexeme()
+	switch token.typ {
+
+	case lexemeFilterBegin:
+
+	case lexemeArraySubscript:
+		// remove [] from token value
+		subscript := strings.TrimSuffix(strings.TrimPrefix(token.val, "["), "]")
+		// wildcard
+		if strings.TrimSpace(subscript) != "*" {
+			return false
+		}
+
+	default:
+		return false
+	}
+	// loop remaining lexemes
+	for {
+		// process lexeme
+		switch lexer.nextLexeme().typ {
+
+		case lexemeEOF:
+			return true
+
+		case lexemeError, lexemeRoot, lexemeFilterParent, lexemeFilterGrandparent:
+			// the root or the parent of a filtered value may be the whole array
+			return false
+
+		case lexemeFilterIndex:
+			// the index of a filtered value may be the index of an item of the whole array
+			return false
+
+		case lexemeCount:
+			// count() counts the items of the whole array
+			return false
+		}
+	}
+}
+
+// peekNonSpace skips the leading white space in reader and returns the next byte without consuming it, 0 if there
+// are no more bytes
+func peekNonSpace(reader *bufio.Reader) byte {
+	// loop bytes
+	for {
+		// peek next byte
+		b, err := reader.Peek(1)
+		if err != nil {
+			return 0
+		}
+		// check JSON white space
+		if b[0] != ' ' && b[0] != '\t' && b[0] != '\n' && b[0] != '\r' {
+			return b[0]
+		}
+		// skip white space
+		_, _ = reader.ReadByte()
+	}
+}
+
+// streamValues evaluates the path on data and calls fn with each matching value
+func streamValues(ctx *pathContext, path *Path, data any, fn func(value any) error) error {
+	// evaluate it
+	it := path.expression(getOperation, data, data)
+	// loop iterator
+	for v, ok := it(); ok; v, ok = it() {
+		// check evaluation errors
+		if err := ctx.checkErrors(); err != nil {
+			return err
+		}
+		// call fn
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+	// check evaluation errors
+	return ctx.checkErrors()
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetStreamArray(t *testing.T) {
+	// test cases
+	tcs := []struct {
+		name       string
+		document   string
+		expression string
+		expected   []any
+	}{
+		{
+			name:       "filter on array items",
+			document:   ` [{"level":"INFO","msg":"a"},{"level":"ERROR","msg":"b"},{"level":"ERROR","msg":"c"}]`,
+			expression: "$[?(@.level == 'ERROR')].msg",
+			expected:   []any{"b", "c"},
+		},
+		{
+			name:       "wildcard on array items",
+			document:   `[{"a":1},{"b":2},{"a":3}]`,
+			expression: "$[*].a",
+			expected:   []any{1.0, 3.0},
+		},
+		{
+			name:       "index on array falls back to full decode",
+			document:   `[{"a":1},{"a":2}]`,
+			expression: "$[1].a",
+			expected:   []any{2.0},
+		},
+		{
+			name:       "object root falls back to full decode",
+			document:   `{"items":[{"a":1},{"a":2}]}`,
+			expression: "$.items[*].a",
+			expected:   []any{1.0, 2.0},
+		},
+		{
+			name:       "root term falls back to full decode",
+			document:   `[1, 2, 3]`,
+			expression: "$[?(@ == $[0])]",
+			expected:   []any{1.0},
+		},
+		{
+			name:       "parent term falls back to full decode",
+			document:   `[{"a":1},{"a":2}]`,
+			expression: "$[?(@.a > @^[0].a)].a",
+			expected:   []any{2.0},
+		},
+		{
+			name:       "array index term falls back to full decode",
+			document:   `[1, 2, 3]`,
+			expression: "$[?(@@index > 0)]",
+			expected:   []any{2.0, 3.0},
+		},
+		{
+			name:       "count falls back to full decode",
+			document:   `[1, 2, 3]`,
+			expression: "$[*].count()",
+			expected:   []any{3},
+		},
+		{
+			name:       "empty array",
+			document:   `[]`,
+			expression: "$[*]",
+			expected:   []any{},
+		},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			result := []any{}
+			// act
+			err := GetStreamArray(strings.NewReader(tc.document), tc.expression, func(value any) error {
+				result = append(result, value)
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Failed to get values: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestGetStreamArrayStops(t *testing.T) {
+	// arrange
+	var stop = errors.New("stop")
+	var count = 0
+	// act (the malformed item after the first one is never decoded)
+	err := GetStreamArray(strings.NewReader(`[1, 2, x]`), "$[*]", func(value any) error {
+		count++
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Unexpected count: %d", count)
+	}
+}
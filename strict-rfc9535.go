@@ -0,0 +1,113 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// rfc9535Extensions describes the lexemes of the syntax extensions not defined by RFC 9535
+var rfc9535Extensions = map[lexemeType]string{
+	lexemePropertyName:                   "property name selector (~)",
+	lexemeBracketPropertyName:            "property name selector (~)",
+	lexemeArraySubscriptPropertyName:     "property name selector (~)",
+	lexemeFilterPropertyName:             "property name selector (~)",
+	lexemePipeDecoder:                    "pipe decoder (|)",
+	lexemeCount:                          "count() selector",
+	lexemeFilterMatchesRegularExpression: "regular expression match (=~)",
+	lexemeFilterAll:                      "all() filter",
+	lexemeFilterAny:                      "any() filter",
+	lexemeFilterParent:                   "parent reference (@^)",
+	lexemeFilterGrandparent:              "grandparent reference (@^^)",
+	lexemeFilterTime:                     "time() function",
+	lexemeFilterMin:                      "min() function",
+	lexemeFilterMax:                      "max() function",
+	lexemeFilterAvg:                      "avg() function",
+	lexemeFilterNormalize:                "normalize() function",
+	lexemeFilterVersion:                  "version() function",
+	lexemeFilterByteLength:               "bytelength() function",
+	lexemeFilterText:                     "text() function",
+	lexemeFilterKeys:                     "member names (@~)",
+	lexemeFilterIndex:                    "array index (@@index)",
+	lexemeFilterIn:                       "set membership (in)",
+	lexemeFilterIs:                       "type test (is)",
+}
+
+// checkRFC9535 checks the expression only uses the syntax defined by RFC 9535, if strict RFC 9535 syntax was requested
+func (ctx *pathContext) checkRFC9535(expression string) error {
+	// check strict syntax
+	if !ctx.strictRFC9535 {
+		return nil
+	}
+	// queries start with the root identifier (the lexer adds it to relative paths)
+	if !strings.HasPrefix(trimPath(expression), root) {
+		return fmt.Errorf("RFC 9535 paths must start with %q", root)
+	}
+	// create lexer
+	lexer := lex(expression)
+	// strings can be ordered only when a custom comparison is provided or they are compared as timestamps
+	lexer.orderedStrings = ctx.compare != nil || ctx.compareTimes
+	// loop lexemes
+	for {
+		// next lexeme
+		token := lexer.nextLexeme()
+		// process token type
+		switch token.typ {
+
+		case lexemeError:
+			return errors.New(token.val)
+
+		case lexemeEOF:
+			return nil
+		}
+		// check extension
+		if extension, ok := rfc9535Extensions[token.typ]; ok {
+			return fmt.Errorf("%s is not supported by RFC 9535: %q", extension, token.val)
+		}
+		// process token type
+		switch token.typ {
+
+		case lexemeDotChild:
+			// .name
+			if name := strings.TrimPrefix(token.val, dot); !rfc9535MemberName(name) {
+				return fmt.Errorf("invalid RFC 9535 member name shorthand: %q", token.val)
+			}
+
+		case lexemeRecursiveDescent:
+			// ..name
+			name := strings.TrimPrefix(token.val, recursiveDescent)
+			// check property name (the ~ is not escaped)
+			if strings.HasSuffix(name, propertyName) && !strings.HasSuffix(name, `\`+propertyName) {
+				return fmt.Errorf("property name selector (~) is not supported by RFC 9535: %q", token.val)
+			}
+			// .. followed by brackets
+			if name != "" && !rfc9535MemberName(name) {
+				return fmt.Errorf("invalid RFC 9535 member name shorthand: %q", token.val)
+			}
+		}
+	}
+}
+
+// rfc9535MemberName checks the name is a wildcard or a member name shorthand as defined by RFC 9535, i.e. a letter,
+// an underscore or a non ASCII character followed by any of them or digits
+func rfc9535MemberName(name string) bool {
+	// check wildcard
+	if name == "*" {
+		return true
+	}
+	// loop runes
+	for i, r := range name {
+		// check rune
+		if r != '_' && r <= unicode.MaxASCII && !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z') && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return name != ""
+}
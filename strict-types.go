@@ -0,0 +1,78 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrIncompatibleTypes is returned (wrapped) when the StrictTypes option is used and a filter compares values of
+// incompatible types, e.g. a string and a number.
+var ErrIncompatibleTypes = errors.New("incompatible types in filter comparison")
+
+// typeErrors records the first comparison of incompatible types found while evaluating a path
+type typeErrors struct {
+	mutex sync.Mutex
+	err   error
+}
+
+// record records the comparison of l and r unless an error has already been recorded
+func (t *typeErrors) record(operator string, l, r typedValue) {
+	// lock
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	// keep first error
+	if t.err == nil {
+		t.err = fmt.Errorf("%w: %s %s %s", ErrIncompatibleTypes, describe(l), operator, describe(r))
+	}
+}
+
+// describe returns the type name and value of the operand, e.g. string 'x'
+func describe(tv typedValue) string {
+	// process value type
+	switch tv.typ {
+	case stringValueType:
+		return fmt.Sprintf("string '%s'", tv.val)
+	case intValueType, floatValueType, booleanValueType:
+		return fmt.Sprintf("%s %s", tv.typeName(), tv.val)
+	case versionValueType:
+		return fmt.Sprintf("version %s", tv.val)
+	}
+	// arrays and objects
+	if name := tv.typeName(); name != "" {
+		return name
+	}
+	return "value"
+}
+
+// checkTypes returns the first comparison of incompatible types found while evaluating a path (if any)
+func (ctx *pathContext) checkTypes() error {
+	// check strict types
+	if ctx.typeErrors == nil {
+		return nil
+	}
+	// lock
+	ctx.typeErrors.mutex.Lock()
+	defer ctx.typeErrors.mutex.Unlock()
+	return ctx.typeErrors.err
+}
+
+// checkErrors returns the errors found while evaluating a path, i.e. an expired deadline, an exceeded budget or a
+// comparison of incompatible types
+func (ctx *pathContext) checkErrors() error {
+	// check deadline
+	if err := ctx.checkTimeout(); err != nil {
+		return err
+	}
+	// check budget
+	if err := ctx.checkBudget(); err != nil {
+		return err
+	}
+	return ctx.checkTypes()
+}
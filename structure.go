@@ -0,0 +1,97 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"sort"
+)
+
+// structureNode is a value in the document rebuilt by EvaluateToStructure, either a matching value or a container
+// holding the matching values below it
+type structureNode struct {
+	matched  bool
+	value    any
+	members  map[string]*structureNode
+	items    map[int]*structureNode
+	isObject bool
+}
+
+// add adds the matching value at the location keys below this node
+func (n *structureNode) add(keys []any, value any) {
+	// check node is already matched (the whole value is kept)
+	if n.matched {
+		return
+	}
+	// check location is this node
+	if len(keys) == 0 {
+		// update state
+		n.matched = true
+		n.value = value
+		n.members = nil
+		n.items = nil
+		return
+	}
+	// child node
+	var child *structureNode
+	// process key type
+	switch k := keys[0].(type) {
+
+	case string:
+		// object member
+		n.isObject = true
+		if n.members == nil {
+			n.members = map[string]*structureNode{}
+		}
+		if child = n.members[k]; child == nil {
+			child = &structureNode{}
+			n.members[k] = child
+		}
+
+	case int:
+		// array item
+		if n.items == nil {
+			n.items = map[int]*structureNode{}
+		}
+		if child = n.items[k]; child == nil {
+			child = &structureNode{}
+			n.items[k] = child
+		}
+	}
+	// add value below child
+	child.add(keys[1:], value)
+}
+
+// build returns the value of this node, containers are rebuilt from their children
+func (n *structureNode) build() any {
+	// check node is matched
+	if n.matched {
+		return n.value
+	}
+	// check object
+	if n.isObject {
+		// object
+		object := make(map[string]any, len(n.members))
+		// loop members
+		for k, child := range n.members {
+			object[k] = child.build()
+		}
+		return object
+	}
+	// indexes in document order
+	indexes := make([]int, 0, len(n.items))
+	for i := range n.items {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	// array
+	array := make([]any, 0, len(indexes))
+	// loop items
+	for _, i := range indexes {
+		array = append(array, n.items[i].build())
+	}
+	return array
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"sync"
+)
+
+// syncMap stores the members of a sync.Map, adapted to the Map interface by anyKeyMap
+type syncMap struct {
+	m *sync.Map
+}
+
+// FromSyncMap adapts a sync.Map (e.g. configuration shared by goroutines) to the Map interface so it can be queried
+// and updated with JsonPath expressions. Keys that are not strings are converted to strings using fmt.Sprint and
+// wildcards enumerate members in sorted key order. Values holding a *sync.Map are adapted as well.
+func FromSyncMap(m *sync.Map) Map {
+	return anyKeyMap{syncMap{m: m}}
+}
+
+func (m syncMap) rangeKeys(fn func(key any) bool) {
+	// loop map
+	m.m.Range(func(k, _ any) bool {
+		return fn(k)
+	})
+}
+
+func (m syncMap) load(key any) (any, bool) {
+	// find key in map
+	v, ok := m.m.Load(key)
+	// check sync.Map (nested maps are adapted)
+	if sm, isSyncMap := v.(*sync.Map); isSyncMap {
+		return FromSyncMap(sm), ok
+	}
+	return v, ok
+}
+
+func (m syncMap) store(key, value any) {
+	m.m.Store(key, value)
+}
+
+func (m syncMap) delete(key any) {
+	m.m.Delete(key)
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSyncMapSet(t *testing.T) {
+	// arrange
+	var server, config sync.Map
+	server.Store("port", 8080)
+	config.Store("server", &server)
+	config.Store("timeouts", []any{5, 30})
+	config.Store(80, "http")
+	var data = FromSyncMap(&config)
+	// act (nested maps and non string keys are updated in place)
+	if err := Set(data, "$.server.port", 9090); err != nil {
+		t.Errorf("Failed to set value: %v", err)
+	}
+	if err := Set(data, "$['80']", "https"); err != nil {
+		t.Errorf("Failed to set value: %v", err)
+	}
+	if _, err := Prune(data, []string{"$.timeouts"}); err != nil {
+		t.Errorf("Failed to prune value: %v", err)
+	}
+	// assert
+	if port, _ := server.Load("port"); port != 9090 {
+		t.Errorf("Unexpected port: %v", port)
+	}
+	if scheme, _ := config.Load(80); scheme != "https" {
+		t.Errorf("Unexpected scheme: %v", scheme)
+	}
+	if _, ok := config.Load("timeouts"); ok {
+		t.Error("Unexpected timeouts")
+	}
+}
+
+func TestSyncMapCollidingKeys(t *testing.T) {
+	// arrange (1 and "1" have the same member name)
+	var config sync.Map
+	config.Store(1, "int")
+	config.Store("1", "string")
+	config.Store(2, "other")
+	var data = FromSyncMap(&config)
+	// test cases
+	tcs := []struct {
+		path     string
+		expected any
+	}{
+		{path: "$.*", expected: []any{"string", "other"}},
+		{path: "$[*]~", expected: []any{"1", "2"}},
+		{path: "$['1']", expected: "string"},
+		{path: "$['2']", expected: "other"},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			// act
+			result, err := Get(data, tc.path)
+			if err != nil {
+				t.Errorf("Failed to get value: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestSyncMapConcurrentMutation(t *testing.T) {
+	// arrange
+	var config sync.Map
+	for i := 0; i < 100; i++ {
+		config.Store(i, i)
+	}
+	var data = FromSyncMap(&config)
+	// mutate members while they are enumerated
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			// delete and restore a member, store a new one
+			config.Delete(i % 100)
+			config.Store(i%100, i%100)
+			config.Store(fmt.Sprint("k", i), fmt.Sprint("v", i))
+		}
+	}()
+	// act
+	for i := 0; i < 100; i++ {
+		result, err := Get(data, "$.*")
+		if err != nil {
+			t.Errorf("Failed to get value: %v", err)
+		}
+		// assert (members deleted during the enumeration are skipped, the others are listed once)
+		values := result.([]any)
+		if len(values) == 0 {
+			t.Error("Expected values")
+		}
+		seen := map[any]bool{}
+		for _, v := range values {
+			if seen[v] {
+				t.Errorf("Duplicate value: %v", v)
+			}
+			seen[v] = true
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrTimeout is returned when the evaluation of a JsonPath expression takes longer than allowed by GetTimeout.
+var ErrTimeout = errors.New("evaluation timeout exceeded")
+
+// GetTimeout evaluates the given JsonPath expression on the input data like Get and returns the matching values as a
+// list, the evaluation is aborted with ErrTimeout if it takes longer than d (path compilation included). The deadline
+// is checked while the values are evaluated, an evaluation completing as d expires returns its values. It is a
+// guardrail for untrusted expressions or documents.
+func GetTimeout(data any, expression string, d time.Duration, options ...Option) ([]any, error) {
+	// deadline
+	deadline, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	// options (the caller slice is not modified)
+	options = append(options[:len(options):len(options)], AlwaysReturnList(), withDeadline(deadline))
+	// evaluate expression
+	result, err := Get(data, expression, options...)
+	if err != nil {
+		return nil, err
+	}
+	return result.([]any), nil
+}
+
+// GetPartial evaluates the given JsonPath expression on the input data and returns the matching values found before
+// ctx is done, truncated reports whether the evaluation was cut short. It is meant for best-effort extraction from
+// huge documents, an expired ctx is not an error.
+func GetPartial(ctx context.Context, data any, expression string, options ...Option) (result []any, truncated bool, err error) {
+	// create context and Path (the caller slice is not modified)
+	pathCtx, path, err := compile(expression, append(options[:len(options):len(options)], withDeadline(ctx)))
+	if err != nil {
+		return nil, false, err
+	}
+	// evaluate it
+	it := path.expression(getOperation, data, data)
+	// collect results (the evaluation stops once ctx is done)
+	result = []any{}
+	for v, ok := it(); ok; v, ok = it() {
+		result = append(result, v)
+	}
+	// check evaluation errors (an expired ctx truncates the result)
+	if err := pathCtx.checkBudget(); err != nil {
+		return nil, false, err
+	}
+	if err := pathCtx.checkTypes(); err != nil {
+		return nil, false, err
+	}
+	// post-process result
+	pathCtx.postProcess(result)
+	return result, pathCtx.deadline.truncated.Load(), nil
+}
+
+// withDeadline aborts the evaluation once the given context is done
+func withDeadline(deadline context.Context) Option {
+	return Option{
+		setup: func(ctx *pathContext) {
+			ctx.deadline = &timeLimit{deadline: deadline}
+		},
+	}
+}
+
+// timeLimit aborts the evaluation once the deadline expires, it records whether values were cut short so an evaluation
+// completing as the deadline expires is not reported as timed out
+type timeLimit struct {
+	deadline  context.Context
+	truncated atomic.Bool
+}
+
+// limit evaluates the path on every node until the deadline expires, the path matches nothing afterwards
+func (t *timeLimit) limit(path *Path) *Path {
+	return &Path{
+		expression: func(operation operation, value, root any) Iterator {
+			// check deadline (the value is not evaluated)
+			if t.deadline.Err() != nil {
+				t.truncated.Store(true)
+				return empty(operation, value, root)
+			}
+			return path.expression(operation, value, root)
+		},
+		terminal: path.terminal,
+	}
+}
+
+// stop returns an iterator over the values in it that ends once the deadline expires
+func (t *timeLimit) stop(it Iterator) Iterator {
+	return func() (any, bool) {
+		// check deadline
+		if t.deadline.Err() != nil {
+			// check values are left (path steps match nothing once the deadline expired, so this ends quickly)
+			if _, ok := it(); ok {
+				t.truncated.Store(true)
+			}
+			return nil, false
+		}
+		return it()
+	}
+}
+
+// checkTimeout returns ErrTimeout if the deadline expired before the evaluation completed
+func (ctx *pathContext) checkTimeout() error {
+	// check deadline
+	if ctx.deadline != nil && ctx.deadline.truncated.Load() {
+		return ErrTimeout
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// GetTyped evaluates the given JsonPath expression on the input data and returns the matching values converted to T,
+// e.g. GetTyped[string](data, "$..name") returns a []string. Numbers are converted to a numeric T when their value is
+// preserved: integers are converted to floating point types, floating point numbers are converted to integer types
+// only when they are whole (so 2.0 is converted to int but 2.5 is not) and values out of the range of T are not
+// converted. Null values are converted only when T is an interface type. An error is returned on the first value that
+// cannot be converted, unless the SkipTypeMismatches option is used.
+func GetTyped[T any](data any, expression string, options ...Option) ([]T, error) {
+	// create context and Path
+	ctx, path, err := compile(expression, options)
+	if err != nil {
+		return nil, err
+	}
+	// evaluate it
+	values := path.expression(getOperation, data, data).ToSlice()
+	// check evaluation errors
+	if err := ctx.checkErrors(); err != nil {
+		return nil, err
+	}
+	// post-process values
+	ctx.postProcess(values)
+	// result
+	result := make([]T, 0, len(values))
+	// loop values
+	for _, v := range values {
+		// convert value
+		t, ok := convertTyped[T](v)
+		if !ok {
+			// check mismatches are skipped
+			if ctx.skipTypeMismatches {
+				continue
+			}
+			return nil, fmt.Errorf("path %s matched a %T value, expected %s", expression, v, reflect.TypeOf((*T)(nil)).Elem())
+		}
+		// append value
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+// convertTyped converts the value to T, numbers are converted to numeric types when their value is preserved
+func convertTyped[T any](value any) (T, bool) {
+	// check value type
+	if t, ok := value.(T); ok {
+		return t, true
+	}
+	// zero value
+	var zero T
+	// target value
+	target := reflect.ValueOf(&zero).Elem()
+	// check null value
+	if value == nil {
+		// null is the zero value of interfaces
+		return zero, target.Kind() == reflect.Interface
+	}
+	// check number
+	source, ok := numberValue(value)
+	if !ok || !convertNumber(source, target) {
+		return zero, false
+	}
+	return zero, true
+}
+
+// numberValue returns the reflected number held by the value, json.Number values are parsed
+func numberValue(value any) (reflect.Value, bool) {
+	// check json.Number
+	if n, ok := value.(json.Number); ok {
+		// parse integer
+		if i, err := n.Int64(); err == nil {
+			return reflect.ValueOf(i), true
+		}
+		// parse floating point number
+		if f, err := n.Float64(); err == nil {
+			return reflect.ValueOf(f), true
+		}
+		return reflect.Value{}, false
+	}
+	// reflected value
+	v := reflect.ValueOf(value)
+	// process value kind
+	switch v.Kind() {
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return v, true
+
+	default:
+		return reflect.Value{}, false
+	}
+}
+
+// convertNumber sets the target to the source number if the target type can hold its value
+func convertNumber(source, target reflect.Value) bool {
+	// process target kind
+	switch target.Kind() {
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// signed value
+		var n int64
+		switch source.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = source.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			// check int64 range
+			if source.Uint() > math.MaxInt64 {
+				return false
+			}
+			n = int64(source.Uint())
+		default:
+			// floating point number must be whole and within the int64 range
+			f := source.Float()
+			if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+				return false
+			}
+			n = int64(f)
+		}
+		// check range
+		if target.OverflowInt(n) {
+			return false
+		}
+		target.SetInt(n)
+		return true
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		// unsigned value
+		var n uint64
+		switch source.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			// check sign
+			if source.Int() < 0 {
+				return false
+			}
+			n = uint64(source.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n = source.Uint()
+		default:
+			// floating point number must be whole and within the uint64 range
+			f := source.Float()
+			if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+				return false
+			}
+			n = uint64(f)
+		}
+		// check range
+		if target.OverflowUint(n) {
+			return false
+		}
+		target.SetUint(n)
+		return true
+
+	case reflect.Float32, reflect.Float64:
+		// floating point value
+		var f float64
+		switch source.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f = float64(source.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			f = float64(source.Uint())
+		default:
+			f = source.Float()
+		}
+		// check range
+		if target.OverflowFloat(f) {
+			return false
+		}
+		target.SetFloat(f)
+		return true
+
+	default:
+		return false
+	}
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetTypedStrings(t *testing.T) {
+	// arrange
+	var data = map[string]any{
+		"store": map[string]any{
+			"book": []any{
+				map[string]any{"name": "Dune"},
+				map[string]any{"name": "Emma"},
+			},
+		},
+	}
+	var path = "$..name"
+	var expected = []string{"Dune", "Emma"}
+	// act
+	result, err := GetTyped[string](data, path, SortByValue(false))
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestGetTypedNumbers(t *testing.T) {
+	// arrange
+	var data = []any{1, 2.0, int64(3), json.Number("4"), uint8(5)}
+	// act
+	ints, err := GetTyped[int](data, "$[*]")
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	floats, err := GetTyped[float64](data, "$[*]")
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	// assert
+	if diff := cmp.Diff([]int{1, 2, 3, 4, 5}, ints); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+	if diff := cmp.Diff([]float64{1, 2, 3, 4, 5}, floats); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestGetTypedMismatch(t *testing.T) {
+	// arrange
+	var data = []any{1, 2.5, "three", nil, -1, 300, true}
+	// test cases
+	tcs := []struct {
+		name     string
+		get      func(options ...Option) (any, error)
+		expected any
+		err      string
+	}{
+		{
+			name:     "fractional number to int",
+			get:      func(options ...Option) (any, error) { return GetTyped[int](data, "$[0,1]", options...) },
+			expected: []int{1},
+			err:      "path $[0,1] matched a float64 value, expected int",
+		},
+		{
+			name:     "string to int",
+			get:      func(options ...Option) (any, error) { return GetTyped[int](data, "$[0,2]", options...) },
+			expected: []int{1},
+			err:      "path $[0,2] matched a string value, expected int",
+		},
+		{
+			name:     "null to string",
+			get:      func(options ...Option) (any, error) { return GetTyped[string](data, "$[2,3]", options...) },
+			expected: []string{"three"},
+			err:      "path $[2,3] matched a <nil> value, expected string",
+		},
+		{
+			name:     "negative number to uint",
+			get:      func(options ...Option) (any, error) { return GetTyped[uint](data, "$[0,4]", options...) },
+			expected: []uint{1},
+			err:      "path $[0,4] matched a int value, expected uint",
+		},
+		{
+			name:     "number out of range",
+			get:      func(options ...Option) (any, error) { return GetTyped[int8](data, "$[0,5]", options...) },
+			expected: []int8{1},
+			err:      "path $[0,5] matched a int value, expected int8",
+		},
+		{
+			name:     "boolean to number",
+			get:      func(options ...Option) (any, error) { return GetTyped[float64](data, "$[1,6]", options...) },
+			expected: []float64{2.5},
+			err:      "path $[1,6] matched a bool value, expected float64",
+		},
+	}
+	// loop test cases
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// act
+			_, err := tc.get()
+			result, skipErr := tc.get(SkipTypeMismatches())
+			// assert
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if skipErr != nil {
+				t.Errorf("Failed to get value: %v", skipErr)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("Unexpected result: %v", diff)
+			}
+		})
+	}
+}
+
+func TestGetTypedAny(t *testing.T) {
+	// arrange
+	var data = []any{1, nil, map[string]any{"a": 1}}
+	var path = "$[*]"
+	var expected = []any{1, nil, map[string]any{"a": 1}}
+	// act
+	result, err := GetTyped[any](data, path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
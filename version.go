@@ -0,0 +1,151 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import "strings"
+
+// version is a semantic version (see https://semver.org), build metadata is not kept since it does not affect
+// precedence
+type version struct {
+	core       [3]string // major, minor and patch numbers
+	prerelease []string  // pre-release identifiers
+}
+
+// parseVersion parses a semantic version, e.g. 1.2.0, 1.2.0-rc.1 or 1.2.0+build.5, an optional v prefix is accepted.
+// It returns false if the string is not a valid semantic version.
+func parseVersion(s string) (version, bool) {
+	// version
+	v := version{}
+	// strip prefix
+	s = strings.TrimPrefix(s, "v")
+	// strip build metadata
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		// check build identifiers
+		for _, id := range strings.Split(s[i+1:], ".") {
+			if !versionIdentifier(id) {
+				return v, false
+			}
+		}
+		s = s[:i]
+	}
+	// pre-release identifiers
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		// loop identifiers
+		for _, id := range strings.Split(s[i+1:], ".") {
+			// check identifier (numeric identifiers must not have leading zeros)
+			if !versionIdentifier(id) || versionNumber(id) && !canonicalNumber(id) {
+				return v, false
+			}
+			v.prerelease = append(v.prerelease, id)
+		}
+		s = s[:i]
+	}
+	// major, minor and patch
+	core := strings.Split(s, ".")
+	if len(core) != 3 {
+		return v, false
+	}
+	// loop numbers
+	for i, n := range core {
+		// check number
+		if !versionNumber(n) || !canonicalNumber(n) {
+			return v, false
+		}
+		v.core[i] = n
+	}
+	return v, true
+}
+
+// versionIdentifier checks the identifier is made of ASCII alphanumerics and hyphens
+func versionIdentifier(id string) bool {
+	// loop bytes
+	for i := 0; i < len(id); i++ {
+		// check byte
+		if c := id[i]; c != '-' && !('0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+			return false
+		}
+	}
+	return id != ""
+}
+
+// versionNumber checks the identifier is made of digits only
+func versionNumber(id string) bool {
+	// loop bytes
+	for i := 0; i < len(id); i++ {
+		// check byte
+		if id[i] < '0' || id[i] > '9' {
+			return false
+		}
+	}
+	return id != ""
+}
+
+// canonicalNumber checks the number has no leading zeros
+func canonicalNumber(n string) bool {
+	return n == "0" || n[0] != '0'
+}
+
+// compareNumbers compares two numeric identifiers of any length
+func compareNumbers(a, b string) comparison {
+	// longer numbers are greater (there are no leading zeros)
+	switch {
+	case len(a) < len(b):
+		return compareLessThan
+	case len(a) > len(b):
+		return compareGreaterThan
+	case a < b:
+		return compareLessThan
+	case a > b:
+		return compareGreaterThan
+	}
+	return compareEqual
+}
+
+// compareSemanticVersions compares two semantic versions by precedence: major, minor and patch numbers are compared
+// numerically and a pre-release version has a lower precedence than the associated normal version
+func compareSemanticVersions(a, b string) comparison {
+	// parse versions (both of them were checked by the version() scanner)
+	va, _ := parseVersion(a)
+	vb, _ := parseVersion(b)
+	// compare major, minor and patch
+	for i := range va.core {
+		if c := compareNumbers(va.core[i], vb.core[i]); c != compareEqual {
+			return c
+		}
+	}
+	// normal versions have a higher precedence than pre-releases
+	switch {
+	case len(va.prerelease) == 0 && len(vb.prerelease) == 0:
+		return compareEqual
+	case len(va.prerelease) == 0:
+		return compareGreaterThan
+	case len(vb.prerelease) == 0:
+		return compareLessThan
+	}
+	// compare pre-release identifiers
+	for i := 0; i < len(va.prerelease) && i < len(vb.prerelease); i++ {
+		// identifiers
+		x, y := va.prerelease[i], vb.prerelease[i]
+		// process identifier kinds (numeric identifiers have a lower precedence than alphanumeric ones)
+		switch nx, ny := versionNumber(x), versionNumber(y); {
+		case nx && ny:
+			if c := compareNumbers(x, y); c != compareEqual {
+				return c
+			}
+		case nx:
+			return compareLessThan
+		case ny:
+			return compareGreaterThan
+		case x < y:
+			return compareLessThan
+		case x > y:
+			return compareGreaterThan
+		}
+	}
+	// a larger set of identifiers has a higher precedence
+	return compareFloat64(float64(len(va.prerelease)), float64(len(vb.prerelease)))
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GetYAML unmarshals the given YAML document and evaluates the JsonPath expression on it, returning the list
+// of matching values. Mapping keys that are not strings are converted to strings, timestamps are converted to
+// RFC 3339 strings and binary values (!!binary) are returned as strings holding the decoded bytes.
+func GetYAML(data []byte, expression string, options ...Option) ([]any, error) {
+	// create context and Path
+	ctx, path, err := compile(expression, options)
+	if err != nil {
+		return nil, err
+	}
+	// unmarshal document
+	var document any
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	// normalize document
+	document = normalizeYAML(document)
+	// evaluate path
+	result := path.expression(getOperation, document, document).ToSlice()
+	// check evaluation errors
+	if err := ctx.checkErrors(); err != nil {
+		return nil, err
+	}
+	// post-process result
+	ctx.postProcess(result)
+	return result, nil
+}
+
+// normalizeYAML converts YAML mappings into map[string]any and timestamps into strings recursively
+func normalizeYAML(value any) any {
+	// process value type
+	switch v := value.(type) {
+
+	case map[string]any:
+		// normalize values
+		for k, mv := range v {
+			v[k] = normalizeYAML(mv)
+		}
+		return v
+
+	case map[any]any:
+		// string keys
+		m := make(map[string]any, len(v))
+		// normalize keys and values
+		for k, mv := range v {
+			m[yamlKey(k)] = normalizeYAML(mv)
+		}
+		return m
+
+	case []any:
+		// normalize items
+		for i, av := range v {
+			v[i] = normalizeYAML(av)
+		}
+		return v
+
+	case time.Time:
+		// RFC 3339 string
+		return v.Format(time.RFC3339Nano)
+
+	default:
+		return value
+	}
+}
+
+// yamlKey converts a YAML mapping key into a string
+func yamlKey(key any) string {
+	// process key type
+	switch k := key.(type) {
+
+	case string:
+		return k
+
+	case time.Time:
+		// RFC 3339 string
+		return k.Format(time.RFC3339Nano)
+
+	default:
+		return fmt.Sprint(key)
+	}
+}
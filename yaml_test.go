@@ -0,0 +1,159 @@
+/*
+ * Copyright 2023 SteelBridgeLabs, Inc.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package jsonpath
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testYAML = `
+store:
+  book:
+    - author: Nigel Rees
+      price: 8.95
+    - author: Evelyn Waugh
+      price: 12.99
+  bicycle:
+    color: red
+    price: 19.95
+ports:
+  80: http
+  443: https
+`
+
+func TestGetYAML1(t *testing.T) {
+	// arrange
+	var path = "$.store.book[*].author"
+	var expected = []any{"Nigel Rees", "Evelyn Waugh"}
+	// act
+	result, err := GetYAML([]byte(testYAML), path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestGetYAML2(t *testing.T) {
+	// arrange
+	var path = "$.store.book[?(@.price > 10)].author"
+	var expected = []any{"Evelyn Waugh"}
+	// act
+	result, err := GetYAML([]byte(testYAML), path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestGetYAML3(t *testing.T) {
+	// arrange
+	var path = "$.store.bicycle.color"
+	var expected = []any{"red"}
+	// act
+	result, err := GetYAML([]byte(testYAML), path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestGetYAML4(t *testing.T) {
+	// arrange
+	var path = "$.ports['443']"
+	var expected = []any{"https"}
+	// act
+	result, err := GetYAML([]byte(testYAML), path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestGetYAML5(t *testing.T) {
+	// arrange
+	var path = "$..price"
+	var expected = []any{8.95, 12.99, 19.95}
+	// act
+	result, err := GetYAML([]byte(testYAML), path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestGetYAML6(t *testing.T) {
+	// act
+	_, err := GetYAML([]byte("a: [1"), "$.a")
+	if err == nil {
+		t.Error("Expected error parsing invalid YAML")
+	}
+}
+
+func TestGetYAMLTimestamps(t *testing.T) {
+	// arrange
+	var document = `
+events:
+  - name: a
+    at: 2001-12-14t21:59:43.10-05:00
+  - name: b
+    at: 2002-12-14
+2003-01-01: c
+`
+	var path = "$.events[?(@.at =~ /^2002/)].name"
+	var expected = []any{"b"}
+	// act
+	result, err := GetYAML([]byte(document), path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+	// act
+	result, err = GetYAML([]byte(document), "$.events[*].at")
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff([]any{"2001-12-14T21:59:43.1-05:00", "2002-12-14T00:00:00Z"}, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+	// act
+	result, err = GetYAML([]byte(document), "$['2003-01-01T00:00:00Z']")
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff([]any{"c"}, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}
+
+func TestGetYAMLBinary(t *testing.T) {
+	// arrange
+	var document = "data: !!binary aGVsbG8=\n"
+	var path = "$.data"
+	var expected = []any{"hello"}
+	// act
+	result, err := GetYAML([]byte(document), path)
+	if err != nil {
+		t.Errorf("Failed to get value: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Unexpected result: %v", diff)
+	}
+}